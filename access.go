@@ -0,0 +1,55 @@
+package hourglass
+
+import "sync"
+
+type userSet struct {
+	mu    sync.RWMutex
+	users map[string]struct{}
+}
+
+func newUserSet(userNames []string) *userSet {
+	s := &userSet{users: make(map[string]struct{}, len(userNames))}
+	for _, userName := range userNames {
+		s.users[userName] = struct{}{}
+	}
+	return s
+}
+
+func (s *userSet) add(userName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[userName] = struct{}{}
+}
+
+func (s *userSet) remove(userName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.users, userName)
+}
+
+func (s *userSet) contains(userName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, exists := s.users[userName]
+	return exists
+}
+
+// AddToWhitelist exempts userName from rate limiting.
+func (hg *HourGlass) AddToWhitelist(userName string) {
+	hg.whitelist.add(userName)
+}
+
+// RemoveFromWhitelist subjects userName to rate limiting again.
+func (hg *HourGlass) RemoveFromWhitelist(userName string) {
+	hg.whitelist.remove(userName)
+}
+
+// AddToBlacklist denies every future Consume by userName.
+func (hg *HourGlass) AddToBlacklist(userName string) {
+	hg.blacklist.add(userName)
+}
+
+// RemoveFromBlacklist lifts the block on userName.
+func (hg *HourGlass) RemoveFromBlacklist(userName string) {
+	hg.blacklist.remove(userName)
+}
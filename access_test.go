@@ -0,0 +1,100 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhitelist(t *testing.T) {
+	limits := map[string]int{
+		"feature1": 1,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+		Whitelist:     []string{"probe"},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Set(ctx, dailyKey("feature1", "probe"), 1, 1*time.Minute)
+	h.redisClient.Set(ctx, dailyKey("feature1", "service"), 1, 1*time.Minute)
+
+	t.Run("A user from the config whitelist should bypass the limit", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "probe")
+		require.True(t, result.Allowed)
+		require.Equal(t, 0, result.Current)
+		require.Equal(t, 1, result.Limit)
+
+		stored, _ := h.Get(ctx, "feature1", "probe")
+		require.Equal(t, 1, stored)
+	})
+
+	t.Run("A user added at runtime should bypass the limit", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "service")
+		require.False(t, result.Allowed)
+
+		h.AddToWhitelist("service")
+		result, _ = h.Consume(ctx, "feature1", "service")
+		require.True(t, result.Allowed)
+	})
+
+	t.Run("A user removed at runtime should be limited again", func(t *testing.T) {
+		h.RemoveFromWhitelist("service")
+		result, _ := h.Consume(ctx, "feature1", "service")
+		require.False(t, result.Allowed)
+	})
+}
+
+func TestBlacklist(t *testing.T) {
+	limits := map[string]int{
+		"feature1": 5,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+		Blacklist:     []string{"abuser"},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "abuser"), dailyKey("feature1", "spammer"))
+
+	t.Run("A user from the config blacklist should be denied without consuming", func(t *testing.T) {
+		result, err := h.consume(ctx, "feature1", "abuser")
+		require.Equal(t, ErrUserBlacklisted, err)
+		require.False(t, result.Allowed)
+
+		exists, _ := h.redisClient.Exists(ctx, dailyKey("feature1", "abuser")).Result()
+		require.Equal(t, int64(0), exists)
+	})
+
+	t.Run("A user added at runtime should be denied", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "spammer")
+		require.True(t, result.Allowed)
+
+		h.AddToBlacklist("spammer")
+		result, _ = h.Consume(ctx, "feature1", "spammer")
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("A user removed at runtime should be allowed again", func(t *testing.T) {
+		h.RemoveFromBlacklist("spammer")
+		result, _ := h.Consume(ctx, "feature1", "spammer")
+		require.True(t, result.Allowed)
+		require.Equal(t, 2, result.Current)
+	})
+}
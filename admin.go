@@ -0,0 +1,128 @@
+package hourglass
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type featureUsage struct {
+	Current   int `json:"current"`
+	Limit     int `json:"limit"`
+	Remaining int `json:"remaining"`
+}
+
+type limitUpdate struct {
+	Limit *int `json:"limit"`
+}
+
+// setLimit changes the limit of an existing featureName at runtime. Only
+// limits from Config are writable; limits served by a LimitProvider return
+// ErrLimitsReadOnly.
+func (hg *HourGlass) setLimit(featureName string, limit int) error {
+	if _, exists := hg.limitProvider.Limit(featureName); !exists {
+		return ErrUnknownFeature
+	}
+	provider, ok := hg.limitProvider.(*staticLimitProvider)
+	if !ok {
+		return ErrLimitsReadOnly
+	}
+	provider.set(featureName, limit)
+
+	return nil
+}
+
+// NewAdminHandler returns a handler for managing limits at runtime:
+//
+//	GET  /features               every feature and its limit
+//	PUT  /features/{name}/limit  set a limit from a {"limit": n} body
+//	POST /users/{user}/reset     set every counter of a user to zero
+//	GET  /users/{user}/usage     the usage of a user for every feature
+//
+// Requests must carry "Authorization: Bearer {authToken}", otherwise they
+// get 401; with an empty authToken every request is rejected. Limit changes
+// only apply to this instance and are lost on restart.
+func NewAdminHandler(hg *HourGlass, authToken string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /features", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, hg.Limits())
+	})
+
+	mux.HandleFunc("PUT /features/{name}/limit", func(w http.ResponseWriter, r *http.Request) {
+		var update limitUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil || update.Limit == nil || *update.Limit < 0 {
+			http.Error(w, "body must be {\"limit\": n} with n >= 0", http.StatusBadRequest)
+			return
+		}
+
+		featureName := r.PathValue("name")
+		err := hg.setLimit(featureName, *update.Limit)
+		switch {
+		case errors.Is(err, ErrUnknownFeature):
+			http.Error(w, "unknown feature", http.StatusNotFound)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		writeJSON(w, map[string]int{featureName: *update.Limit})
+	})
+
+	mux.HandleFunc("POST /users/{user}/reset", func(w http.ResponseWriter, r *http.Request) {
+		userName := r.PathValue("user")
+		if !hg.validUserName(userName) {
+			http.Error(w, ErrInvalidUsername.Error(), http.StatusBadRequest)
+			return
+		}
+
+		for featureName := range hg.limitProvider.Limits() {
+			if err := hg.SetUsage(r.Context(), featureName, userName, 0); err != nil {
+				http.Error(w, "rate limit store unavailable", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("GET /users/{user}/usage", func(w http.ResponseWriter, r *http.Request) {
+		userName := r.PathValue("user")
+		if !hg.validUserName(userName) {
+			http.Error(w, ErrInvalidUsername.Error(), http.StatusBadRequest)
+			return
+		}
+
+		usage := map[string]featureUsage{}
+		for featureName := range hg.limitProvider.Limits() {
+			current, limit, err := hg.get(r.Context(), featureName, userName)
+			if errors.Is(err, redis.Nil) {
+				current, err = 0, nil
+			}
+			if err != nil {
+				http.Error(w, "rate limit store unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			usage[featureName] = featureUsage{Current: current, Limit: limit, Remaining: max(limit-current, 0)}
+		}
+		writeJSON(w, usage)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := []byte("Bearer " + authToken)
+		if authToken == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,158 @@
+package hourglass
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits:       map[string]int{"admin-a": 5, "admin-b": 3},
+		KeyPrefix:    "admin-test:",
+	})
+	require.NoError(t, err)
+	defer h.Close()
+
+	require.NoError(t, h.SetUsage(ctx, "admin-a", "alice", 2))
+	require.NoError(t, h.SetUsage(ctx, "admin-b", "alice", 1))
+
+	handler := NewAdminHandler(h, "secret")
+
+	tt := []struct {
+		description    string
+		method         string
+		path           string
+		body           string
+		token          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			description:    "A request without a token should be rejected",
+			method:         http.MethodGet,
+			path:           "/features",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			description:    "A request with the wrong token should be rejected",
+			method:         http.MethodGet,
+			path:           "/features",
+			token:          "wrong",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			description:    "Listing features should return their limits",
+			method:         http.MethodGet,
+			path:           "/features",
+			token:          "secret",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"admin-a": 5, "admin-b": 3}`,
+		},
+		{
+			description:    "Usage should cover every feature",
+			method:         http.MethodGet,
+			path:           "/users/alice/usage",
+			token:          "secret",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"admin-a": {"current": 2, "limit": 5, "remaining": 3}, "admin-b": {"current": 1, "limit": 3, "remaining": 2}}`,
+		},
+		{
+			description:    "A limit should be updated",
+			method:         http.MethodPut,
+			path:           "/features/admin-b/limit",
+			body:           `{"limit": 10}`,
+			token:          "secret",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"admin-b": 10}`,
+		},
+		{
+			description:    "Setting the limit of an unknown feature should fail",
+			method:         http.MethodPut,
+			path:           "/features/admin-c/limit",
+			body:           `{"limit": 10}`,
+			token:          "secret",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description:    "Listing features should not include a feature that failed to be set",
+			method:         http.MethodGet,
+			path:           "/features",
+			token:          "secret",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"admin-a": 5, "admin-b": 10}`,
+		},
+		{
+			description:    "A negative limit should be rejected",
+			method:         http.MethodPut,
+			path:           "/features/admin-b/limit",
+			body:           `{"limit": -1}`,
+			token:          "secret",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "Resetting a user should clear every counter",
+			method:         http.MethodPost,
+			path:           "/users/alice/reset",
+			token:          "secret",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			description:    "Usage after the reset should reflect the new limit",
+			method:         http.MethodGet,
+			path:           "/users/alice/usage",
+			token:          "secret",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"admin-a": {"current": 0, "limit": 5, "remaining": 5}, "admin-b": {"current": 0, "limit": 10, "remaining": 10}}`,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			if test.token != "" {
+				req.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, test.expectedStatus, rec.Code)
+			if test.expectedBody != "" {
+				require.JSONEq(t, test.expectedBody, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("Limits from a provider should not be writable", func(t *testing.T) {
+		provided, err := New(&Config{RedisAddress: "localhost:6379"}, WithLimitProvider(fixedLimits{"admin-a": 5}))
+		require.NoError(t, err)
+		defer provided.Close()
+
+		req := httptest.NewRequest(http.MethodPut, "/features/admin-a/limit", strings.NewReader(`{"limit": 1}`))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		NewAdminHandler(provided, "secret").ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+// fixedLimits is a LimitProvider other than the built-in one.
+type fixedLimits map[string]int
+
+func (l fixedLimits) Limit(featureName string) (int, bool) {
+	limit, exists := l[featureName]
+	return limit, exists
+}
+
+func (l fixedLimits) Limits() map[string]int {
+	return l
+}
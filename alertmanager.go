@@ -0,0 +1,242 @@
+package hourglass
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultAlertManagerTimeout = 5 * time.Second
+	alertManagerAttempts       = 3
+	alertManagerRetryDelay     = 200 * time.Millisecond
+	alertManagerBreakerFails   = 5
+	alertManagerBreakerReset   = 30 * time.Second
+	alertManagerWorkers        = 4
+	alertManagerQueueSize      = 100
+	alertManagerLocalMarkers   = 10000
+)
+
+// WithAlertManagerWebhook posts the first limit exceeded event of every
+// user, feature and window to url, e.g. Alertmanager's /api/v2/alerts
+// endpoint, as an alert with the alertname HourglassLimitExceeded, the
+// feature and user and the extra labels. Alerts are queued and sent by a few
+// background workers with retries; alerts that do not fit in the queue are
+// dropped, and after repeated failures a circuit breaker drops alerts for a
+// while instead of piling up requests.
+func WithAlertManagerWebhook(url string, labels map[string]string) Option {
+	return func(hg *HourGlass) {
+		hg.alertManager = &alertManager{
+			url:     url,
+			labels:  maps.Clone(labels),
+			client:  &http.Client{Timeout: defaultAlertManagerTimeout},
+			breaker: &circuitBreaker{threshold: alertManagerBreakerFails, resetTimeout: alertManagerBreakerReset},
+		}
+	}
+}
+
+// WithAlertManagerTimeout sets the timeout of each webhook request sent by
+// WithAlertManagerWebhook. Defaults to 5 seconds.
+func WithAlertManagerTimeout(d time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.alertManagerTimeout = d
+	}
+}
+
+type alertManager struct {
+	url     string
+	labels  map[string]string
+	client  *http.Client
+	breaker *circuitBreaker
+
+	// mu guards sending on queue against Close closing it.
+	mu        sync.RWMutex
+	closed    bool
+	queue     chan queuedAlert
+	workers   sync.WaitGroup
+	closeOnce sync.Once
+
+	// alerted remembers the markers this instance already queued, with the
+	// end of their window, so repeated denials skip the queue and Redis.
+	alertedMu sync.Mutex
+	alerted   map[string]time.Time
+}
+
+type queuedAlert struct {
+	event     LimitEvent
+	payload   []byte
+	marker    string
+	markerTTL time.Duration
+}
+
+func (am *alertManager) start(hg *HourGlass) {
+	am.queue = make(chan queuedAlert, alertManagerQueueSize)
+	for range alertManagerWorkers {
+		am.workers.Add(1)
+		go func() {
+			defer am.workers.Done()
+
+			for queued := range am.queue {
+				first, err := hg.redisClient.SetNX(context.Background(), queued.marker, 1, queued.markerTTL).Result()
+				if err == nil && !first {
+					continue
+				}
+				hg.deliverAlert(queued.event, queued.payload)
+			}
+		}()
+	}
+}
+
+// Close stops taking alerts and waits until the queued ones are sent.
+func (am *alertManager) Close() error {
+	am.closeOnce.Do(func() {
+		am.mu.Lock()
+		am.closed = true
+		close(am.queue)
+		am.mu.Unlock()
+
+		am.workers.Wait()
+	})
+	return nil
+}
+
+// alert is the Alertmanager representation of a limit exceeded event.
+type alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+}
+
+func newAlert(event LimitEvent, labels map[string]string) alert {
+	a := alert{
+		Labels: map[string]string{
+			"alertname": "HourglassLimitExceeded",
+			"feature":   event.Feature,
+			"user":      event.User,
+		},
+		Annotations: map[string]string{
+			"summary": fmt.Sprintf("%s exceeded the limit of %s", event.User, event.Feature),
+			"current": strconv.Itoa(event.Current),
+			"limit":   strconv.Itoa(event.Limit),
+		},
+		StartsAt: event.Time,
+	}
+	maps.Copy(a.Labels, labels)
+	for key, value := range event.Metadata {
+		if _, exists := a.Annotations[key]; !exists {
+			a.Annotations[key] = value
+		}
+	}
+
+	return a
+}
+
+// sendAlert queues event unless this instance already queued an alert for
+// the user, feature and window. The worker then stores a marker next to the
+// counter until the window ends and only sends the alert if it set the
+// marker first, so instances share the deduplication without the caller
+// paying for a Redis round trip.
+func (hg *HourGlass) sendAlert(ctx context.Context, event LimitEvent) {
+	am := hg.alertManager
+	ttl := time.Until(hg.userWindowEnd(ctx, event.Feature, event.User))
+	if ttl <= 0 {
+		ttl = hg.ttlFor(ctx, event.Feature, event.User)
+	}
+	key, _, _ := hg.lookup(ctx, event.Feature, event.User)
+	marker := key + ":alerted"
+	if !am.markAlerted(marker, time.Now().Add(ttl)) {
+		return
+	}
+
+	payload, err := json.Marshal([]alert{newAlert(event, am.labels)})
+	if err != nil {
+		hg.logger.WarnContext(ctx, "failed to encode alert", "feature", event.Feature, "user", event.User, "error", err)
+		return
+	}
+
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	if am.closed {
+		return
+	}
+	select {
+	case am.queue <- queuedAlert{event: event, payload: payload, marker: marker, markerTTL: ttl}:
+	default:
+		hg.logger.WarnContext(ctx, "dropping alert because the webhook queue is full", "feature", event.Feature, "user", event.User)
+	}
+}
+
+// markAlerted records that marker was queued until expires and reports
+// whether it was not already recorded. Expired markers are dropped once
+// alertManagerLocalMarkers are held, and all of them if none had expired;
+// the marker in Redis still deduplicates what the local one forgets.
+func (am *alertManager) markAlerted(marker string, expires time.Time) bool {
+	am.alertedMu.Lock()
+	defer am.alertedMu.Unlock()
+
+	now := time.Now()
+	if until, ok := am.alerted[marker]; ok && now.Before(until) {
+		return false
+	}
+	if am.alerted == nil {
+		am.alerted = make(map[string]time.Time)
+	}
+	if len(am.alerted) >= alertManagerLocalMarkers {
+		maps.DeleteFunc(am.alerted, func(_ string, until time.Time) bool { return !now.Before(until) })
+		if len(am.alerted) >= alertManagerLocalMarkers {
+			clear(am.alerted)
+		}
+	}
+	am.alerted[marker] = expires
+	return true
+}
+
+// deliverAlert posts payload, retrying failed requests. Alerts are dropped
+// while the breaker is open.
+func (hg *HourGlass) deliverAlert(event LimitEvent, payload []byte) {
+	am := hg.alertManager
+
+	var err error
+	for attempt := range alertManagerAttempts {
+		if attempt > 0 {
+			time.Sleep(alertManagerRetryDelay << (attempt - 1))
+		}
+		if !am.breaker.allow() {
+			hg.logger.Debug("dropping alert while the webhook circuit is open", "feature", event.Feature, "user", event.User)
+			return
+		}
+
+		err = am.post(payload)
+		am.breaker.record(err)
+		if err == nil {
+			return
+		}
+	}
+	hg.logger.Warn("failed to send alert", "feature", event.Feature, "user", event.User, "error", err)
+}
+
+func (am *alertManager) post(payload []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, am.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := am.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("alert webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package hourglass
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertManagerWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	tt := []struct {
+		description      string
+		failures         int32
+		expectedRequests int32
+		expectedAlerts   int
+	}{
+		{
+			description:      "A limit exceeded event should be posted as an alert",
+			expectedRequests: 1,
+			expectedAlerts:   1,
+		},
+		{
+			description:      "A failed post should be retried",
+			failures:         2,
+			expectedRequests: 3,
+			expectedAlerts:   1,
+		},
+		{
+			description:      "A post should give up after the last attempt",
+			failures:         alertManagerAttempts,
+			expectedRequests: alertManagerAttempts,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			var requests atomic.Int32
+			var alerts []alert
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) <= test.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&alerts))
+			}))
+			defer server.Close()
+
+			h, err := New(&Config{
+				RedisAddress: "localhost:6379",
+				Limits:       map[string]int{"alerting": 1},
+				KeyPrefix:    "alertmanager-test:",
+			}, WithAlertManagerWebhook(server.URL, map[string]string{"team": "platform"}))
+			require.NoError(t, err)
+
+			keys, _ := h.redisClient.Keys(ctx, "alertmanager-test:*").Result()
+			if len(keys) > 0 {
+				h.redisClient.Del(ctx, keys...)
+			}
+
+			h.Consume(ctx, "alerting", "alice")
+			result, err := h.Consume(ctx, "alerting", "alice")
+			require.NoError(t, err)
+			require.False(t, result.Allowed)
+
+			// Close waits for pending alerts.
+			require.NoError(t, h.Close())
+
+			require.Equal(t, test.expectedRequests, requests.Load())
+			require.Len(t, alerts, test.expectedAlerts)
+			if test.expectedAlerts > 0 {
+				require.Equal(t, map[string]string{
+					"alertname": "HourglassLimitExceeded",
+					"feature":   "alerting",
+					"user":      "alice",
+					"team":      "platform",
+				}, alerts[0].Labels)
+				require.Equal(t, "1", alerts[0].Annotations["limit"])
+				require.False(t, alerts[0].StartsAt.IsZero())
+			}
+		})
+	}
+}
+
+func TestAlertManagerCircuitBreaker(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits:       map[string]int{"alerting": 1},
+	}, WithAlertManagerWebhook(server.URL, nil))
+	require.NoError(t, err)
+	defer h.Close()
+
+	event := LimitEvent{Feature: "alerting", User: "alice"}
+	for range alertManagerBreakerFails {
+		h.deliverAlert(event, []byte("[]"))
+	}
+
+	require.True(t, h.alertManager.breaker.isOpen())
+	sent := requests.Load()
+
+	h.deliverAlert(event, []byte("[]"))
+	require.Equal(t, sent, requests.Load())
+}
+
+func TestAlertManagerDeduplication(t *testing.T) {
+	ctx := context.Background()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits:       map[string]int{"alerting": 1},
+		KeyPrefix:    "alertmanager-dedup-test:",
+	}, WithAlertManagerWebhook(server.URL, nil))
+	require.NoError(t, err)
+
+	keys, _ := h.redisClient.Keys(ctx, "alertmanager-dedup-test:*").Result()
+	if len(keys) > 0 {
+		h.redisClient.Del(ctx, keys...)
+	}
+
+	h.Consume(ctx, "alerting", "alice")
+	for range 5 {
+		h.Consume(ctx, "alerting", "alice")
+	}
+	h.Consume(ctx, "alerting", "bob")
+	h.Consume(ctx, "alerting", "bob")
+
+	require.NoError(t, h.Close())
+	require.Equal(t, int32(2), requests.Load())
+
+	// Another instance finds the marker in Redis and does not alert again.
+	other, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits:       map[string]int{"alerting": 1},
+		KeyPrefix:    "alertmanager-dedup-test:",
+	}, WithAlertManagerWebhook(server.URL, nil))
+	require.NoError(t, err)
+	other.Consume(ctx, "alerting", "alice")
+	require.NoError(t, other.Close())
+	require.Equal(t, int32(2), requests.Load())
+
+	// Alerts after Close are dropped instead of panicking.
+	require.NotPanics(t, func() {
+		h.sendAlert(ctx, LimitEvent{Feature: "alerting", User: "carol"})
+	})
+}
@@ -0,0 +1,115 @@
+package hourglass
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// auditStreamMaxLen caps the audit stream; older entries are trimmed
+// approximately.
+const auditStreamMaxLen = 100000
+
+func (hg *HourGlass) auditKey(ctx context.Context) string {
+	return hg.keyPrefix(ctx) + "audit"
+}
+
+// ConsumeAndRecord consumes like Consume and appends an entry to the audit
+// stream in the same MULTI/EXEC round trip, so a consume is never recorded
+// without its audit entry or the other way round. The entry holds the
+// feature, the user, the time and every metadata pair with a "meta." prefix.
+// Pairs added with WithMetadata are recorded the same way. It applies the
+// daily limit and burst allowance only; pauses, cooldowns, per minute rates,
+// the local buffer and value serializers are not used.
+func (hg *HourGlass) ConsumeAndRecord(ctx context.Context, featureName, userName string, metadata map[string]string, opts ...ConsumeOption) (ConsumeResult, error) {
+	options := newConsumeOptions(opts)
+	metadata = mergeMetadata(metadata, options.metadata)
+
+	key, limit, exists := hg.lookup(ctx, featureName, userName)
+	if !exists || hg.blacklist.contains(userName) || hg.whitelist.contains(userName) {
+		result, err := hg.consumeWith(ctx, featureName, userName, consumeOptions{metadata: metadata})
+		if auditErr := hg.redisClient.XAdd(ctx, hg.auditArgs(ctx, featureName, userName, metadata)).Err(); auditErr != nil {
+			hg.logger.WarnContext(ctx, "failed to record audit entry", "feature", featureName, "user", userName, "error", auditErr)
+		}
+		return result, err
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		return hg.failureResult(limit, err)
+	}
+
+	ttl := hg.ttlFor(ctx, featureName, userName)
+	burst := hg.appConfig.Features[featureName].BurstAllowance
+
+	// EVAL instead of EVALSHA, a NOSCRIPT error inside MULTI could not be
+	// retried without recording the audit entry twice.
+	var consumeCmd *redis.Cmd
+	_, err := hg.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		consumeCmd = hg.consumeScriptFor(featureName).Eval(ctx, pipe, []string{key, burstKey(key)}, limit, ttlMillis(ttl), burst)
+		pipe.XAdd(ctx, hg.auditArgs(ctx, featureName, userName, metadata))
+		return nil
+	})
+	if err != nil {
+		return hg.failureResult(limit, err)
+	}
+
+	current, newLimit, allowed, burstUsed, err := hg.parseConsumeResult(ctx, consumeCmd.Val())
+	if err != nil {
+		return hg.failureResult(limit, err)
+	}
+	limit = newLimit
+	if !allowed {
+		hg.publishLimitExceeded(ctx, LimitEvent{
+			Feature:  featureName,
+			User:     userName,
+			Current:  current,
+			Limit:    limit,
+			Time:     time.Now().UTC(),
+			Metadata: metadata,
+		})
+	}
+
+	return ConsumeResult{
+		Current:   current,
+		Limit:     limit,
+		Remaining: max(limit-current, 0),
+		Allowed:   allowed,
+		ResetsAt:  hg.userWindowEnd(ctx, featureName, userName),
+		BurstUsed: burstUsed,
+	}, nil
+}
+
+// mergeMetadata combines metadata with the pairs from WithMetadata, which win
+// on conflicting keys.
+func mergeMetadata(metadata, extra map[string]string) map[string]string {
+	if len(extra) == 0 {
+		return metadata
+	}
+
+	merged := maps.Clone(metadata)
+	if merged == nil {
+		merged = make(map[string]string, len(extra))
+	}
+	maps.Copy(merged, extra)
+	return merged
+}
+
+func (hg *HourGlass) auditArgs(ctx context.Context, featureName, userName string, metadata map[string]string) *redis.XAddArgs {
+	values := []any{
+		"feature", featureName,
+		"user", userName,
+		"time", time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	for k, v := range metadata {
+		values = append(values, "meta."+k, v)
+	}
+
+	return &redis.XAddArgs{
+		Stream: hg.auditKey(ctx),
+		MaxLen: auditStreamMaxLen,
+		Approx: true,
+		Values: values,
+	}
+}
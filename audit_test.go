@@ -0,0 +1,67 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeAndRecord(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+		KeyPrefix: "auditprefix:",
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, h.auditKey(ctx), "auditprefix:"+dailyKey("feature1", "audit-user"))
+
+	t.Run("A consume should be recorded with its metadata", func(t *testing.T) {
+		result, err := h.ConsumeAndRecord(ctx, "feature1", "audit-user", map[string]string{"request_id": "req-1"})
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+
+		entries, err := h.redisClient.XRange(ctx, h.auditKey(ctx), "-", "+").Result()
+		require.Nil(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, "feature1", entries[0].Values["feature"])
+		require.Equal(t, "audit-user", entries[0].Values["user"])
+		require.Equal(t, "req-1", entries[0].Values["meta.request_id"])
+		require.NotEmpty(t, entries[0].Values["time"])
+	})
+
+	t.Run("A denied consume should be recorded too", func(t *testing.T) {
+		result, err := h.ConsumeAndRecord(ctx, "feature1", "audit-user", nil)
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+
+		require.Equal(t, int64(2), h.redisClient.XLen(ctx, h.auditKey(ctx)).Val())
+	})
+
+	t.Run("Metadata options should be recorded with the metadata", func(t *testing.T) {
+		_, err := h.ConsumeAndRecord(ctx, "feature1", "audit-user", map[string]string{"request_id": "req-2"}, WithMetadata("user_agent", "curl"))
+		require.Nil(t, err)
+
+		entries, err := h.redisClient.XRevRangeN(ctx, h.auditKey(ctx), "+", "-", 1).Result()
+		require.Nil(t, err)
+		require.Equal(t, "req-2", entries[0].Values["meta.request_id"])
+		require.Equal(t, "curl", entries[0].Values["meta.user_agent"])
+	})
+
+	t.Run("A consume of an unknown feature should be recorded", func(t *testing.T) {
+		result, err := h.ConsumeAndRecord(ctx, "feature-notexistent", "audit-user", nil)
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+
+		require.Equal(t, int64(4), h.redisClient.XLen(ctx, h.auditKey(ctx)).Val())
+	})
+}
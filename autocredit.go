@@ -0,0 +1,41 @@
+package hourglass
+
+import (
+	"context"
+	"sync"
+)
+
+// ConsumeAutoCredit consumes like Consume and credits the unit back if ctx is
+// cancelled before the returned func is called, for work that is abandoned
+// when the request goes away. Calling the func keeps the consume; its cause is
+// ignored. One of the two has to happen, a goroutine watches the consume until
+// then. The cause of the cancellation is logged with the credit. Consumes that
+// were denied or did not touch a counter, such as those of whitelisted users,
+// are never credited.
+func (hg *HourGlass) ConsumeAutoCredit(ctx context.Context, featureName, userName string) (ConsumeResult, context.CancelCauseFunc, error) {
+	result, err := hg.Consume(ctx, featureName, userName)
+	if err != nil || !result.Allowed || result.Current <= 0 {
+		return result, func(error) {}, err
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+	keep := func(error) {
+		once.Do(func() { close(done) })
+	}
+
+	go func() {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			// Both channels can be ready at once; the once decides whether
+			// the consume was kept first.
+			once.Do(func() {
+				hg.Credit(context.WithoutCancel(ctx), featureName, userName)
+				hg.logger.InfoContext(ctx, "credited abandoned consume", "feature", featureName, "user", userName, "reason", context.Cause(ctx))
+			})
+		}
+	}()
+
+	return result, keep, nil
+}
@@ -0,0 +1,68 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeAutoCredit(t *testing.T) {
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+		Whitelist: []string{"auto-probe"},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := dailyKey("feature1", "auto-user")
+	h.redisClient.Del(context.Background(), key)
+
+	t.Run("A cancelled context should credit the consume back", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+
+		result, _, err := h.ConsumeAutoCredit(ctx, "feature1", "auto-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+
+		cancel(errors.New("client went away"))
+
+		require.Eventually(t, func() bool {
+			current, _ := h.Get(context.Background(), "feature1", "auto-user")
+			return current == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("A kept consume should not be credited", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		_, keep, err := h.ConsumeAutoCredit(ctx, "feature1", "auto-user")
+		require.Nil(t, err)
+
+		keep(nil)
+		cancel()
+
+		time.Sleep(50 * time.Millisecond)
+		current, _ := h.Get(context.Background(), "feature1", "auto-user")
+		require.Equal(t, 1, current)
+	})
+
+	t.Run("Whitelisted users should not be credited", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		result, keep, err := h.ConsumeAutoCredit(ctx, "feature1", "auto-probe")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.NotNil(t, keep)
+
+		cancel()
+	})
+}
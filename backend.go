@@ -0,0 +1,15 @@
+package hourglass
+
+import (
+	"log/slog"
+	"time"
+)
+
+// counterBackend keeps counters outside Redis, see WithCRDTBackend. Consume
+// and Get use it instead of Redis when one is set.
+type counterBackend interface {
+	start(logger *slog.Logger) error
+	consume(key string, limit int, expiresAt time.Time) (current int, allowed bool)
+	get(key string) int
+	close() error
+}
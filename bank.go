@@ -0,0 +1,75 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//go:embed bank.lua
+var bankScriptData string
+
+//go:embed rollover.lua
+var rolloverScriptData string
+
+// bankKey returns the hash that holds the units userName has banked for
+// featureName. It has no window in it and no expiry, so banked units carry
+// over from one window to the next.
+func (hg *HourGlass) bankKey(ctx context.Context, featureName, userName string) string {
+	keyFeature, _, _ := hg.lookupLimit(featureName, userName)
+	return fmt.Sprintf("%s%s:%s:bank", hg.keyPrefix(ctx), keyFeature, hg.keyUser(userName))
+}
+
+func (hg *HourGlass) runBankScript(ctx context.Context, bankKey, key string, limit int, ttl time.Duration) (current, newLimit int, allowed bool, banked int, err error) {
+	result, err := hg.bankScript.Run(ctx, hg.redisClient, []string{bankKey, key}, limit, ttlMillis(ttl)).Int64Slice()
+	if err != nil {
+		return -1, limit, false, 0, err
+	}
+
+	return int(result[0]), int(result[1]), result[2] == 1, int(result[3]), nil
+}
+
+// RolloverJob banks the unused quota of the current window for every user
+// that consumed a feature with a RolloverMax, up to RolloverMax banked units
+// per user. It is meant to run shortly before the window ends, e.g. from a
+// cron job at 23:55 UTC for daily features. Each window is banked at most
+// once per user, so running the job again is harmless. It returns the total
+// number of units added to banks.
+func (hg *HourGlass) RolloverJob(ctx context.Context) (int64, error) {
+	var total int64
+
+	for featureName, featureConfig := range hg.appConfig.Features {
+		if featureConfig.RolloverMax <= 0 {
+			continue
+		}
+
+		defaultLimit, exists := hg.limitProvider.Limit(featureName)
+		if !exists {
+			continue
+		}
+
+		counters, err := hg.activeCounters(ctx, featureName)
+		if err != nil {
+			return total, err
+		}
+
+		for _, counter := range counters {
+			limit := defaultLimit
+			if counter.priority != "" {
+				limit = hg.appConfig.PriorityLimits[featureName][counter.priority]
+			}
+
+			// The bank key is the counter key with the window replaced.
+			bankKey := strings.TrimSuffix(counter.key, counter.windowID) + "bank"
+			added, err := hg.rolloverScript.Run(ctx, hg.redisClient, []string{bankKey, counter.key}, limit, featureConfig.RolloverMax, counter.windowID).Int64()
+			if err != nil {
+				return total, err
+			}
+			total += added
+		}
+	}
+
+	return total, nil
+}
@@ -0,0 +1,22 @@
+local bank_key = KEYS[1]
+local key = KEYS[2]
+local limit = tonumber(ARGV[1])
+local ttl_ms = tonumber(ARGV[2])
+
+local current = tonumber(redis.call('GET', key) or '0')
+local banked = tonumber(redis.call('HGET', bank_key, 'units') or '0')
+
+-- Banked units are spent before the daily limit.
+if banked > 0 then
+    return {current, limit, 1, redis.call('HINCRBY', bank_key, 'units', -1)}
+end
+
+if current >= limit then
+    return {current, limit, 0, 0}
+end
+
+if current == 0 and redis.call('SET', key, 1, 'PX', ttl_ms, 'NX') then
+    return {1, limit, 1, 0}
+end
+
+return {redis.call('INCR', key), limit, 1, 0}
@@ -0,0 +1,79 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolloverJob(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"bank-feature":  5,
+			"plain-feature": 5,
+		},
+		Features: map[string]FeatureConfig{
+			"bank-feature": {RolloverMax: 3},
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	for _, userName := range []string{"bank-user", "heavy-user"} {
+		h.redisClient.Del(ctx, dailyKey("bank-feature", userName), h.bankKey(ctx, "bank-feature", userName))
+	}
+	h.redisClient.Del(ctx, dailyKey("plain-feature", "bank-user"))
+
+	t.Run("Unused quota should be banked up to the maximum", func(t *testing.T) {
+		h.redisClient.Set(ctx, dailyKey("bank-feature", "bank-user"), 1, 0)
+		h.redisClient.Set(ctx, dailyKey("bank-feature", "heavy-user"), 4, 0)
+		h.redisClient.Set(ctx, dailyKey("plain-feature", "bank-user"), 1, 0)
+
+		added, err := h.RolloverJob(ctx)
+		require.Nil(t, err)
+		require.Equal(t, int64(4), added)
+
+		banked, err := h.redisClient.HGet(ctx, h.bankKey(ctx, "bank-feature", "bank-user"), "units").Int()
+		require.Nil(t, err)
+		require.Equal(t, 3, banked)
+	})
+
+	t.Run("Running the job again in the same window should not bank twice", func(t *testing.T) {
+		added, err := h.RolloverJob(ctx)
+		require.Nil(t, err)
+		require.Equal(t, int64(0), added)
+	})
+
+	t.Run("Consume should spend banked units before the daily limit", func(t *testing.T) {
+		result, err := h.Consume(ctx, "bank-feature", "heavy-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 4, result.Current)
+		require.Equal(t, 0, result.Banked)
+		require.Equal(t, 1, result.Remaining)
+
+		result, err = h.Consume(ctx, "bank-feature", "heavy-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 5, result.Current)
+
+		result, err = h.Consume(ctx, "bank-feature", "heavy-user")
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("The bank should be reported in the result", func(t *testing.T) {
+		result, err := h.Consume(ctx, "bank-feature", "bank-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+		require.Equal(t, 2, result.Banked)
+		require.Equal(t, 6, result.Remaining)
+	})
+}
@@ -0,0 +1,42 @@
+package hourglass
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// barrierKey returns the set that holds the members that joined barrierName
+// in the current window of featureName.
+func (hg *HourGlass) barrierKey(ctx context.Context, featureName, barrierName string) string {
+	return fmt.Sprintf("%sbarrier:%s:%s:%s", hg.keyPrefix(ctx), featureName, barrierName, hg.windowID(featureName))
+}
+
+// BarrierConsume records that memberID reached barrierName and reports
+// whether all totalMembers have, e.g. to start a workflow once every worker
+// checked in. Joining twice counts once. The barrier is reset with the
+// window of featureName.
+func (hg *HourGlass) BarrierConsume(ctx context.Context, featureName, barrierName string, memberID string, totalMembers int) (allReady bool, err error) {
+	if _, exists := hg.limitProvider.Limit(featureName); !exists {
+		return false, ErrUnknownFeature
+	}
+	if totalMembers <= 0 {
+		return false, ErrInvalidAmount
+	}
+
+	key := hg.barrierKey(ctx, featureName, barrierName)
+
+	var joined *redis.IntCmd
+	_, err = hg.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, memberID)
+		pipe.ExpireAt(ctx, key, hg.windowEnd(featureName))
+		joined = pipe.SCard(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return joined.Val() >= int64(totalMembers), nil
+}
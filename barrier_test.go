@@ -0,0 +1,87 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBarrierConsume(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 10,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, h.barrierKey(ctx, "feature1", "workers"))
+
+	tt := []struct {
+		description      string
+		featureName      string
+		memberID         string
+		totalMembers     int
+		expectedAllReady bool
+		expectedErr      error
+	}{
+		{
+			description:  "The first member should not complete the barrier",
+			featureName:  "feature1",
+			memberID:     "worker-1",
+			totalMembers: 3,
+		},
+		{
+			description:  "A member joining twice should count once",
+			featureName:  "feature1",
+			memberID:     "worker-1",
+			totalMembers: 3,
+		},
+		{
+			description:  "The second member should not complete the barrier",
+			featureName:  "feature1",
+			memberID:     "worker-2",
+			totalMembers: 3,
+		},
+		{
+			description:      "The last member should complete the barrier",
+			featureName:      "feature1",
+			memberID:         "worker-3",
+			totalMembers:     3,
+			expectedAllReady: true,
+		},
+		{
+			description:  "A non-positive member count should fail",
+			featureName:  "feature1",
+			memberID:     "worker-1",
+			totalMembers: 0,
+			expectedErr:  ErrInvalidAmount,
+		},
+		{
+			description:  "An unknown feature should fail",
+			featureName:  "feature-notexistent",
+			memberID:     "worker-1",
+			totalMembers: 3,
+			expectedErr:  ErrUnknownFeature,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			allReady, err := h.BarrierConsume(ctx, tc.featureName, "workers", tc.memberID, tc.totalMembers)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedAllReady, allReady)
+		})
+	}
+
+	t.Run("The barrier should expire with the window", func(t *testing.T) {
+		ttl := h.redisClient.TTL(ctx, h.barrierKey(ctx, "feature1", "workers")).Val()
+		require.InDelta(t, timeUntilEndOfDay().Seconds(), ttl.Seconds(), 2)
+	})
+}
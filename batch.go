@@ -0,0 +1,109 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+)
+
+//go:embed batch.lua
+var batchScriptData string
+
+// ConsumeItem is one feature and amount of a ConsumeBatch.
+type ConsumeItem struct {
+	FeatureName string `json:"featureName"`
+	Amount      int    `json:"amount"`
+}
+
+// ConsumeBatch consumes every item for userName or none of them, in one
+// script run. allowed reports whether all items fit their limits. When it is
+// false nothing was consumed and the results mark the items that did not fit
+// with Allowed false; the others would have fit. Items of the same feature
+// are counted together. Only the limits apply; burst allowances, cooldowns,
+// per minute rates, the local buffer and value serializers are not used.
+func (hg *HourGlass) ConsumeBatch(ctx context.Context, userName string, items []ConsumeItem) (results []ConsumeResult, allowed bool, err error) {
+	return hg.consumeItems(ctx, userName, items, false)
+}
+
+// ConsumePartial consumes every item for userName that fits its limit, in
+// one script run, and leaves the others. The results of items that did not
+// fit have Allowed false and the current counter. partiallyAllowed reports
+// whether at least one item was consumed; the caller decides whether to
+// proceed. It otherwise behaves like ConsumeBatch.
+func (hg *HourGlass) ConsumePartial(ctx context.Context, userName string, items []ConsumeItem) (results []ConsumeResult, partiallyAllowed bool, err error) {
+	results, _, err = hg.consumeItems(ctx, userName, items, true)
+	for _, result := range results {
+		partiallyAllowed = partiallyAllowed || result.Allowed
+	}
+
+	return results, partiallyAllowed, err
+}
+
+// consumeItems runs batch.lua for items. With partial the items that fit are
+// consumed even when others do not.
+func (hg *HourGlass) consumeItems(ctx context.Context, userName string, items []ConsumeItem, partial bool) (results []ConsumeResult, allowed bool, err error) {
+	keys := make([]string, len(items))
+	args := make([]any, 0, len(items)*3+1)
+	results = make([]ConsumeResult, len(items))
+	for i, item := range items {
+		if item.Amount <= 0 {
+			return nil, false, ErrInvalidAmount
+		}
+
+		key, limit, exists := hg.lookup(ctx, item.FeatureName, userName)
+		if !exists {
+			return nil, false, ErrUnknownFeature
+		}
+
+		keys[i] = key
+		args = append(args, item.Amount, limit, ttlMillis(hg.ttlFor(ctx, item.FeatureName, userName)))
+		results[i] = ConsumeResult{Current: -1, Limit: limit, ResetsAt: hg.userWindowEnd(ctx, item.FeatureName, userName)}
+	}
+
+	args = append(args, partial)
+
+	if hg.blacklist.contains(userName) {
+		return results, false, ErrUserBlacklisted
+	}
+	if hg.whitelist.contains(userName) {
+		for i := range results {
+			results[i].Current, results[i].Remaining, results[i].Allowed = 0, results[i].Limit, true
+		}
+		return results, true, nil
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		return hg.batchFailure(results, err)
+	}
+	if hg.breaker != nil && hg.breaker.isOpen() {
+		return hg.batchFailure(results, ErrCircuitOpen)
+	}
+
+	reply, err := hg.batchScript.Run(ctx, hg.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		return hg.batchFailure(results, err)
+	}
+
+	allowed = reply[0] == 1
+	for i := range results {
+		results[i].Current = int(reply[1+i*2])
+		results[i].Remaining = max(results[i].Limit-results[i].Current, 0)
+		results[i].Allowed = reply[2+i*2] == 0
+	}
+
+	return results, allowed, nil
+}
+
+// batchFailure answers a batch that could not be checked against Redis
+// according to the failure mode.
+func (hg *HourGlass) batchFailure(results []ConsumeResult, err error) ([]ConsumeResult, bool, error) {
+	var allowed bool
+	for i := range results {
+		var result ConsumeResult
+		result, err = hg.failureResult(results[i].Limit, err)
+		result.ResetsAt = results[i].ResetsAt
+		results[i] = result
+		allowed = result.Allowed
+	}
+
+	return results, allowed, err
+}
@@ -0,0 +1,43 @@
+-- ARGV holds amount, limit and TTL in milliseconds for every key in KEYS, followed by 1 when
+-- the items that fit should be consumed even though others do not.
+local partial = ARGV[#KEYS * 3 + 1] == '1'
+local pending = {}
+local currents = {}
+local failed = {}
+local all_allowed = 1
+
+for i, key in ipairs(KEYS) do
+    local amount = tonumber(ARGV[i * 3 - 2])
+    local limit = tonumber(ARGV[i * 3 - 1])
+
+    -- A feature can appear more than once, earlier items count against it.
+    local current = tonumber(redis.call('GET', key) or '0') + (pending[key] or 0)
+    currents[i] = current
+    if current + amount > limit then
+        failed[i] = 1
+        all_allowed = 0
+    else
+        failed[i] = 0
+        pending[key] = (pending[key] or 0) + amount
+    end
+end
+
+local result = {all_allowed}
+for i, key in ipairs(KEYS) do
+    local current = currents[i]
+    if failed[i] == 0 and (all_allowed == 1 or partial) then
+        local amount = tonumber(ARGV[i * 3 - 2])
+        local ttl_ms = tonumber(ARGV[i * 3])
+
+        -- Only a new key gets a TTL, later increments keep the original expiry.
+        if redis.call('SET', key, amount, 'PX', ttl_ms, 'NX') then
+            current = amount
+        else
+            current = redis.call('INCRBY', key, amount)
+        end
+    end
+    table.insert(result, current)
+    table.insert(result, failed[i])
+end
+
+return result
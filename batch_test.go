@@ -0,0 +1,156 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeBatch(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 10,
+			"feature2": 3,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "batch-user"), dailyKey("feature2", "batch-user"))
+
+	tt := []struct {
+		description     string
+		items           []ConsumeItem
+		expectedAllowed bool
+		expectedCurrent []int
+		expectedItems   []bool
+		expectedErr     error
+	}{
+		{
+			description:     "A batch within every limit should consume all items",
+			items:           []ConsumeItem{{FeatureName: "feature1", Amount: 4}, {FeatureName: "feature2", Amount: 2}},
+			expectedAllowed: true,
+			expectedCurrent: []int{4, 2},
+			expectedItems:   []bool{true, true},
+		},
+		{
+			description:     "A batch with one item over its limit should consume nothing",
+			items:           []ConsumeItem{{FeatureName: "feature1", Amount: 4}, {FeatureName: "feature2", Amount: 2}},
+			expectedAllowed: false,
+			expectedCurrent: []int{4, 2},
+			expectedItems:   []bool{true, false},
+		},
+		{
+			description:     "Items of the same feature should be counted together",
+			items:           []ConsumeItem{{FeatureName: "feature1", Amount: 4}, {FeatureName: "feature1", Amount: 4}},
+			expectedAllowed: false,
+			expectedCurrent: []int{4, 8},
+			expectedItems:   []bool{true, false},
+		},
+		{
+			description:     "A batch that fits should consume the remaining units",
+			items:           []ConsumeItem{{FeatureName: "feature1", Amount: 6}, {FeatureName: "feature2", Amount: 1}},
+			expectedAllowed: true,
+			expectedCurrent: []int{10, 3},
+			expectedItems:   []bool{true, true},
+		},
+		{
+			description: "A non-positive amount should fail",
+			items:       []ConsumeItem{{FeatureName: "feature1", Amount: 0}},
+			expectedErr: ErrInvalidAmount,
+		},
+		{
+			description: "An unknown feature should fail",
+			items:       []ConsumeItem{{FeatureName: "feature-notexistent", Amount: 1}},
+			expectedErr: ErrUnknownFeature,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			results, allowed, err := h.ConsumeBatch(ctx, "batch-user", tc.items)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedAllowed, allowed)
+			if tc.expectedErr != nil {
+				return
+			}
+
+			for i, result := range results {
+				require.Equal(t, tc.expectedCurrent[i], result.Current)
+				require.Equal(t, tc.expectedItems[i], result.Allowed)
+			}
+		})
+	}
+
+	t.Run("New counters should get an end of day expiry", func(t *testing.T) {
+		ttl := h.redisClient.TTL(ctx, dailyKey("feature2", "batch-user")).Val()
+		require.InDelta(t, h.ttlFor(ctx, "feature2", "batch-user").Seconds(), ttl.Seconds(), 2)
+	})
+}
+
+func TestConsumePartial(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 10,
+			"feature2": 3,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "partial-user"), dailyKey("feature2", "partial-user"))
+
+	tt := []struct {
+		description     string
+		items           []ConsumeItem
+		expectedAllowed bool
+		expectedCurrent []int
+		expectedItems   []bool
+	}{
+		{
+			description:     "A batch within every limit should consume all items",
+			items:           []ConsumeItem{{FeatureName: "feature1", Amount: 4}, {FeatureName: "feature2", Amount: 2}},
+			expectedAllowed: true,
+			expectedCurrent: []int{4, 2},
+			expectedItems:   []bool{true, true},
+		},
+		{
+			description:     "Items within their limit should be consumed when others are not",
+			items:           []ConsumeItem{{FeatureName: "feature1", Amount: 4}, {FeatureName: "feature2", Amount: 2}},
+			expectedAllowed: true,
+			expectedCurrent: []int{8, 2},
+			expectedItems:   []bool{true, false},
+		},
+		{
+			description:     "A batch where no item fits should consume nothing",
+			items:           []ConsumeItem{{FeatureName: "feature1", Amount: 4}, {FeatureName: "feature2", Amount: 2}},
+			expectedAllowed: false,
+			expectedCurrent: []int{8, 2},
+			expectedItems:   []bool{false, false},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			results, allowed, err := h.ConsumePartial(ctx, "partial-user", tc.items)
+			require.Nil(t, err)
+			require.Equal(t, tc.expectedAllowed, allowed)
+
+			for i, result := range results {
+				require.Equal(t, tc.expectedCurrent[i], result.Current)
+				require.Equal(t, tc.expectedItems[i], result.Allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	circuitClosed int32 = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// WithCircuitBreaker stops sending commands to Redis after threshold
+// consecutive connection errors. While the circuit is open, commands fail
+// immediately with ErrCircuitOpen and Consume answers according to the
+// FailureMode. After resetTimeout one probe command is let through; if it
+// succeeds the circuit closes again, otherwise it stays open for another
+// resetTimeout. The breaker wraps the clients of the connection pool, so it
+// is installed once per pool: with NewFromPool and Clone every instance
+// sharing the pool uses the breaker of the first one created with this
+// option, and it stays in place until the pool is closed.
+func WithCircuitBreaker(threshold int, resetTimeout time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.breaker = &circuitBreaker{threshold: int32(threshold), resetTimeout: resetTimeout}
+	}
+}
+
+type circuitBreaker struct {
+	threshold    int32
+	resetTimeout time.Duration
+
+	state    atomic.Int32
+	failures atomic.Int32
+	openedAt atomic.Int64
+}
+
+// isOpen reports whether calls are currently rejected, without claiming the
+// half-open probe.
+func (b *circuitBreaker) isOpen() bool {
+	switch b.state.Load() {
+	case circuitOpen:
+		return time.Since(time.Unix(0, b.openedAt.Load())) < b.resetTimeout
+	case circuitHalfOpen:
+		return true
+	default:
+		return false
+	}
+}
+
+// allow reports whether a command may be sent. Once resetTimeout has passed,
+// exactly one caller gets to send the probe.
+func (b *circuitBreaker) allow() bool {
+	switch b.state.Load() {
+	case circuitOpen:
+		if time.Since(time.Unix(0, b.openedAt.Load())) < b.resetTimeout {
+			return false
+		}
+		return b.state.CompareAndSwap(circuitOpen, circuitHalfOpen)
+	case circuitHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) record(err error) {
+	if !isConnectionError(err) {
+		b.failures.Store(0)
+		b.state.Store(circuitClosed)
+		return
+	}
+
+	if b.state.Load() == circuitHalfOpen || b.failures.Add(1) >= b.threshold {
+		b.openedAt.Store(time.Now().UnixNano())
+		b.state.Store(circuitOpen)
+	}
+}
+
+// isConnectionError reports whether err means Redis could not be reached.
+// Replies such as redis.Nil or a script error show that Redis is up, and a
+// cancelled or expired context says nothing about Redis either, only about
+// the caller's deadline.
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var redisErr redis.Error
+	return !errors.As(err, &redisErr)
+}
+
+func (b *circuitBreaker) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (b *circuitBreaker) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !b.allow() {
+			cmd.SetErr(ErrCircuitOpen)
+			return ErrCircuitOpen
+		}
+
+		err := next(ctx, cmd)
+		b.record(err)
+		return err
+	}
+}
+
+func (b *circuitBreaker) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !b.allow() {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrCircuitOpen)
+			}
+			return ErrCircuitOpen
+		}
+
+		err := next(ctx, cmds)
+		b.record(err)
+		return err
+	}
+}
@@ -0,0 +1,105 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("The circuit should open after consecutive connection errors", func(t *testing.T) {
+		h, err := New(&Config{
+			RedisAddress: "localhost:6390",
+			MaxRetries:   -1,
+			Limits: map[string]int{
+				"feature1": 5,
+			},
+		}, WithLazyConnect(), WithCircuitBreaker(2, time.Hour), WithFailureMode(FailClosed))
+		require.Nil(t, err)
+		defer h.Close()
+
+		for i := 0; i < 2; i++ {
+			result, err := h.consume(ctx, "feature1", "breaker-user")
+			require.ErrorIs(t, err, ErrNotConnected)
+			require.NotErrorIs(t, err, ErrCircuitOpen)
+			require.False(t, result.Allowed)
+		}
+
+		result, err := h.consume(ctx, "feature1", "breaker-user")
+		require.ErrorIs(t, err, ErrCircuitOpen)
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("A healthy Redis should keep the circuit closed", func(t *testing.T) {
+		h, err := New(&Config{
+			RedisAddress: "localhost:6379",
+			Limits: map[string]int{
+				"feature1": 5,
+			},
+		}, WithCircuitBreaker(1, time.Hour))
+		require.Nil(t, err)
+		defer h.Close()
+
+		h.redisClient.Del(ctx, dailyKey("feature1", "breaker-user"))
+
+		for i := 0; i < 3; i++ {
+			result, _ := h.Consume(ctx, "feature1", "breaker-user")
+			require.True(t, result.Allowed)
+		}
+		h.Get(ctx, "feature1", "breaker-missing-user")
+
+		require.Equal(t, circuitClosed, h.breaker.state.Load())
+	})
+
+	t.Run("The circuit should let one probe through after the reset timeout", func(t *testing.T) {
+		b := &circuitBreaker{threshold: 1, resetTimeout: 20 * time.Millisecond}
+		connectionErr := errors.New("dial tcp: connection refused")
+
+		b.record(connectionErr)
+		require.True(t, b.isOpen())
+		require.False(t, b.allow())
+
+		time.Sleep(30 * time.Millisecond)
+		require.False(t, b.isOpen())
+		require.True(t, b.allow())
+		require.False(t, b.allow())
+
+		b.record(connectionErr)
+		require.Equal(t, circuitOpen, b.state.Load())
+
+		time.Sleep(30 * time.Millisecond)
+		require.True(t, b.allow())
+		b.record(redis.Nil)
+		require.Equal(t, circuitClosed, b.state.Load())
+		require.True(t, b.allow())
+	})
+
+	t.Run("Instances sharing a pool should share one breaker", func(t *testing.T) {
+		pool, err := NewPool(&Config{RedisAddress: "localhost:6379"})
+		require.Nil(t, err)
+		defer pool.Close()
+
+		config := &Config{Limits: map[string]int{"feature1": 5}}
+		first, err := NewFromPool(pool, config, WithCircuitBreaker(1, time.Hour))
+		require.Nil(t, err)
+		second, err := NewFromPool(pool, config, WithCircuitBreaker(3, time.Minute))
+		require.Nil(t, err)
+		require.Nil(t, second.Close())
+
+		require.Same(t, first.breaker, second.breaker)
+		require.Same(t, pool.breaker, first.breaker)
+		require.Nil(t, first.Close())
+	})
+
+	t.Run("An expired caller deadline should not count as a connection error", func(t *testing.T) {
+		require.False(t, isConnectionError(context.DeadlineExceeded))
+		require.False(t, isConnectionError(context.Canceled))
+		require.True(t, isConnectionError(errors.New("dial tcp: connection refused")))
+	})
+}
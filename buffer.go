@@ -0,0 +1,242 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed flush.lua
+var flushScriptData string
+
+// WithLocalBuffer counts consumes in process and writes them to Redis in
+// batches, either every flushInterval or once a counter has size pending
+// increments.
+//
+// The buffer reads the Redis counter the first time a key is seen and after
+// every flush, so a user can never exceed their limit through a single
+// instance. Pending increments are not visible to Get or to other instances
+// until they are flushed, and when several instances buffer the same user the
+// flush only records what is left of the limit, so the combined usage can
+// briefly exceed it. Burst allowances are not applied to buffered consumes.
+func WithLocalBuffer(size int, flushInterval time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.localBuffer = &localBuffer{
+			size:          size,
+			flushInterval: flushInterval,
+			entries:       map[string]*bufferEntry{},
+			done:          make(chan struct{}),
+			stopped:       make(chan struct{}),
+		}
+	}
+}
+
+type bufferEntry struct {
+	// mu serializes consumes and flushes of one key, so a slow flush only
+	// holds up the users of that key.
+	mu      sync.Mutex
+	limit   int
+	ttl     time.Duration
+	base    int
+	pending int
+	// dropped is set once the entry is removed from the buffer. Callers
+	// that still hold it look the key up again.
+	dropped bool
+}
+
+type localBuffer struct {
+	hg            *HourGlass
+	size          int
+	flushInterval time.Duration
+
+	// mu guards entries only and is never held during Redis calls.
+	mu      sync.Mutex
+	entries map[string]*bufferEntry
+
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+func (b *localBuffer) start(hg *HourGlass) {
+	b.hg = hg
+
+	go func() {
+		defer close(b.stopped)
+
+		ticker := time.NewTicker(b.flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-b.done:
+				return
+			case <-ticker.C:
+				b.flushAll(context.Background())
+			}
+		}
+	}()
+}
+
+func (b *localBuffer) consume(ctx context.Context, key string, limit int, ttl time.Duration) (current int, allowed bool, err error) {
+	for {
+		entry, err := b.entry(ctx, key, limit, ttl)
+		if err != nil {
+			return -1, false, err
+		}
+
+		entry.mu.Lock()
+		if entry.dropped {
+			entry.mu.Unlock()
+			continue
+		}
+		current, allowed = b.consumeEntry(ctx, key, entry, limit)
+		entry.mu.Unlock()
+
+		return current, allowed, nil
+	}
+}
+
+// entry returns the buffered entry of key, reading the counter from Redis
+// without holding b.mu when the key is new.
+func (b *localBuffer) entry(ctx context.Context, key string, limit int, ttl time.Duration) (*bufferEntry, error) {
+	b.mu.Lock()
+	entry, exists := b.entries[key]
+	b.mu.Unlock()
+	if exists {
+		return entry, nil
+	}
+
+	base, err := b.hg.redisClient.Get(ctx, key).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if entry, exists := b.entries[key]; exists {
+		return entry, nil
+	}
+	entry = &bufferEntry{limit: limit, ttl: ttl, base: base}
+	b.entries[key] = entry
+
+	return entry, nil
+}
+
+// consumeEntry must be called with entry.mu held.
+func (b *localBuffer) consumeEntry(ctx context.Context, key string, entry *bufferEntry, limit int) (current int, allowed bool) {
+	current = entry.base + entry.pending
+	if current >= limit {
+		return current, false
+	}
+
+	entry.pending++
+	current++
+
+	if entry.pending >= b.size {
+		if err := b.flush(ctx, key, entry); err != nil {
+			b.hg.logger.WarnContext(ctx, "failed to flush local buffer", "key", key, "error", err)
+		}
+	}
+
+	return current, true
+}
+
+// credit takes back a pending increment. It returns false when there is
+// nothing pending for key and the counter in Redis has to be decremented.
+func (b *localBuffer) credit(key string, amount int) (current int, credited bool) {
+	b.mu.Lock()
+	entry, exists := b.entries[key]
+	b.mu.Unlock()
+	if !exists {
+		return 0, false
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.dropped {
+		return 0, false
+	}
+	if entry.pending == 0 {
+		b.drop(key, entry)
+		return 0, false
+	}
+	if entry.pending < amount {
+		return 0, false
+	}
+
+	entry.pending -= amount
+	return entry.base + entry.pending, true
+}
+
+// flushAll flushes a snapshot of the buffered keys one at a time, so that
+// consumes of other keys are not held up by the Redis round trips.
+func (b *localBuffer) flushAll(ctx context.Context) {
+	b.mu.Lock()
+	entries := maps.Clone(b.entries)
+	b.mu.Unlock()
+
+	for key, entry := range entries {
+		entry.mu.Lock()
+		if entry.dropped {
+			entry.mu.Unlock()
+			continue
+		}
+		if err := b.flush(ctx, key, entry); err != nil {
+			b.hg.logger.WarnContext(ctx, "failed to flush local buffer", "key", key, "error", err)
+			entry.mu.Unlock()
+			continue
+		}
+		// Drop the entry so the next consume re-reads the counter and
+		// picks up usage from other instances.
+		b.drop(key, entry)
+		entry.mu.Unlock()
+	}
+}
+
+// drop removes entry from the buffer. It must be called with entry.mu held.
+func (b *localBuffer) drop(key string, entry *bufferEntry) {
+	entry.dropped = true
+
+	b.mu.Lock()
+	if b.entries[key] == entry {
+		delete(b.entries, key)
+	}
+	b.mu.Unlock()
+}
+
+// flush must be called with entry.mu held.
+func (b *localBuffer) flush(ctx context.Context, key string, entry *bufferEntry) error {
+	if entry.pending == 0 {
+		return nil
+	}
+
+	result, err := b.hg.flushScript.Run(ctx, b.hg.redisClient, []string{key}, entry.pending, entry.limit, ttlMillis(entry.ttl)).Int64Slice()
+	if err != nil {
+		return err
+	}
+
+	if granted := int(result[1]); granted < entry.pending {
+		b.hg.logger.WarnContext(ctx, "local buffer exceeded the shared limit", "key", key, "pending", entry.pending, "granted", granted)
+	}
+	entry.base = int(result[0])
+	entry.pending = 0
+
+	return nil
+}
+
+func (b *localBuffer) Close() error {
+	b.closeOnce.Do(func() {
+		close(b.done)
+		<-b.stopped
+		b.flushAll(context.Background())
+	})
+	return nil
+}
@@ -0,0 +1,105 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBuffer(t *testing.T) {
+	limits := map[string]int{
+		"feature1": 4,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+	}, WithLocalBuffer(3, 1*time.Hour))
+
+	require.Nil(t, err)
+
+	key := dailyKey("feature1", "buffer-user")
+	h.redisClient.Del(ctx, key)
+
+	stored := func() int {
+		value, _ := h.redisClient.Get(ctx, key).Int()
+		return value
+	}
+
+	t.Run("Consumes below the buffer size should not be written to redis", func(t *testing.T) {
+		for i := 1; i <= 2; i++ {
+			result, _ := h.Consume(ctx, "feature1", "buffer-user")
+			require.True(t, result.Allowed)
+			require.Equal(t, i, result.Current)
+		}
+		require.Equal(t, 0, stored())
+	})
+
+	t.Run("Reaching the buffer size should flush to redis", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "buffer-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 3, result.Current)
+		require.Equal(t, 3, stored())
+	})
+
+	t.Run("The buffer should not allow the limit to be exceeded", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "buffer-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 4, result.Current)
+
+		result, _ = h.Consume(ctx, "feature1", "buffer-user")
+		require.False(t, result.Allowed)
+		require.Equal(t, 4, result.Current)
+	})
+
+	t.Run("A credit should take back a pending increment", func(t *testing.T) {
+		current, _ := h.Credit(ctx, "feature1", "buffer-user")
+		require.Equal(t, 3, current)
+		require.Equal(t, 3, stored())
+	})
+
+	t.Run("A key busy flushing should not hold up other users", func(t *testing.T) {
+		entry := h.localBuffer.entries[key]
+		entry.mu.Lock()
+		defer entry.mu.Unlock()
+
+		otherKey := dailyKey("feature1", "buffer-other-user")
+		h.redisClient.Del(ctx, otherKey)
+		defer h.redisClient.Del(ctx, otherKey)
+
+		done := make(chan ConsumeResult)
+		go func() {
+			result, _ := h.Consume(ctx, "feature1", "buffer-other-user")
+			done <- result
+		}()
+
+		select {
+		case result := <-done:
+			require.True(t, result.Allowed)
+			require.Equal(t, 1, result.Current)
+		case <-time.After(time.Second):
+			t.Fatal("consume waited for another user's key")
+		}
+	})
+
+	t.Run("Closing should flush pending increments", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "buffer-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 3, stored())
+
+		require.Nil(t, h.Close())
+
+		rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+		defer rdb.Close()
+
+		value, err := rdb.Get(ctx, key).Int()
+		require.Nil(t, err)
+		require.Equal(t, 4, value)
+	})
+}
@@ -0,0 +1,41 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+	"time"
+)
+
+//go:embed burstrate.lua
+var burstRateScriptData string
+
+const burstRateWindow = time.Minute
+
+// burstRateKey returns the sorted set that holds the consumes of the last
+// minute for key.
+func burstRateKey(key string) string {
+	return key + ":rate"
+}
+
+// checkBurstRate records a consume in the per minute window of key and
+// reports whether it stays within maxPerMinute. The returned release func
+// removes the recorded consume again, for when the daily limit denies it.
+func (hg *HourGlass) checkBurstRate(ctx context.Context, key string, maxPerMinute int) (allowed bool, release func(), err error) {
+	member, err := newToken()
+	if err != nil {
+		return false, nil, err
+	}
+
+	rateKey := burstRateKey(key)
+	now := time.Now().UnixMilli()
+	allowed, err = hg.burstRateScript.Run(ctx, hg.redisClient, []string{rateKey}, maxPerMinute, now, burstRateWindow.Milliseconds(), member).Bool()
+	if err != nil {
+		return false, nil, err
+	}
+
+	release = func() {
+		hg.redisClient.ZRem(context.Background(), rateKey, member)
+	}
+
+	return allowed, release, nil
+}
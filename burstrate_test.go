@@ -0,0 +1,66 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxBurstPerMinute(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"rate-feature":  10,
+			"rate-daily":    1,
+			"rate-no-limit": 10,
+		},
+		Features: map[string]FeatureConfig{
+			"rate-feature": {MaxBurstPerMinute: 2},
+			"rate-daily":   {MaxBurstPerMinute: 5},
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	for _, featureName := range []string{"rate-feature", "rate-daily", "rate-no-limit"} {
+		key := dailyKey(featureName, "rate-user")
+		h.redisClient.Del(ctx, key, burstRateKey(key))
+	}
+
+	t.Run("Consumes beyond the per minute rate should be denied", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			result, err := h.consume(ctx, "rate-feature", "rate-user")
+			require.Nil(t, err)
+			require.True(t, result.Allowed)
+		}
+
+		result, err := h.consume(ctx, "rate-feature", "rate-user")
+		require.ErrorIs(t, err, ErrBurstLimitExceeded)
+		require.False(t, result.Allowed)
+
+		current, _ := h.Get(ctx, "rate-feature", "rate-user")
+		require.Equal(t, 2, current)
+	})
+
+	t.Run("Consumes denied by the daily limit should not count towards the rate", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "rate-daily", "rate-user")
+		require.True(t, result.Allowed)
+		result, _ = h.Consume(ctx, "rate-daily", "rate-user")
+		require.False(t, result.Allowed)
+
+		key := dailyKey("rate-daily", "rate-user")
+		require.Equal(t, int64(1), h.redisClient.ZCard(ctx, burstRateKey(key)).Val())
+	})
+
+	t.Run("Features without a rate should only use the daily limit", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			result, _ := h.Consume(ctx, "rate-no-limit", "rate-user")
+			require.True(t, result.Allowed)
+		}
+	})
+}
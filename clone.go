@@ -0,0 +1,43 @@
+package hourglass
+
+import (
+	"fmt"
+	"maps"
+)
+
+// Clone creates an HourGlass with its own limits that shares this instance's
+// Redis connection. The clone's keys get a distinct KeyPrefix derived from the
+// order in which clones are created, so clones created in the same order
+// after a restart pick their counters back up. The clone is created with the
+// options of hg. Closing the clone leaves the connection and the counter
+// backend open; they are owned by the original instance.
+func (hg *HourGlass) Clone(newLimits map[string]int) (*HourGlass, error) {
+	config := hg.appConfig
+	config.Limits = newLimits
+	config.Environment = ""
+	// Limits from expressions would otherwise override newLimits.
+	config.LimitExpressions = nil
+	config.Features = maps.Clone(hg.appConfig.Features)
+	for featureName, featureConfig := range config.Features {
+		featureConfig.Limit = 0
+		config.Features[featureName] = featureConfig
+	}
+	config.KeyPrefix = fmt.Sprintf("%sclone%d:", hg.appConfig.KeyPrefix, hg.clones.Add(1))
+
+	// Replaying the options gives the clone the same behaviour with its own
+	// buffer and janitor. Its limits come from newLimits, and it uses the
+	// counter backend of hg, which is bound to one listen address.
+	clone, err := newHourGlass(hg.options...)
+	if err != nil {
+		return nil, err
+	}
+	clone.limitProvider = nil
+	clone.backend = nil
+
+	if err := clone.init(hg.pool, &config); err != nil {
+		return nil, err
+	}
+	clone.backend = hg.backend
+
+	return clone, nil
+}
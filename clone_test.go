@@ -0,0 +1,100 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"clone-feature": 5,
+		},
+		KeyPrefix: "parent:",
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	clone, err := h.Clone(map[string]int{
+		"clone-feature": 1,
+	})
+	require.Nil(t, err)
+
+	h.redisClient.Del(ctx, "parent:"+dailyKey("clone-feature", "clone-user"), "parent:clone1:"+dailyKey("clone-feature", "clone-user"))
+
+	t.Run("The clone should share the connection but use its own limits", func(t *testing.T) {
+		require.Same(t, h.redisClient, clone.redisClient)
+
+		_, limit := clone.Get(ctx, "clone-feature", "clone-user")
+		require.Equal(t, 1, limit)
+	})
+
+	t.Run("The clone should keep its counters under a distinct prefix", func(t *testing.T) {
+		result, _ := clone.Consume(ctx, "clone-feature", "clone-user")
+		require.True(t, result.Allowed)
+		result, _ = clone.Consume(ctx, "clone-feature", "clone-user")
+		require.False(t, result.Allowed)
+
+		result, _ = h.Consume(ctx, "clone-feature", "clone-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+
+		value, err := h.redisClient.Get(ctx, "parent:clone1:"+dailyKey("clone-feature", "clone-user")).Int()
+		require.Nil(t, err)
+		require.Equal(t, 1, value)
+	})
+
+	t.Run("Closing the clone should leave the original connection open", func(t *testing.T) {
+		require.Nil(t, clone.Close())
+
+		result, _ := h.Consume(ctx, "clone-feature", "clone-user")
+		require.True(t, result.Allowed)
+	})
+}
+
+func TestCloneKeepsOptions(t *testing.T) {
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"clone-feature": 5,
+		},
+		KeyPrefix: "parent:",
+	}, WithDynamicPrefix(func(ctx context.Context) string {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenant + ":"
+	}))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	clone, err := h.Clone(map[string]int{
+		"clone-feature": 1,
+	})
+	require.Nil(t, err)
+	defer clone.Close()
+
+	acme := context.WithValue(context.Background(), tenantKey{}, "acme")
+	globex := context.WithValue(context.Background(), tenantKey{}, "globex")
+	h.redisClient.Del(acme, "parent:clone1:acme:"+dailyKey("clone-feature", "clone-user"), "parent:clone1:globex:"+dailyKey("clone-feature", "clone-user"))
+
+	t.Run("The clone should keep the dynamic prefix of the original", func(t *testing.T) {
+		result, _ := clone.Consume(acme, "clone-feature", "clone-user")
+		require.True(t, result.Allowed)
+
+		result, _ = clone.Consume(globex, "clone-feature", "clone-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+
+		exists, err := h.redisClient.Exists(acme, "parent:clone1:acme:"+dailyKey("clone-feature", "clone-user"), "parent:clone1:globex:"+dailyKey("clone-feature", "clone-user")).Result()
+		require.Nil(t, err)
+		require.Equal(t, int64(2), exists)
+	})
+}
@@ -0,0 +1,83 @@
+package hourglass
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// clusterErrorPrefixes are the Redis Cluster replies for keys that moved to
+// another node or slots that are not served right now.
+var clusterErrorPrefixes = []string{"MOVED ", "ASK ", "CLUSTERDOWN ", "TRYAGAIN "}
+
+// isClusterError reports whether err is a Redis Cluster redirect or outage,
+// for example during a slot migration. The client talks to a single node and
+// cannot follow redirects, so retrying the same command does not help.
+func isClusterError(err error) bool {
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		return false
+	}
+
+	for _, prefix := range clusterErrorPrefixes {
+		if strings.HasPrefix(redisErr.Error(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithClusterSafeHashTag wraps the user part of every per-user key in a Redis
+// Cluster hash tag, e.g. lattice:{alice}:2024-01-02, so all keys of a user
+// hash to the same slot and scripts that touch several of them, such as
+// ConsumeBatch and feature groups, work in cluster mode. Calls that touch the
+// keys of two users, such as TransferCredit, still cross slots. The tag only
+// takes effect if KeyPrefix has no braces of its own. It changes the key
+// format: run MigrateKeys on an instance with this option to tag existing
+// keys.
+func WithClusterSafeHashTag() Option {
+	return func(hg *HourGlass) {
+		hg.clusterHashTag = true
+	}
+}
+
+// hashTag wraps s in a hash tag when WithClusterSafeHashTag is set.
+func (hg *HourGlass) hashTag(s string) string {
+	if !hg.clusterHashTag {
+		return s
+	}
+
+	return "{" + s + "}"
+}
+
+// untagUser returns the user part of a key without its hash tag.
+func untagUser(keyUser string) string {
+	if strings.HasPrefix(keyUser, "{") && strings.HasSuffix(keyUser, "}") {
+		return keyUser[1 : len(keyUser)-1]
+	}
+
+	return keyUser
+}
+
+// tagKeyUser adds the hash tag to the user part of an unprefixed key of
+// featureName that does not have one yet.
+func (hg *HourGlass) tagKeyUser(featureName, key string) string {
+	if !hg.clusterHashTag {
+		return key
+	}
+
+	segments := strings.Split(key, ":")
+	user := 1
+	if len(segments) > 3 {
+		if _, ok := hg.appConfig.PriorityLimits[featureName][segments[1]]; ok {
+			user = 2
+		}
+	}
+	if len(segments) <= user || strings.HasPrefix(segments[user], "{") {
+		return key
+	}
+
+	segments[user] = hg.hashTag(segments[user])
+	return strings.Join(segments, ":")
+}
@@ -0,0 +1,112 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// redisReply is an error reply as returned by the Redis server.
+type redisReply string
+
+func (r redisReply) Error() string { return string(r) }
+
+func (redisReply) RedisError() {}
+
+func TestIsClusterError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "Moved", err: redisReply("MOVED 3999 127.0.0.1:6381"), expected: true},
+		{name: "Ask", err: redisReply("ASK 3999 127.0.0.1:6381"), expected: true},
+		{name: "Cluster down", err: redisReply("CLUSTERDOWN The cluster is down"), expected: true},
+		{name: "Try again", err: redisReply("TRYAGAIN Multiple keys request during rehashing of slot"), expected: true},
+		{name: "Script error", err: redisReply("ERR Error running script"), expected: false},
+		{name: "Connection error", err: errors.New("dial tcp: connection refused"), expected: false},
+		{name: "No error", err: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, isClusterError(tt.err))
+		})
+	}
+}
+
+func TestConsumeClusterError(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+	}, WithConsumeScript("return redis.error_reply('MOVED 3999 127.0.0.1:6381')"))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	t.Run("A redirect should deny the consume instead of failing open", func(t *testing.T) {
+		result, err := h.Consume(ctx, "feature1", "cluster-user")
+		require.ErrorIs(t, err, ErrClusterUnavailable)
+		require.False(t, result.Allowed)
+		require.Equal(t, 5, result.Limit)
+	})
+}
+
+func TestClusterSafeHashTag(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits:       map[string]int{"tagged-a": 5, "tagged-b": 5},
+		KeyPrefix:    "hashtag-test:",
+	}, WithClusterSafeHashTag())
+	require.NoError(t, err)
+	defer h.Close()
+
+	keys, _ := h.redisClient.Keys(ctx, "hashtag-test:*").Result()
+	if len(keys) > 0 {
+		h.redisClient.Del(ctx, keys...)
+	}
+
+	t.Run("Keys should wrap the user in a hash tag", func(t *testing.T) {
+		key, _, _ := h.lookup(ctx, "tagged-a", "alice")
+		require.Equal(t, "hashtag-test:tagged-a:{alice}:"+time.Now().UTC().Format("2006-01-02"), key)
+	})
+
+	t.Run("A batch should consume the tagged keys", func(t *testing.T) {
+		_, allowed, err := h.ConsumeBatch(ctx, "alice", []ConsumeItem{
+			{FeatureName: "tagged-a", Amount: 1},
+			{FeatureName: "tagged-b", Amount: 2},
+		})
+		require.NoError(t, err)
+		require.True(t, allowed)
+
+		current, _ := h.Get(ctx, "tagged-b", "alice")
+		require.Equal(t, 2, current)
+	})
+
+	t.Run("Reported user names should not include the hash tag", func(t *testing.T) {
+		users, err := h.ActiveUsers(ctx, "tagged-a")
+		require.NoError(t, err)
+		require.Equal(t, []string{"alice"}, users)
+	})
+
+	t.Run("MigrateKeys should tag existing keys", func(t *testing.T) {
+		h.redisClient.Set(ctx, "hashtag-test:"+dailyKey("tagged-a", "bob"), 3, time.Minute)
+
+		migrated, err := h.MigrateKeys(ctx, "hashtag-test:", "hashtag-test:", false)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), migrated)
+
+		current, _ := h.Get(ctx, "tagged-a", "bob")
+		require.Equal(t, 3, current)
+	})
+}
@@ -0,0 +1,39 @@
+package hourglass
+
+import (
+	"context"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// WithCoalescing shares one Redis read between concurrent reads of the same
+// counter, e.g. a burst of Get calls for one feature and user. Consume is
+// not coalesced: every call has to count against the limit, so sharing one
+// result would let several calls through on a single unit.
+func WithCoalescing() Option {
+	return func(hg *HourGlass) {
+		hg.coalescer = &singleflight.Group{}
+	}
+}
+
+// readCounter returns the raw value of the counter at key from the read
+// client, sharing the read with concurrent callers when coalescing is on.
+// A caller whose ctx ends stops waiting without cancelling the shared read.
+func (hg *HourGlass) readCounter(ctx context.Context, key string) (string, error) {
+	if hg.coalescer == nil {
+		return hg.readClient.Get(ctx, key).Result()
+	}
+
+	results := hg.coalescer.DoChan(key, func() (interface{}, error) {
+		return hg.readClient.Get(context.WithoutCancel(ctx), key).Result()
+	})
+	select {
+	case result := <-results:
+		if result.Err != nil {
+			return "", result.Err
+		}
+		return result.Val.(string), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
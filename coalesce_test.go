@@ -0,0 +1,96 @@
+package hourglass
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// countingHook counts the GET commands sent to Redis.
+type countingHook struct {
+	gets atomic.Int32
+	wait chan struct{}
+}
+
+func (h *countingHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *countingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "get" {
+			h.gets.Add(1)
+			<-h.wait
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *countingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestCoalescing(t *testing.T) {
+	ctx := context.Background()
+
+	tt := []struct {
+		description  string
+		opts         []Option
+		expectedGets int32
+	}{
+		{
+			description:  "Concurrent reads should share one GET",
+			opts:         []Option{WithCoalescing()},
+			expectedGets: 1,
+		},
+		{
+			description:  "Without coalescing every read should send a GET",
+			expectedGets: 10,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			h, err := New(&Config{
+				RedisAddress: "localhost:6379",
+				Limits:       map[string]int{"coalesce": 10},
+				KeyPrefix:    "coalesce-test:",
+			}, test.opts...)
+			require.NoError(t, err)
+			defer h.Close()
+
+			require.NoError(t, h.SetUsage(ctx, "coalesce", "alice", 4))
+
+			hook := &countingHook{wait: make(chan struct{})}
+			h.readClient.AddHook(hook)
+
+			var wg sync.WaitGroup
+			var started sync.WaitGroup
+			currents := make([]int, 10)
+			for i := range currents {
+				wg.Add(1)
+				started.Add(1)
+				go func() {
+					defer wg.Done()
+					started.Done()
+					currents[i], _ = h.Get(ctx, "coalesce", "alice")
+				}()
+			}
+			started.Wait()
+
+			// Let the reads pile up before Redis answers.
+			require.Eventually(t, func() bool { return hook.gets.Load() >= test.expectedGets }, time.Second, time.Millisecond)
+			time.Sleep(50 * time.Millisecond)
+			close(hook.wait)
+			wg.Wait()
+
+			require.Equal(t, test.expectedGets, hook.gets.Load())
+			for _, current := range currents {
+				require.Equal(t, 4, current)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package hourglass
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// unixEpochJulianDay is the Julian day number of 1970-01-01.
+const unixEpochJulianDay = 2440588
+
+// WithCompressedKeys shortens counter keys from feature:user:date to the
+// CRC32 of the feature and of the user and the Julian day number, e.g.
+// 8c736521:3610a686:2461329, to save memory with many users. Windowed
+// features keep their window start in place of the day. Consume records
+// each feature and user in the hash {KeyPrefix}keymap when it creates their
+// first counter, so DecompressKey can map keys back. Different users whose
+// names share a CRC32 share a counter; collisions are logged when recorded.
+func WithCompressedKeys() Option {
+	return func(hg *HourGlass) {
+		hg.compressedKeys = true
+	}
+}
+
+func crc32Hex(s string) string {
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(s)))
+}
+
+// julianDay returns the Julian day number of the date of at.
+func julianDay(at time.Time) int {
+	year, month, day := at.Date()
+	return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix()/86400) + unixEpochJulianDay
+}
+
+// compressedCounterKey is counterKey with WithCompressedKeys for the window
+// that contains at.
+func (hg *HourGlass) compressedCounterKey(ctx context.Context, featureName, keyFeature, keyUser string, at time.Time) string {
+	window := strconv.Itoa(julianDay(at))
+	if _, windowed := hg.windowStartAt(featureName, at); windowed {
+		window = hg.windowIDAt(featureName, at)
+	}
+
+	return hg.keyPrefix(ctx) + crc32Hex(keyFeature) + ":" + hg.hashTag(crc32Hex(untagUser(keyUser))) + ":" + window
+}
+
+func (hg *HourGlass) keyMapKey(ctx context.Context) string {
+	return hg.keyPrefix(ctx) + "keymap"
+}
+
+// recordCompressedKey stores which feature and user the compressed keys of
+// featureName for userName stand for.
+func (hg *HourGlass) recordCompressedKey(ctx context.Context, featureName, userName string) error {
+	keyFeature, _, _ := hg.lookupLimit(featureName, userName)
+	keyUser := untagUser(hg.keyUser(userName))
+
+	field := crc32Hex(keyFeature) + ":" + crc32Hex(keyUser)
+	value, err := json.Marshal([]string{keyFeature, keyUser})
+	if err != nil {
+		return err
+	}
+
+	set, err := hg.redisClient.HSetNX(ctx, hg.keyMapKey(ctx), field, value).Result()
+	if err != nil || set {
+		return err
+	}
+
+	existing, err := hg.redisClient.HGet(ctx, hg.keyMapKey(ctx), field).Result()
+	if err != nil {
+		return err
+	}
+	if existing != string(value) {
+		hg.logger.WarnContext(ctx, "compressed key collision", "feature", featureName, "user", userName, "key", field, "existing", existing)
+	}
+
+	return nil
+}
+
+// DecompressKey returns the feature, user and window start a key written
+// with WithCompressedKeys stands for. The feature includes the priority level
+// for priority counters and the user is hashed with WithHashedKeys. Keys
+// that are malformed or were never recorded return ErrUnknownKey.
+func (hg *HourGlass) DecompressKey(ctx context.Context, key string) (featureName, userName string, date time.Time, err error) {
+	parts := strings.Split(strings.TrimPrefix(key, hg.keyPrefix(ctx)), ":")
+	if len(parts) != 3 {
+		return "", "", time.Time{}, ErrUnknownKey
+	}
+
+	if day, err := strconv.Atoi(parts[2]); err == nil {
+		date = time.Unix(int64(day-unixEpochJulianDay)*86400, 0).UTC()
+	} else if date, err = time.Parse("2006-01-02T150405", parts[2]); err != nil {
+		return "", "", time.Time{}, ErrUnknownKey
+	}
+
+	value, err := hg.redisClient.HGet(ctx, hg.keyMapKey(ctx), parts[0]+":"+untagUser(parts[1])).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", "", time.Time{}, ErrUnknownKey
+	}
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	var names []string
+	if err := json.Unmarshal([]byte(value), &names); err != nil || len(names) != 2 {
+		return "", "", time.Time{}, ErrUnknownKey
+	}
+
+	return names[0], names[1], date, nil
+}
@@ -0,0 +1,83 @@
+package hourglass
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCompressedKeys(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+		KeyPrefix: "compressed-test:",
+	}, WithCompressedKeys())
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := h.KeyFor(ctx, "feature1", "compressed-user", time.Now(), false)
+	h.redisClient.Del(ctx, key, h.keyMapKey(ctx))
+
+	t.Run("Counters should be stored under the compressed key", func(t *testing.T) {
+		result, err := h.Consume(ctx, "feature1", "compressed-user")
+		require.Nil(t, err)
+		require.Equal(t, 1, result.Current)
+
+		require.Equal(t, "compressed-test:"+crc32Hex("feature1")+":"+crc32Hex("compressed-user")+":"+
+			strconv.Itoa(julianDay(time.Now().UTC())), key)
+		current, err := h.redisClient.Get(ctx, key).Int()
+		require.Nil(t, err)
+		require.Equal(t, 1, current)
+	})
+
+	tt := []struct {
+		description     string
+		key             string
+		expectedFeature string
+		expectedUser    string
+		expectedErr     error
+	}{
+		{
+			description:     "A recorded key should decompress to its feature and user",
+			key:             key,
+			expectedFeature: "feature1",
+			expectedUser:    "compressed-user",
+		},
+		{
+			description: "A key that was never recorded should fail",
+			key:         "compressed-test:00000000:00000000:2460000",
+			expectedErr: ErrUnknownKey,
+		},
+		{
+			description: "A malformed key should fail",
+			key:         "compressed-test:feature1",
+			expectedErr: ErrUnknownKey,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			featureName, userName, date, err := h.DecompressKey(ctx, tc.key)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedFeature, featureName)
+			require.Equal(t, tc.expectedUser, userName)
+			if tc.expectedErr == nil {
+				require.Equal(t, time.Now().UTC().Format("2006-01-02"), date.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestJulianDay(t *testing.T) {
+	require.Equal(t, 2440588, julianDay(time.Date(1970, 1, 1, 12, 0, 0, 0, time.UTC)))
+	require.Equal(t, 2451545, julianDay(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)))
+}
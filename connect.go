@@ -0,0 +1,35 @@
+package hourglass
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithLazyConnect makes New skip the initial Ping so that it succeeds even
+// when Redis is not reachable yet. The connection is verified by Connect or by
+// the first operation, which returns an error wrapping ErrNotConnected while
+// Redis stays down.
+func WithLazyConnect() Option {
+	return func(hg *HourGlass) {
+		hg.lazyConnect = true
+	}
+}
+
+// Connect verifies the Redis connection. It is only needed with
+// WithLazyConnect, to fail fast instead of on the first operation.
+func (hg *HourGlass) Connect(ctx context.Context) error {
+	if err := hg.redisClient.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrNotConnected, err)
+	}
+
+	hg.connected.Store(true)
+	return nil
+}
+
+func (hg *HourGlass) ensureConnected(ctx context.Context) error {
+	if hg.connected.Load() {
+		return nil
+	}
+
+	return hg.Connect(ctx)
+}
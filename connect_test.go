@@ -0,0 +1,51 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyConnect(t *testing.T) {
+	limits := map[string]int{
+		"feature1": 5,
+	}
+
+	ctx := context.Background()
+
+	t.Run("New should succeed while redis is unreachable", func(t *testing.T) {
+		h, err := New(&Config{
+			RedisAddress:  "localhost:6399",
+			RedisPassword: "",
+			Limits:        limits,
+		}, WithLazyConnect())
+
+		require.Nil(t, err)
+		defer h.Close()
+
+		_, err = h.consume(ctx, "feature1", "lazy-user")
+		require.ErrorIs(t, err, ErrNotConnected)
+
+		_, _, err = h.get(ctx, "feature1", "lazy-user")
+		require.ErrorIs(t, err, ErrNotConnected)
+
+		require.ErrorIs(t, h.Connect(ctx), ErrNotConnected)
+	})
+
+	t.Run("Connect should succeed once redis is reachable", func(t *testing.T) {
+		h, err := New(&Config{
+			RedisAddress:  "localhost:6379",
+			RedisPassword: "",
+			Limits:        limits,
+		}, WithLazyConnect())
+
+		require.Nil(t, err)
+		defer h.Close()
+
+		require.Nil(t, h.Connect(ctx))
+
+		_, err = h.consume(ctx, "feature1", "lazy-user")
+		require.Nil(t, err)
+	})
+}
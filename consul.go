@@ -0,0 +1,92 @@
+package hourglass
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// WithConsulLimitProvider loads limits from the Consul KV store. Every key
+// {kvPrefix}/{feature} holds the integer limit for that feature and is polled
+// every pollInterval.
+func WithConsulLimitProvider(client *api.Client, kvPrefix string, pollInterval time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.limitProvider = &consulLimitProvider{
+			staticLimitProvider: newStaticLimitProvider(nil),
+			kv:                  client.KV(),
+			kvPrefix:            strings.TrimSuffix(kvPrefix, "/") + "/",
+			pollInterval:        pollInterval,
+			done:                make(chan struct{}),
+		}
+	}
+}
+
+type consulLimitProvider struct {
+	*staticLimitProvider
+	kv           *api.KV
+	kvPrefix     string
+	pollInterval time.Duration
+	logger       *slog.Logger
+	done         chan struct{}
+	closeOnce    sync.Once
+}
+
+func (p *consulLimitProvider) start(ctx context.Context, logger *slog.Logger) error {
+	p.logger = logger
+	if err := p.poll(ctx); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(p.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				if err := p.poll(context.Background()); err != nil {
+					p.logger.WarnContext(ctx, "failed to poll consul for limits", "prefix", p.kvPrefix, "error", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (p *consulLimitProvider) poll(ctx context.Context) error {
+	pairs, _, err := p.kv.List(p.kvPrefix, (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	limits := make(map[string]int, len(pairs))
+	for _, pair := range pairs {
+		featureName := strings.TrimPrefix(pair.Key, p.kvPrefix)
+		if featureName == "" || strings.Contains(featureName, "/") {
+			continue
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(string(pair.Value)))
+		if err != nil {
+			p.logger.WarnContext(ctx, "ignoring invalid limit in consul", "key", pair.Key, "error", err)
+			continue
+		}
+		limits[featureName] = limit
+	}
+
+	p.store(limits)
+	return nil
+}
+
+func (p *consulLimitProvider) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	return nil
+}
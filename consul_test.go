@@ -0,0 +1,94 @@
+package hourglass
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsulLimitProvider(t *testing.T) {
+	ctx := context.Background()
+
+	var featureLimit atomic.Value
+	featureLimit.Store("2")
+
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/kv/hourglass/limits/", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(api.KVPairs{
+			{Key: "hourglass/limits/feature1", Value: []byte(featureLimit.Load().(string))},
+			{Key: "hourglass/limits/invalid", Value: []byte("not-a-number")},
+			{Key: "hourglass/limits/nested/feature", Value: []byte("5")},
+		})
+	}))
+	defer consul.Close()
+
+	client, err := api.NewClient(&api.Config{Address: consul.Listener.Addr().String()})
+	require.Nil(t, err)
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+	}, WithConsulLimitProvider(client, "hourglass/limits", 10*time.Millisecond))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "consul-user"))
+
+	t.Run("Limits should be loaded from consul when the instance is created", func(t *testing.T) {
+		require.Equal(t, map[string]int{"feature1": 2}, h.limitProvider.Limits())
+
+		result, _ := h.Consume(ctx, "feature1", "consul-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+		require.Equal(t, 2, result.Limit)
+	})
+
+	t.Run("Limit changes in consul should be picked up on the next poll", func(t *testing.T) {
+		featureLimit.Store("7")
+
+		require.Eventually(t, func() bool {
+			_, limit := h.Get(ctx, "feature1", "consul-user")
+			return limit == 7
+		}, 1*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Closing the provider twice should not panic", func(t *testing.T) {
+		provider := h.limitProvider.(*consulLimitProvider)
+		require.NotPanics(t, func() {
+			require.Nil(t, provider.Close())
+			require.Nil(t, provider.Close())
+		})
+	})
+}
+
+func TestConsulLimitProviderInvalidConfig(t *testing.T) {
+	var polls atomic.Int64
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(api.KVPairs{})
+	}))
+	defer consul.Close()
+
+	client, err := api.NewClient(&api.Config{Address: consul.Listener.Addr().String()})
+	require.Nil(t, err)
+
+	_, err = New(&Config{
+		RedisAddress:   "localhost:6379",
+		FeatureScripts: map[string]string{"feature1": " "},
+	}, WithConsulLimitProvider(client, "hourglass/limits", 10*time.Millisecond))
+	require.ErrorIs(t, err, ErrEmptyConsumeScript)
+
+	time.Sleep(50 * time.Millisecond)
+	require.Zero(t, polls.Load())
+}
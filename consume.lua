@@ -1,6 +1,8 @@
 local key = KEYS[1]
+local burst_key = KEYS[2]
 local limit = tonumber(ARGV[1])
-local ttl = tonumber(ARGV[2])
+local ttl_ms = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3]) or 0
 
 local current = redis.call('GET', key)
 if current == false then
@@ -10,17 +12,28 @@ else
 end
 
 if current >= limit then
-    return {current, limit, 0}
-end
+    -- Over the limit, the burst allowance covers up to burst extra calls a day.
+    if burst_key == nil or burst <= 0 then
+        return {current, limit, 0, 0}
+    end
 
-local new_value = redis.call('INCR', key)
-if redis.call('TTL', key) == -1 then
-    redis.call('EXPIRE', key, ttl)
+    local used = tonumber(redis.call('GET', burst_key) or '0')
+    if used >= burst then
+        return {current, limit, 0, 0}
+    end
+
+    if redis.call('INCR', burst_key) == 1 then
+        redis.call('PEXPIRE', burst_key, ttl_ms)
+    end
+
+    return {redis.call('INCR', key), limit, 1, 1}
 end
 
-if new_value > limit then
-    redis.call('DECR', key)
-    return {limit, limit, 0}
+-- Only a new key gets a TTL, later increments keep the original expiry.
+if current == 0 and redis.call('SET', key, 1, 'PX', ttl_ms, 'NX') then
+    return {1, limit, 1, 0}
 end
 
-return {new_value, limit, 1}
\ No newline at end of file
+local new_value = redis.call('INCR', key)
+
+return {new_value, limit, 1, 0}
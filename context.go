@@ -0,0 +1,40 @@
+package hourglass
+
+import "context"
+
+type contextKey int
+
+const (
+	featureContextKey contextKey = iota
+	consumeResultContextKey
+	userLocationContextKey
+)
+
+type featureContext struct {
+	featureName string
+	userName    string
+}
+
+// WithFeatureContext returns a copy of ctx that carries the feature and user
+// that ConsumeContext consumes for.
+func WithFeatureContext(ctx context.Context, featureName, userName string) context.Context {
+	return context.WithValue(ctx, featureContextKey, featureContext{featureName: featureName, userName: userName})
+}
+
+// ConsumeContext consumes quota for the feature and user stored in ctx by
+// WithFeatureContext. It returns ErrNoFeatureContext when ctx carries neither.
+func (hg *HourGlass) ConsumeContext(ctx context.Context) (ConsumeResult, error) {
+	fc, ok := ctx.Value(featureContextKey).(featureContext)
+	if !ok {
+		return ConsumeResult{}, ErrNoFeatureContext
+	}
+
+	return hg.consume(ctx, fc.featureName, fc.userName)
+}
+
+// ConsumeResultFromContext returns the result of the consume done by
+// Middleware for the current request.
+func ConsumeResultFromContext(ctx context.Context) (ConsumeResult, bool) {
+	result, ok := ctx.Value(consumeResultContextKey).(ConsumeResult)
+	return result, ok
+}
@@ -0,0 +1,43 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeContext(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "ctx-user"))
+
+	t.Run("The feature and user should be read from the context", func(t *testing.T) {
+		featureCtx := WithFeatureContext(ctx, "feature1", "ctx-user")
+
+		result, err := h.ConsumeContext(featureCtx)
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+
+		result, err = h.ConsumeContext(featureCtx)
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("A context without feature and user should return an error", func(t *testing.T) {
+		_, err := h.ConsumeContext(ctx)
+		require.ErrorIs(t, err, ErrNoFeatureContext)
+	})
+}
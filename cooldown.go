@@ -0,0 +1,36 @@
+package hourglass
+
+import (
+	"context"
+	"time"
+)
+
+// WithCooldownOnExhaustion blocks a user for d once Consume has denied them,
+// even if their counter is credited back in the meantime. Credit does not end
+// the cooldown.
+func WithCooldownOnExhaustion(d time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.cooldown = d
+	}
+}
+
+// cooldownKey returns the key that marks key as cooling down.
+func cooldownKey(key string) string {
+	return key + ":cooldown"
+}
+
+// cooldownRemaining returns how long key is still cooling down, or zero when
+// it is not.
+func (hg *HourGlass) cooldownRemaining(ctx context.Context, key string) (time.Duration, error) {
+	remaining, err := hg.redisClient.PTTL(ctx, cooldownKey(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// PTTL reports a missing key as -2 and a key without expiry as -1.
+	return max(remaining, 0), nil
+}
+
+func (hg *HourGlass) startCooldown(ctx context.Context, key string) error {
+	return hg.redisClient.Set(ctx, cooldownKey(key), 1, hg.cooldown).Err()
+}
@@ -0,0 +1,58 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCooldownOnExhaustion(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+	}, WithCooldownOnExhaustion(time.Hour))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := dailyKey("feature1", "cooldown-user")
+	h.redisClient.Del(ctx, key, cooldownKey(key))
+
+	result, _ := h.Consume(ctx, "feature1", "cooldown-user")
+	require.True(t, result.Allowed)
+
+	t.Run("Hitting the limit should start the cooldown", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "cooldown-user")
+		require.False(t, result.Allowed)
+
+		ttl := h.redisClient.TTL(ctx, cooldownKey(key)).Val()
+		require.InDelta(t, time.Hour.Seconds(), ttl.Seconds(), 2)
+	})
+
+	t.Run("Credit should not end the cooldown", func(t *testing.T) {
+		current, _ := h.Credit(ctx, "feature1", "cooldown-user")
+		require.Equal(t, 0, current)
+
+		result, err := h.consume(ctx, "feature1", "cooldown-user")
+		require.ErrorIs(t, err, ErrCoolingDown)
+		require.False(t, result.Allowed)
+		require.WithinDuration(t, time.Now().Add(time.Hour), result.ResetsAt, 2*time.Second)
+
+		current, _ = h.Get(ctx, "feature1", "cooldown-user")
+		require.Equal(t, 0, current)
+	})
+
+	t.Run("Consume should work again once the cooldown has expired", func(t *testing.T) {
+		h.redisClient.Del(ctx, cooldownKey(key))
+
+		result, _ := h.Consume(ctx, "feature1", "cooldown-user")
+		require.True(t, result.Allowed)
+	})
+}
@@ -0,0 +1,83 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed copy.lua
+var copyScriptData string
+
+// CopyUsage moves the counters of fromUser to toUser for every configured
+// feature, for when accounts are merged or renamed. Counters toUser already
+// has for the same window are added to, clamped at the feature limit,
+// and take the expiry of fromUser's counter. fromUser's counters are deleted.
+// Each counter is moved atomically, but not all of them together: when an
+// error is returned some counters may have been moved already. Calling
+// CopyUsage again moves the rest.
+func (hg *HourGlass) CopyUsage(ctx context.Context, fromUser, toUser string) error {
+	prefix := hg.keyPrefix(ctx)
+	fromKeyUser := hg.keyUser(fromUser)
+	toKeyUser := hg.keyUser(toUser)
+	keys, err := hg.scanKeys(ctx, globReplacer.Replace(prefix)+"*:"+globReplacer.Replace(fromKeyUser)+":*")
+	if err != nil {
+		return err
+	}
+
+	type counterCopy struct {
+		keys  []string
+		limit int
+	}
+
+	var copies []counterCopy
+	for _, key := range keys {
+		featureName, windowID, ok := hg.parseCounterKey(strings.TrimPrefix(key, prefix), fromKeyUser)
+		if !ok {
+			continue
+		}
+		limit, exists := hg.limitProvider.Limit(featureName)
+		if !exists {
+			continue
+		}
+		copies = append(copies, counterCopy{
+			keys:  []string{key, prefix + featureName + ":" + toKeyUser + ":" + windowID},
+			limit: limit,
+		})
+	}
+	if len(copies) == 0 {
+		return nil
+	}
+
+	// The script reads, adds, clamps and deletes in one step, so consumes
+	// by toUser in between are not lost. A counter that was already moved
+	// is gone, so running the pipeline again with EVAL after a NOSCRIPT
+	// error does not move it twice.
+	run := func(eval func(pipe redis.Pipeliner, c counterCopy) *redis.Cmd) error {
+		cmds, err := hg.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, c := range copies {
+				eval(pipe, c)
+			}
+			return nil
+		})
+		for _, cmd := range cmds {
+			if cmd.Err() != nil {
+				return cmd.Err()
+			}
+		}
+		return err
+	}
+
+	err = run(func(pipe redis.Pipeliner, c counterCopy) *redis.Cmd {
+		return hg.copyScript.EvalSha(ctx, pipe, c.keys, c.limit)
+	})
+	if err != nil && redis.HasErrorPrefix(err, "NOSCRIPT") {
+		err = run(func(pipe redis.Pipeliner, c counterCopy) *redis.Cmd {
+			return hg.copyScript.Eval(ctx, pipe, c.keys, c.limit)
+		})
+	}
+
+	return err
+}
@@ -0,0 +1,22 @@
+local from_key = KEYS[1]
+local to_key = KEYS[2]
+local limit = tonumber(ARGV[1])
+
+local from = redis.call('GET', from_key)
+if from == false then
+    -- Expired since the scan.
+    return -1
+end
+
+local to = tonumber(redis.call('GET', to_key) or '0')
+local total = math.min(tonumber(from) + to, limit)
+
+local ttl = redis.call('PTTL', from_key)
+if ttl > 0 then
+    redis.call('SET', to_key, total, 'PX', ttl)
+else
+    redis.call('SET', to_key, total)
+end
+redis.call('DEL', from_key)
+
+return total
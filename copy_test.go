@@ -0,0 +1,95 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyUsage(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"copy1": 5,
+			"copy2": 3,
+		},
+		Features: map[string]FeatureConfig{
+			"copy3": {Limit: 10, Window: time.Hour},
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Set(ctx, "copy1:copy-old:2026-01-01", 2, time.Hour)
+	h.redisClient.Set(ctx, "copy1:copy-old:2026-01-02", 4, 2*time.Hour)
+	h.redisClient.Set(ctx, "copy2:copy-old:2026-01-02", 1, time.Hour)
+	h.redisClient.Set(ctx, "copy1:copy-new:2026-01-02", 3, time.Minute)
+	h.redisClient.Set(ctx, "copy3:copy-old:2026-01-02T140000", 6, time.Hour)
+	h.redisClient.Del(ctx, "copy1:copy-new:2026-01-01", "copy2:copy-new:2026-01-02", "copy3:copy-new:2026-01-02T140000")
+
+	require.Nil(t, h.CopyUsage(ctx, "copy-old", "copy-new"))
+
+	tt := []struct {
+		description   string
+		key           string
+		expectedCount string
+		expectedTTL   time.Duration
+	}{
+		{
+			description:   "Counters should be copied with their expiry",
+			key:           "copy1:copy-new:2026-01-01",
+			expectedCount: "2",
+			expectedTTL:   time.Hour,
+		},
+		{
+			description:   "Existing counters should be added to and clamped at the limit",
+			key:           "copy1:copy-new:2026-01-02",
+			expectedCount: "5",
+			expectedTTL:   2 * time.Hour,
+		},
+		{
+			description:   "Counters of every feature should be copied",
+			key:           "copy2:copy-new:2026-01-02",
+			expectedCount: "1",
+			expectedTTL:   time.Hour,
+		},
+		{
+			description:   "Counters of features with other windows should be copied",
+			key:           "copy3:copy-new:2026-01-02T140000",
+			expectedCount: "6",
+			expectedTTL:   time.Hour,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expectedCount, h.redisClient.Get(ctx, tc.key).Val())
+			require.InDelta(t, tc.expectedTTL.Seconds(), h.redisClient.TTL(ctx, tc.key).Val().Seconds(), 2)
+		})
+	}
+
+	t.Run("The counters of the old user should be deleted", func(t *testing.T) {
+		keys, err := h.scanKeys(ctx, "copy*:copy-old:*")
+		require.Nil(t, err)
+		require.Empty(t, keys)
+	})
+
+	t.Run("A user without usage should copy nothing", func(t *testing.T) {
+		require.Nil(t, h.CopyUsage(ctx, "copy-nobody", "copy-new"))
+	})
+
+	t.Run("Counters should be copied after the script cache was flushed", func(t *testing.T) {
+		h.redisClient.Set(ctx, "copy2:copy-flushed:2026-01-03", 2, time.Hour)
+		h.redisClient.Del(ctx, "copy2:copy-new:2026-01-03")
+		require.Nil(t, h.redisClient.ScriptFlush(ctx).Err())
+
+		require.Nil(t, h.CopyUsage(ctx, "copy-flushed", "copy-new"))
+		require.Equal(t, "2", h.redisClient.Get(ctx, "copy2:copy-new:2026-01-03").Val())
+	})
+}
@@ -0,0 +1,255 @@
+//go:build crdt
+
+package hourglass
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	crdtGossipPath     = "/hourglass/crdt"
+	crdtGossipInterval = time.Second
+)
+
+// WithCRDTBackend is an experimental replacement for Redis in geographically
+// distributed deployments. Every node keeps a grow-only counter (G-Counter)
+// per feature, user and window with one slot per node, and pushes its state
+// to the other nodes over HTTP every second. Consume checks the sum of all
+// slots against the limit and increments only this node's slot, so nodes that
+// have not heard from each other yet can let through up to one limit each.
+//
+// nodes[0] is the address this node listens on for gossip, e.g.
+// "10.0.0.1:7946"; the rest are its peers. Only Consume and Get use the
+// backend; burst allowances, cooldowns and the other Redis features do not
+// apply. Combine it with WithLazyConnect to run without Redis. It is only
+// available when building with the crdt tag.
+func WithCRDTBackend(nodes []string) Option {
+	return func(hg *HourGlass) {
+		if len(nodes) == 0 {
+			return
+		}
+		hg.backend = &crdtBackend{
+			self:     nodes[0],
+			peers:    nodes[1:],
+			interval: crdtGossipInterval,
+			counters: map[string]*gCounter{},
+			client:   &http.Client{Timeout: crdtGossipInterval},
+		}
+	}
+}
+
+// gCounter is a grow-only counter with one slot per node.
+type gCounter struct {
+	ExpiresAt time.Time         `json:"expiresAt"`
+	Counts    map[string]uint64 `json:"counts"`
+}
+
+func (c *gCounter) value() int {
+	var sum uint64
+	for _, count := range c.Counts {
+		sum += count
+	}
+
+	return int(sum)
+}
+
+// merge takes the larger count of every slot, which makes merging
+// commutative, associative and idempotent.
+func (c *gCounter) merge(other *gCounter) {
+	for node, count := range other.Counts {
+		c.Counts[node] = max(c.Counts[node], count)
+	}
+	if other.ExpiresAt.After(c.ExpiresAt) {
+		c.ExpiresAt = other.ExpiresAt
+	}
+}
+
+type crdtBackend struct {
+	self     string
+	peers    []string
+	interval time.Duration
+	client   *http.Client
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	counters map[string]*gCounter
+
+	server    *http.Server
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+func (b *crdtBackend) start(logger *slog.Logger) error {
+	b.logger = logger
+
+	listener, err := net.Listen("tcp", b.self)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+crdtGossipPath, b.receive)
+	b.server = &http.Server{Handler: mux, ReadHeaderTimeout: b.interval}
+	b.done = make(chan struct{})
+
+	b.wg.Add(2)
+	go func() {
+		defer b.wg.Done()
+		if err := b.server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			b.logger.Error("crdt gossip server stopped", "error", err)
+		}
+	}()
+	go func() {
+		defer b.wg.Done()
+		b.gossip()
+	}()
+
+	return nil
+}
+
+func (b *crdtBackend) consume(key string, limit int, expiresAt time.Time) (int, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	counter := b.counter(key, expiresAt)
+	current := counter.value()
+	if current+1 > limit {
+		return current, false
+	}
+	counter.Counts[b.self]++
+
+	return current + 1, true
+}
+
+func (b *crdtBackend) get(key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	counter, exists := b.counters[key]
+	if !exists || time.Now().After(counter.ExpiresAt) {
+		return 0
+	}
+
+	return counter.value()
+}
+
+// counter returns the live counter for key, starting a new one when there is
+// none or the old one expired. b.mu must be held.
+func (b *crdtBackend) counter(key string, expiresAt time.Time) *gCounter {
+	counter, exists := b.counters[key]
+	if !exists || time.Now().After(counter.ExpiresAt) {
+		counter = &gCounter{ExpiresAt: expiresAt, Counts: map[string]uint64{}}
+		b.counters[key] = counter
+	}
+
+	return counter
+}
+
+// snapshot drops expired counters and returns a copy of the others to send
+// to peers.
+func (b *crdtBackend) snapshot() map[string]*gCounter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	state := make(map[string]*gCounter, len(b.counters))
+	for key, counter := range b.counters {
+		if now.After(counter.ExpiresAt) {
+			delete(b.counters, key)
+			continue
+		}
+		counts := make(map[string]uint64, len(counter.Counts))
+		for node, count := range counter.Counts {
+			counts[node] = count
+		}
+		state[key] = &gCounter{ExpiresAt: counter.ExpiresAt, Counts: counts}
+	}
+
+	return state
+}
+
+func (b *crdtBackend) merge(state map[string]*gCounter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for key, remote := range state {
+		if remote == nil || now.After(remote.ExpiresAt) {
+			continue
+		}
+		b.counter(key, remote.ExpiresAt).merge(remote)
+	}
+}
+
+func (b *crdtBackend) receive(w http.ResponseWriter, r *http.Request) {
+	var state map[string]*gCounter
+	if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b.merge(state)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// gossip pushes the full state to every peer each interval until close.
+func (b *crdtBackend) gossip() {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+		}
+
+		payload, err := json.Marshal(b.snapshot())
+		if err != nil {
+			b.logger.Warn("failed to encode crdt state", "error", err)
+			continue
+		}
+		for _, peer := range b.peers {
+			if err := b.push(peer, payload); err != nil {
+				b.logger.Debug("failed to gossip crdt state", "peer", peer, "error", err)
+			}
+		}
+	}
+}
+
+func (b *crdtBackend) push(peer string, payload []byte) error {
+	resp, err := b.client.Post("http://"+peer+crdtGossipPath, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return errors.New("crdt gossip returned " + resp.Status)
+	}
+
+	return nil
+}
+
+func (b *crdtBackend) close() error {
+	if b.server == nil {
+		return nil
+	}
+
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.done)
+		err = b.server.Shutdown(context.Background())
+		b.wg.Wait()
+	})
+
+	return err
+}
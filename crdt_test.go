@@ -0,0 +1,102 @@
+//go:build crdt
+
+package hourglass
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCounterMerge(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	tt := []struct {
+		description   string
+		local         map[string]uint64
+		remote        map[string]uint64
+		expectedValue int
+	}{
+		{
+			description:   "Slots of different nodes should be summed",
+			local:         map[string]uint64{"a": 2},
+			remote:        map[string]uint64{"b": 3},
+			expectedValue: 5,
+		},
+		{
+			description:   "The larger count of a slot should win",
+			local:         map[string]uint64{"a": 2, "b": 4},
+			remote:        map[string]uint64{"a": 1, "b": 5},
+			expectedValue: 7,
+		},
+		{
+			description:   "Merging the same state twice should not change it",
+			local:         map[string]uint64{"a": 2, "b": 3},
+			remote:        map[string]uint64{"a": 2, "b": 3},
+			expectedValue: 5,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			counter := &gCounter{ExpiresAt: expiresAt, Counts: test.local}
+			counter.merge(&gCounter{ExpiresAt: expiresAt, Counts: test.remote})
+			require.Equal(t, test.expectedValue, counter.value())
+		})
+	}
+}
+
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+func TestCRDTBackend(t *testing.T) {
+	ctx := context.Background()
+	a, b := freeAddress(t), freeAddress(t)
+
+	newNode := func(nodes []string) *HourGlass {
+		h, err := New(&Config{
+			// Nothing listens here; the backend replaces Redis.
+			RedisAddress: "127.0.0.1:1",
+			Limits:       map[string]int{"crdt": 3},
+		}, WithLazyConnect(), WithCRDTBackend(nodes))
+		require.NoError(t, err)
+		return h
+	}
+	nodeA := newNode([]string{a, b})
+	defer nodeA.Close()
+	nodeB := newNode([]string{b, a})
+	defer nodeB.Close()
+
+	for range 2 {
+		result, err := nodeA.Consume(ctx, "crdt", "alice")
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	}
+
+	require.Eventually(t, func() bool {
+		current, _ := nodeB.Get(ctx, "crdt", "alice")
+		return current == 2
+	}, 3*crdtGossipInterval, 10*time.Millisecond)
+
+	result, err := nodeB.Consume(ctx, "crdt", "alice")
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 3, result.Current)
+
+	result, err = nodeB.Consume(ctx, "crdt", "alice")
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+
+	require.Eventually(t, func() bool {
+		current, _ := nodeA.Get(ctx, "crdt", "alice")
+		return current == 3
+	}, 3*crdtGossipInterval, 10*time.Millisecond)
+}
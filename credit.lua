@@ -0,0 +1,24 @@
+local key = KEYS[1]
+local burst_key = KEYS[2]
+local limit = tonumber(ARGV[1])
+local amount = tonumber(ARGV[2]) or 1
+
+-- Counters never go below zero, and a missing counter is not created.
+local current = tonumber(redis.call('GET', key) or '0')
+local credited = math.min(amount, current)
+if credited <= 0 then
+    return current
+end
+
+local new_value = redis.call('DECRBY', key, credited)
+
+-- Crediting back calls made on the burst allowance makes them available again.
+local over = math.min(credited, current - limit)
+if over > 0 then
+    local used = tonumber(redis.call('GET', burst_key) or '0')
+    if used > 0 then
+        redis.call('DECRBY', burst_key, math.min(over, used))
+    end
+end
+
+return new_value
@@ -0,0 +1,68 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DebugInfo is a snapshot of everything that decides whether a user can
+// consume a feature. KeyTTL is negative when the counter does not exist (-2)
+// or has no expiry (-1), following Redis.
+type DebugInfo struct {
+	CurrentKey     string        `json:"currentKey"`
+	CurrentValue   int           `json:"currentValue"`
+	KeyTTL         time.Duration `json:"keyTTL"`
+	EffectiveLimit int           `json:"effectiveLimit"`
+	ResetsAt       time.Time     `json:"resetsAt"`
+	IsWhitelisted  bool          `json:"isWhitelisted"`
+	IsBlacklisted  bool          `json:"isBlacklisted"`
+	CooldownActive bool          `json:"cooldownActive"`
+}
+
+// DebugInfo collects the state of userName's counter for featureName in one
+// round trip, for support teams looking into unexpected denials. The counter
+// is read from the primary, not the read replica.
+func (hg *HourGlass) DebugInfo(ctx context.Context, featureName, userName string) (DebugInfo, error) {
+	key, limit, exists := hg.lookup(ctx, featureName, userName)
+	if !exists {
+		return DebugInfo{}, ErrUnknownFeature
+	}
+
+	info := DebugInfo{
+		CurrentKey:     key,
+		EffectiveLimit: limit,
+		ResetsAt:       hg.userWindowEnd(ctx, featureName, userName),
+		IsWhitelisted:  hg.whitelist.contains(userName),
+		IsBlacklisted:  hg.blacklist.contains(userName),
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		return info, err
+	}
+
+	var value *redis.StringCmd
+	var ttl, cooldown *redis.DurationCmd
+	_, err := hg.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		value = pipe.Get(ctx, key)
+		ttl = pipe.PTTL(ctx, key)
+		cooldown = pipe.PTTL(ctx, cooldownKey(key))
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return info, err
+	}
+
+	if raw, err := value.Result(); err == nil {
+		info.CurrentValue, _, err = hg.serializer().Decode(raw)
+		if err != nil {
+			return info, err
+		}
+	}
+	info.KeyTTL = ttl.Val()
+	info.CooldownActive = cooldown.Val() > 0
+
+	return info, nil
+}
@@ -0,0 +1,63 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugInfo(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+		Whitelist: []string{"debug-probe"},
+	}, WithCooldownOnExhaustion(time.Minute))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := dailyKey("feature1", "debug-user")
+	h.redisClient.Del(ctx, key, cooldownKey(key))
+
+	t.Run("A user without a counter should report an empty state", func(t *testing.T) {
+		info, err := h.DebugInfo(ctx, "feature1", "debug-user")
+		require.Nil(t, err)
+		require.Equal(t, key, info.CurrentKey)
+		require.Equal(t, 0, info.CurrentValue)
+		require.Equal(t, time.Duration(-2), info.KeyTTL)
+		require.Equal(t, 1, info.EffectiveLimit)
+		require.Equal(t, endOfDay(), info.ResetsAt)
+		require.False(t, info.CooldownActive)
+	})
+
+	t.Run("An exhausted user should report the counter and cooldown", func(t *testing.T) {
+		h.Consume(ctx, "feature1", "debug-user")
+		h.Consume(ctx, "feature1", "debug-user")
+
+		info, err := h.DebugInfo(ctx, "feature1", "debug-user")
+		require.Nil(t, err)
+		require.Equal(t, 1, info.CurrentValue)
+		require.Greater(t, info.KeyTTL, time.Duration(0))
+		require.True(t, info.CooldownActive)
+		require.False(t, info.IsWhitelisted)
+		require.False(t, info.IsBlacklisted)
+	})
+
+	t.Run("Whitelisted users should be flagged", func(t *testing.T) {
+		info, err := h.DebugInfo(ctx, "feature1", "debug-probe")
+		require.Nil(t, err)
+		require.True(t, info.IsWhitelisted)
+	})
+
+	t.Run("Unknown features should fail", func(t *testing.T) {
+		_, err := h.DebugInfo(ctx, "feature-notexistent", "debug-user")
+		require.ErrorIs(t, err, ErrUnknownFeature)
+	})
+}
@@ -0,0 +1,32 @@
+package hourglass
+
+import (
+	"context"
+	"net/url"
+)
+
+// dimensionsKey returns the canonical form of dimensions used in place of a
+// user name: the pairs sorted by name and query escaped, e.g.
+// region=us-east-1&user=alice.
+func dimensionsKey(dimensions map[string]string) string {
+	values := make(url.Values, len(dimensions))
+	for name, value := range dimensions {
+		values.Set(name, value)
+	}
+
+	return values.Encode()
+}
+
+// ConsumeWithDimensions consumes one unit of featureName for a combination of
+// dimensions such as user, region or endpoint instead of a single user. Each
+// combination has its own counter, keyed by the canonical form of
+// dimensions, so the order of the map does not matter. Whitelists,
+// blacklists, priorities and the other per user settings apply to that
+// canonical form, not to the individual values.
+func (hg *HourGlass) ConsumeWithDimensions(ctx context.Context, featureName string, dimensions map[string]string) (ConsumeResult, error) {
+	if len(dimensions) == 0 {
+		return ConsumeResult{Current: -1, Limit: -1, Allowed: false}, ErrNoDimensions
+	}
+
+	return hg.consume(ctx, featureName, dimensionsKey(dimensions))
+}
@@ -0,0 +1,88 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDimensionsKey(t *testing.T) {
+	tt := []struct {
+		description string
+		dimensions  map[string]string
+		expected    string
+	}{
+		{
+			description: "Dimensions should be sorted by name",
+			dimensions:  map[string]string{"user": "alice", "region": "us-east-1"},
+			expected:    "region=us-east-1&user=alice",
+		},
+		{
+			description: "Separators in values should be escaped",
+			dimensions:  map[string]string{"endpoint": "/a&b=c:d"},
+			expected:    "endpoint=%2Fa%26b%3Dc%3Ad",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expected, dimensionsKey(tc.dimensions))
+		})
+	}
+}
+
+func TestConsumeWithDimensions(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx,
+		dailyKey("feature1", "region=us-east-1&user=alice"),
+		dailyKey("feature1", "region=eu-west-1&user=alice"),
+	)
+
+	tt := []struct {
+		description     string
+		dimensions      map[string]string
+		expectedAllowed bool
+		expectedErr     error
+	}{
+		{
+			description:     "The first consume of a combination should be allowed",
+			dimensions:      map[string]string{"user": "alice", "region": "us-east-1"},
+			expectedAllowed: true,
+		},
+		{
+			description:     "A consume of the same combination should share its counter",
+			dimensions:      map[string]string{"region": "us-east-1", "user": "alice"},
+			expectedAllowed: false,
+		},
+		{
+			description:     "A consume of another combination should have its own counter",
+			dimensions:      map[string]string{"user": "alice", "region": "eu-west-1"},
+			expectedAllowed: true,
+		},
+		{
+			description: "A consume without dimensions should fail",
+			expectedErr: ErrNoDimensions,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := h.ConsumeWithDimensions(ctx, "feature1", tc.dimensions)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedAllowed, result.Allowed)
+		})
+	}
+}
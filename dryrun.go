@@ -0,0 +1,44 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DryRunConsume reports whether consuming amount units of featureName for
+// userName would be allowed, without changing anything. It reads the counter
+// with a single GET and checks current + amount <= limit locally, so Current
+// and Remaining describe the state before the action. Only the configured
+// limit is checked: burst allowances, banked units, groups, cooldowns and
+// increments still pending in the local buffer are not taken into account.
+func (hg *HourGlass) DryRunConsume(ctx context.Context, featureName, userName string, amount int) (ConsumeResult, error) {
+	if amount <= 0 {
+		return ConsumeResult{Current: -1, Limit: -1}, ErrInvalidAmount
+	}
+	_, limit, exists := hg.lookupLimit(featureName, userName)
+	if !exists {
+		return ConsumeResult{Current: -1, Limit: -1, Allowed: true}, nil
+	}
+	if hg.blacklist.contains(userName) {
+		return ConsumeResult{Current: -1, Limit: limit}, ErrUserBlacklisted
+	}
+
+	ctx = hg.withUserLocation(ctx, userName)
+	current, _, err := hg.get(ctx, featureName, userName)
+	if errors.Is(err, redis.Nil) {
+		current, err = 0, nil
+	}
+	if err != nil {
+		return ConsumeResult{Current: -1, Limit: limit}, err
+	}
+
+	return ConsumeResult{
+		Current:   current,
+		Limit:     limit,
+		Remaining: max(limit-current, 0),
+		Allowed:   current+amount <= limit || hg.whitelist.contains(userName),
+		ResetsAt:  hg.userWindowEnd(ctx, featureName, userName),
+	}, nil
+}
@@ -0,0 +1,96 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunConsume(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits:       map[string]int{"dryrun": 5},
+		KeyPrefix:    "dryrun-test:",
+		Blacklist:    []string{"mallory"},
+	})
+	require.NoError(t, err)
+	defer h.Close()
+
+	keys, _ := h.redisClient.Keys(ctx, "dryrun-test:*").Result()
+	if len(keys) > 0 {
+		h.redisClient.Del(ctx, keys...)
+	}
+	require.NoError(t, h.SetUsage(ctx, "dryrun", "alice", 3))
+
+	tt := []struct {
+		description     string
+		featureName     string
+		userName        string
+		amount          int
+		expectedCurrent int
+		expectedLimit   int
+		expectedAllowed bool
+		expectedErr     error
+	}{
+		{
+			description:     "An amount that fits should be allowed",
+			featureName:     "dryrun",
+			userName:        "alice",
+			amount:          2,
+			expectedCurrent: 3,
+			expectedLimit:   5,
+			expectedAllowed: true,
+		},
+		{
+			description:     "An amount that does not fit should be denied",
+			featureName:     "dryrun",
+			userName:        "alice",
+			amount:          3,
+			expectedCurrent: 3,
+			expectedLimit:   5,
+		},
+		{
+			description:     "A user without a counter should start at zero",
+			featureName:     "dryrun",
+			userName:        "bob",
+			amount:          5,
+			expectedCurrent: 0,
+			expectedLimit:   5,
+			expectedAllowed: true,
+		},
+		{
+			description:     "A blacklisted user should be denied",
+			featureName:     "dryrun",
+			userName:        "mallory",
+			amount:          1,
+			expectedCurrent: -1,
+			expectedLimit:   5,
+			expectedErr:     ErrUserBlacklisted,
+		},
+		{
+			description:     "A non-positive amount should fail",
+			featureName:     "dryrun",
+			userName:        "alice",
+			amount:          0,
+			expectedCurrent: -1,
+			expectedLimit:   -1,
+			expectedErr:     ErrInvalidAmount,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			result, err := h.DryRunConsume(ctx, test.featureName, test.userName, test.amount)
+			require.ErrorIs(t, err, test.expectedErr)
+			require.Equal(t, test.expectedCurrent, result.Current)
+			require.Equal(t, test.expectedLimit, result.Limit)
+			require.Equal(t, test.expectedAllowed, result.Allowed)
+		})
+	}
+
+	current, _ := h.Get(ctx, "dryrun", "alice")
+	require.Equal(t, 3, current)
+}
@@ -0,0 +1,66 @@
+package hourglass
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ConfigFromEnv builds a Config from HOURGLASS_* environment variables:
+// HOURGLASS_REDIS_ADDRESS, HOURGLASS_REDIS_PASSWORD, HOURGLASS_KEY_PREFIX,
+// HOURGLASS_ENVIRONMENT and the limits. Limits are read from HOURGLASS_LIMITS
+// as a JSON object or from HOURGLASS_LIMITS_SIMPLE as feature=limit pairs
+// separated by commas; the JSON form wins when both are set.
+func ConfigFromEnv() (*Config, error) {
+	config := &Config{
+		RedisAddress:  os.Getenv("HOURGLASS_REDIS_ADDRESS"),
+		RedisPassword: os.Getenv("HOURGLASS_REDIS_PASSWORD"),
+		KeyPrefix:     os.Getenv("HOURGLASS_KEY_PREFIX"),
+		Environment:   os.Getenv("HOURGLASS_ENVIRONMENT"),
+	}
+
+	if raw, ok := os.LookupEnv("HOURGLASS_LIMITS"); ok {
+		if err := json.Unmarshal([]byte(raw), &config.Limits); err != nil {
+			return nil, fmt.Errorf("%w: HOURGLASS_LIMITS: %v", ErrInvalidEnvLimits, err)
+		}
+		return config, nil
+	}
+
+	if raw, ok := os.LookupEnv("HOURGLASS_LIMITS_SIMPLE"); ok {
+		limits, err := parseSimpleLimits(raw)
+		if err != nil {
+			return nil, err
+		}
+		config.Limits = limits
+	}
+
+	return config, nil
+}
+
+// parseSimpleLimits parses "feature1=10,feature2=5". Every limit must be a
+// positive integer.
+func parseSimpleLimits(raw string) (map[string]int, error) {
+	limits := map[string]int{}
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		featureName, value, found := strings.Cut(pair, "=")
+		featureName = strings.TrimSpace(featureName)
+		if !found || featureName == "" {
+			return nil, fmt.Errorf("%w: HOURGLASS_LIMITS_SIMPLE: %q is not feature=limit", ErrInvalidEnvLimits, pair)
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("%w: HOURGLASS_LIMITS_SIMPLE: limit for %q must be a positive integer", ErrInvalidEnvLimits, featureName)
+		}
+		limits[featureName] = limit
+	}
+
+	return limits, nil
+}
@@ -0,0 +1,74 @@
+package hourglass
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	tt := []struct {
+		description    string
+		env            map[string]string
+		expectedLimits map[string]int
+		expectedErr    error
+	}{
+		{
+			description:    "Parses the simple format",
+			env:            map[string]string{"HOURGLASS_LIMITS_SIMPLE": "feature1=10, feature2=5"},
+			expectedLimits: map[string]int{"feature1": 10, "feature2": 5},
+		},
+		{
+			description:    "Parses the JSON format",
+			env:            map[string]string{"HOURGLASS_LIMITS": `{"feature1": 3}`},
+			expectedLimits: map[string]int{"feature1": 3},
+		},
+		{
+			description: "Prefers JSON when both are set",
+			env: map[string]string{
+				"HOURGLASS_LIMITS":        `{"feature1": 3}`,
+				"HOURGLASS_LIMITS_SIMPLE": "feature2=5",
+			},
+			expectedLimits: map[string]int{"feature1": 3},
+		},
+		{
+			description: "Rejects a non-positive limit",
+			env:         map[string]string{"HOURGLASS_LIMITS_SIMPLE": "feature1=0"},
+			expectedErr: ErrInvalidEnvLimits,
+		},
+		{
+			description: "Rejects a non-integer limit",
+			env:         map[string]string{"HOURGLASS_LIMITS_SIMPLE": "feature1=ten"},
+			expectedErr: ErrInvalidEnvLimits,
+		},
+		{
+			description: "Rejects a pair without a limit",
+			env:         map[string]string{"HOURGLASS_LIMITS_SIMPLE": "feature1"},
+			expectedErr: ErrInvalidEnvLimits,
+		},
+		{
+			description: "Rejects malformed JSON",
+			env:         map[string]string{"HOURGLASS_LIMITS": "feature1=10"},
+			expectedErr: ErrInvalidEnvLimits,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			t.Setenv("HOURGLASS_REDIS_ADDRESS", "localhost:6379")
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+
+			config, err := ConfigFromEnv()
+			if test.expectedErr != nil {
+				require.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "localhost:6379", config.RedisAddress)
+			require.Equal(t, test.expectedLimits, config.Limits)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package hourglass
+
+import "errors"
+
+var (
+	ErrUnknownFeature          = errors.New("hourglass: unknown feature")
+	ErrInvalidAmount           = errors.New("hourglass: amount must be positive")
+	ErrInsufficientCredit      = errors.New("hourglass: insufficient credit to transfer")
+	ErrLimitExceeded           = errors.New("hourglass: limit exceeded")
+	ErrEmptyConsumeScript      = errors.New("hourglass: consume script must not be empty")
+	ErrUserBlacklisted         = errors.New("hourglass: user is blacklisted")
+	ErrAlreadySubscribed       = errors.New("hourglass: already subscribed to feature")
+	ErrNotConnected            = errors.New("hourglass: redis is not connected")
+	ErrNoFeatureContext        = errors.New("hourglass: context has no feature and user")
+	ErrBurstLimitExceeded      = errors.New("hourglass: per minute burst limit exceeded")
+	ErrUnknownEnvironment      = errors.New("hourglass: unknown environment")
+	ErrCoolingDown             = errors.New("hourglass: user is cooling down after exhausting the limit")
+	ErrTimeSeriesRequired      = errors.New("hourglass: spike detector requires WithTimeSeries")
+	ErrCircuitOpen             = errors.New("hourglass: circuit breaker is open")
+	ErrInvalidLimitExpr        = errors.New("hourglass: invalid limit expression")
+	ErrInvalidTTL              = errors.New("hourglass: ttl must be positive")
+	ErrNoPoolMembers           = errors.New("hourglass: pool must have at least one member")
+	ErrPoolNotFound            = errors.New("hourglass: pool not found")
+	ErrNotPoolMember           = errors.New("hourglass: user is not a member of the pool")
+	ErrClusterUnavailable      = errors.New("hourglass: redis cluster is unavailable")
+	ErrEmptyKeySecret          = errors.New("hourglass: key hashing secret must not be empty")
+	ErrNoRollingAverage        = errors.New("hourglass: feature has no rolling average")
+	ErrInvalidUsername         = errors.New("hourglass: user name does not match the configured pattern")
+	ErrUnknownKey              = errors.New("hourglass: key is not a known compressed key")
+	ErrNoDimensions            = errors.New("hourglass: at least one dimension is required")
+	ErrUnexpectedRedisResponse = errors.New("hourglass: unexpected response from redis")
+	ErrInvalidScriptResponse   = errors.New("hourglass: script response hook returned fewer than three elements")
+	ErrInsufficientQuota       = errors.New("hourglass: not enough remaining quota to lend")
+	ErrLendingDisabled         = errors.New("hourglass: quota lending is not enabled")
+	ErrLimitsReadOnly          = errors.New("hourglass: limits are managed by the limit provider")
+	ErrEmptyIdempotencyKey     = errors.New("hourglass: idempotency key must not be empty")
+	ErrInvalidEnvLimits        = errors.New("hourglass: invalid limits in environment")
+	ErrPausingDisabled         = errors.New("hourglass: pausing is not enabled")
+	ErrInvalidInterval         = errors.New("hourglass: interval must be positive")
+)
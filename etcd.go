@@ -0,0 +1,106 @@
+package hourglass
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// WithEtcdLimitProvider loads limits from etcd keys {keyPrefix}/{feature} and
+// watches the prefix so that changes are applied as soon as they are written.
+func WithEtcdLimitProvider(client *clientv3.Client, keyPrefix string) Option {
+	return func(hg *HourGlass) {
+		hg.limitProvider = &etcdLimitProvider{
+			client:    client,
+			keyPrefix: strings.TrimSuffix(keyPrefix, "/") + "/",
+		}
+	}
+}
+
+type etcdLimitProvider struct {
+	client    *clientv3.Client
+	keyPrefix string
+	limits    sync.Map
+	logger    *slog.Logger
+	cancel    context.CancelFunc
+}
+
+func (p *etcdLimitProvider) start(ctx context.Context, logger *slog.Logger) error {
+	p.logger = logger
+
+	resp, err := p.client.Get(ctx, p.keyPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+	for _, kv := range resp.Kvs {
+		p.apply(ctx, false, string(kv.Key), kv.Value)
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	watch := p.client.Watch(watchCtx, p.keyPrefix, clientv3.WithPrefix(), clientv3.WithRev(resp.Header.Revision+1))
+
+	go func() {
+		for update := range watch {
+			if err := update.Err(); err != nil {
+				p.logger.WarnContext(watchCtx, "etcd limit watch failed", "prefix", p.keyPrefix, "error", err)
+				continue
+			}
+			for _, event := range update.Events {
+				p.apply(watchCtx, event.Type == clientv3.EventTypeDelete, string(event.Kv.Key), event.Kv.Value)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (p *etcdLimitProvider) apply(ctx context.Context, deleted bool, key string, value []byte) {
+	featureName := strings.TrimPrefix(key, p.keyPrefix)
+	if featureName == "" || strings.Contains(featureName, "/") {
+		return
+	}
+
+	if deleted {
+		p.limits.Delete(featureName)
+		return
+	}
+
+	limit, err := strconv.Atoi(strings.TrimSpace(string(value)))
+	if err != nil {
+		p.logger.WarnContext(ctx, "ignoring invalid limit in etcd", "key", key, "error", err)
+		return
+	}
+	p.limits.Store(featureName, limit)
+}
+
+func (p *etcdLimitProvider) Limit(featureName string) (int, bool) {
+	limit, exists := p.limits.Load(featureName)
+	if !exists {
+		return 0, false
+	}
+
+	return limit.(int), true
+}
+
+func (p *etcdLimitProvider) Limits() map[string]int {
+	limits := map[string]int{}
+	p.limits.Range(func(key, value any) bool {
+		limits[key.(string)] = value.(int)
+		return true
+	})
+
+	return limits
+}
+
+func (p *etcdLimitProvider) Close() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package hourglass
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func startEmbeddedEtcd(t *testing.T) *clientv3.Client {
+	t.Helper()
+
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+
+	clientURL, _ := url.Parse("http://127.0.0.1:0")
+	peerURL, _ := url.Parse("http://127.0.0.1:0")
+	cfg.ListenClientUrls = []url.URL{*clientURL}
+	cfg.AdvertiseClientUrls = []url.URL{*clientURL}
+	cfg.ListenPeerUrls = []url.URL{*peerURL}
+	cfg.AdvertisePeerUrls = []url.URL{*peerURL}
+	cfg.InitialCluster = cfg.Name + "=" + peerURL.String()
+
+	server, err := embed.StartEtcd(cfg)
+	require.Nil(t, err)
+	t.Cleanup(server.Close)
+
+	select {
+	case <-server.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("embedded etcd did not become ready")
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{server.Clients[0].Addr().String()},
+		DialTimeout: 5 * time.Second,
+	})
+	require.Nil(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func TestEtcdLimitProvider(t *testing.T) {
+	ctx := context.Background()
+	client := startEmbeddedEtcd(t)
+
+	_, err := client.Put(ctx, "hourglass/limits/feature1", "2")
+	require.Nil(t, err)
+	_, err = client.Put(ctx, "hourglass/limits/invalid", "not-a-number")
+	require.Nil(t, err)
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+	}, WithEtcdLimitProvider(client, "hourglass/limits"))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	t.Run("Limits should be loaded from etcd when the instance is created", func(t *testing.T) {
+		require.Equal(t, map[string]int{"feature1": 2}, h.limitProvider.Limits())
+	})
+
+	t.Run("Limit updates should be applied from the watch", func(t *testing.T) {
+		_, err := client.Put(ctx, "hourglass/limits/feature1", "9")
+		require.Nil(t, err)
+
+		require.Eventually(t, func() bool {
+			limit, _ := h.limitProvider.Limit("feature1")
+			return limit == 9
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Deleted limits should be removed from the watch", func(t *testing.T) {
+		_, err := client.Delete(ctx, "hourglass/limits/feature1")
+		require.Nil(t, err)
+
+		require.Eventually(t, func() bool {
+			_, exists := h.limitProvider.Limit("feature1")
+			return !exists
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,155 @@
+package hourglass
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type LimitEvent struct {
+	Feature string    `json:"feature"`
+	User    string    `json:"user"`
+	Current int       `json:"current"`
+	Limit   int       `json:"limit"`
+	Time    time.Time `json:"time"`
+	// Metadata holds the pairs passed to Consume with WithMetadata.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+type subscriptions struct {
+	mu     sync.Mutex
+	active map[string]*subscription
+}
+
+// subscription is one SubscribeLimitExceeded call. done is closed when it
+// is stopped, so the goroutine that forwards its events ends even while it
+// waits for the receiver.
+type subscription struct {
+	pubsub    *redis.PubSub
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *subscription) close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.pubsub.Close()
+	})
+	return err
+}
+
+func eventsChannel(featureName string) string {
+	return "hourglass:events:" + featureName
+}
+
+func (hg *HourGlass) publishLimitExceeded(ctx context.Context, event LimitEvent) {
+	if hg.alertManager != nil {
+		hg.sendAlert(ctx, event)
+	}
+
+	payload, err := json.Marshal(event)
+	if err == nil {
+		err = hg.redisClient.Publish(ctx, eventsChannel(event.Feature), payload).Err()
+	}
+	if err != nil {
+		hg.logger.WarnContext(ctx, "failed to publish limit exceeded event", "feature", event.Feature, "user", event.User, "error", err)
+	}
+}
+
+// SubscribeLimitExceeded returns a channel that receives an event every time
+// Consume denies a user of featureName, on this or any other instance. The
+// channel is closed when ctx is done or UnsubscribeLimitExceeded is called.
+func (hg *HourGlass) SubscribeLimitExceeded(ctx context.Context, featureName string) (<-chan LimitEvent, error) {
+	hg.subscriptions.mu.Lock()
+	defer hg.subscriptions.mu.Unlock()
+
+	if _, exists := hg.subscriptions.active[featureName]; exists {
+		return nil, ErrAlreadySubscribed
+	}
+
+	pubsub := hg.redisClient.Subscribe(ctx, eventsChannel(featureName))
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+	sub := &subscription{pubsub: pubsub, done: make(chan struct{})}
+	hg.subscriptions.active[featureName] = sub
+
+	events := make(chan LimitEvent)
+	go func() {
+		defer close(events)
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				hg.unsubscribe(featureName, sub)
+				return
+			case <-sub.done:
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+
+				var event LimitEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					hg.logger.WarnContext(ctx, "ignoring malformed limit exceeded event", "feature", featureName, "error", err)
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					hg.unsubscribe(featureName, sub)
+					return
+				case <-sub.done:
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// UnsubscribeLimitExceeded stops the subscription for featureName and closes
+// its event channel.
+func (hg *HourGlass) UnsubscribeLimitExceeded(featureName string) error {
+	hg.subscriptions.mu.Lock()
+	defer hg.subscriptions.mu.Unlock()
+
+	sub, exists := hg.subscriptions.active[featureName]
+	if !exists {
+		return nil
+	}
+	delete(hg.subscriptions.active, featureName)
+
+	return sub.close()
+}
+
+// unsubscribe stops sub and removes it unless featureName has been
+// subscribed to again since.
+func (hg *HourGlass) unsubscribe(featureName string, sub *subscription) {
+	hg.subscriptions.mu.Lock()
+	defer hg.subscriptions.mu.Unlock()
+
+	if hg.subscriptions.active[featureName] == sub {
+		delete(hg.subscriptions.active, featureName)
+	}
+	sub.close()
+}
+
+func (hg *HourGlass) unsubscribeAll() {
+	hg.subscriptions.mu.Lock()
+	defer hg.subscriptions.mu.Unlock()
+
+	for featureName, sub := range hg.subscriptions.active {
+		sub.close()
+		delete(hg.subscriptions.active, featureName)
+	}
+}
@@ -0,0 +1,107 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeLimitExceeded(t *testing.T) {
+	limits := map[string]int{
+		"events-feature": 1,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Set(ctx, dailyKey("events-feature", "events-user"), 1, 1*time.Minute)
+
+	events, err := h.SubscribeLimitExceeded(ctx, "events-feature")
+	require.Nil(t, err)
+
+	t.Run("Subscribing twice to the same feature should fail", func(t *testing.T) {
+		_, err := h.SubscribeLimitExceeded(ctx, "events-feature")
+		require.Equal(t, ErrAlreadySubscribed, err)
+	})
+
+	t.Run("A denied consume should publish an event", func(t *testing.T) {
+		result, err := h.Consume(ctx, "events-feature", "events-user", WithMetadata("request_id", "req-1"))
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+
+		select {
+		case event := <-events:
+			require.Equal(t, "events-feature", event.Feature)
+			require.Equal(t, "events-user", event.User)
+			require.Equal(t, 1, event.Current)
+			require.Equal(t, 1, event.Limit)
+			require.Equal(t, map[string]string{"request_id": "req-1"}, event.Metadata)
+		case <-time.After(2 * time.Second):
+			t.Fatal("no limit exceeded event received")
+		}
+	})
+
+	t.Run("Unsubscribing should close the event channel", func(t *testing.T) {
+		require.Nil(t, h.UnsubscribeLimitExceeded("events-feature"))
+
+		select {
+		case _, ok := <-events:
+			require.False(t, ok)
+		case <-time.After(2 * time.Second):
+			t.Fatal("event channel was not closed")
+		}
+	})
+
+	t.Run("Unsubscribing while an event waits for the receiver should close the event channel", func(t *testing.T) {
+		events, err := h.SubscribeLimitExceeded(ctx, "events-feature")
+		require.Nil(t, err)
+
+		h.Consume(ctx, "events-feature", "events-user")
+		time.Sleep(100 * time.Millisecond)
+		require.Nil(t, h.UnsubscribeLimitExceeded("events-feature"))
+
+		closed := false
+		timeout := time.After(2 * time.Second)
+		for !closed {
+			select {
+			case _, ok := <-events:
+				closed = !ok
+			case <-timeout:
+				t.Fatal("event channel was not closed")
+			}
+		}
+	})
+
+	t.Run("Stopping an old subscription should not stop a newer one", func(t *testing.T) {
+		_, err := h.SubscribeLimitExceeded(ctx, "events-feature")
+		require.Nil(t, err)
+		old := h.subscriptions.active["events-feature"]
+		require.Nil(t, h.UnsubscribeLimitExceeded("events-feature"))
+
+		events, err := h.SubscribeLimitExceeded(ctx, "events-feature")
+		require.Nil(t, err)
+		defer h.UnsubscribeLimitExceeded("events-feature")
+
+		// What the old goroutine does when its context ends late.
+		h.unsubscribe("events-feature", old)
+
+		h.Consume(ctx, "events-feature", "events-user")
+		select {
+		case event, ok := <-events:
+			require.True(t, ok)
+			require.Equal(t, "events-user", event.User)
+		case <-time.After(2 * time.Second):
+			t.Fatal("no limit exceeded event received")
+		}
+	})
+}
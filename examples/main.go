@@ -37,13 +37,13 @@ func main() {
 	log.Printf("Current: %d, Limit: %d", current, limit)
 
 	// Increment value for a feature for a user
-	current, limit, isAllowed := hg.Consume(ctx, "lattice", "pj11993")
-	if !isAllowed {
+	result, _ := hg.Consume(ctx, "lattice", "pj11993")
+	if !result.Allowed {
 		// Throw error to user saying limit exceeded
 		log.Printf("Not allowed")
 		os.Exit(-1)
 	}
-	log.Printf("After increment Current: %d, Limit: %d", current, limit)
+	log.Printf("After increment Current: %d, Limit: %d", result.Current, result.Limit)
 
 	// Run logic - run lattice
 	log.Printf("Run logic")
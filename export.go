@@ -0,0 +1,22 @@
+package hourglass
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const redacted = "[REDACTED]"
+
+// ExportConfig writes the live configuration as JSON, with the current limits
+// and with sensitive fields redacted.
+func (hg *HourGlass) ExportConfig(w io.Writer) error {
+	config := hg.appConfig
+	config.Limits = hg.limitProvider.Limits()
+	if config.RedisPassword != "" {
+		config.RedisPassword = redacted
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(config)
+}
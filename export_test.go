@@ -0,0 +1,44 @@
+package hourglass
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportConfig(t *testing.T) {
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	t.Run("The live limits should be exported", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.Nil(t, h.ExportConfig(&buf))
+
+		var exported Config
+		require.Nil(t, json.Unmarshal(buf.Bytes(), &exported))
+		require.Equal(t, map[string]int{"feature1": 5}, exported.Limits)
+		require.Equal(t, "localhost:6379", exported.RedisAddress)
+		require.Equal(t, "", exported.RedisPassword)
+	})
+
+	t.Run("The redis password should be redacted", func(t *testing.T) {
+		h.appConfig.RedisPassword = "secret"
+
+		var buf bytes.Buffer
+		require.Nil(t, h.ExportConfig(&buf))
+		require.NotContains(t, buf.String(), "secret")
+
+		var exported Config
+		require.Nil(t, json.Unmarshal(buf.Bytes(), &exported))
+		require.Equal(t, "[REDACTED]", exported.RedisPassword)
+	})
+}
@@ -0,0 +1,32 @@
+package hourglass
+
+import "expvar"
+
+// expvars holds the variables published by RegisterExpvars.
+type expvars struct {
+	consumes      *expvar.Map
+	limitExceeded *expvar.Map
+	redisErrors   *expvar.Int
+}
+
+func (v *expvars) observeConsume(featureName string, result ConsumeResult, err error) {
+	v.consumes.Add(featureName, 1)
+	if isLimitDenial(result, err) {
+		v.limitExceeded.Add(featureName, 1)
+	}
+}
+
+// RegisterExpvars publishes counters of hg with expvar, so they show up at
+// /debug/vars without a metrics library: {namespace}.consume_total and
+// {namespace}.limit_exceeded_total count consumes and limit denials by
+// feature, {namespace}.redis_errors_total counts calls answered by the
+// failure mode because Redis failed. Like expvar.Publish it panics when a
+// namespace is registered twice.
+func RegisterExpvars(hg *HourGlass, namespace string) {
+	vars := &expvars{
+		consumes:      expvar.NewMap(namespace + ".consume_total"),
+		limitExceeded: expvar.NewMap(namespace + ".limit_exceeded_total"),
+		redisErrors:   expvar.NewInt(namespace + ".redis_errors_total"),
+	}
+	hg.expvars.Store(vars)
+}
@@ -0,0 +1,78 @@
+package hourglass
+
+import (
+	"context"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterExpvars(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	RegisterExpvars(h, "hourglass_test")
+	h.redisClient.Del(ctx, dailyKey("feature1", "expvar-user"))
+
+	h.Consume(ctx, "feature1", "expvar-user")
+	h.Consume(ctx, "feature1", "expvar-user")
+	h.Consume(ctx, "feature-notexistent", "expvar-user")
+
+	unreachable, err := New(&Config{
+		RedisAddress:  "localhost:6399",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+	}, WithLazyConnect())
+
+	require.Nil(t, err)
+	defer unreachable.Close()
+
+	RegisterExpvars(unreachable, "hourglass_test_unreachable")
+	unreachable.Consume(ctx, "feature1", "expvar-user")
+
+	tt := []struct {
+		description string
+		name        string
+		expected    string
+	}{
+		{
+			description: "Consumes of known features should be counted by feature",
+			name:        "hourglass_test.consume_total",
+			expected:    `{"feature1": 2}`,
+		},
+		{
+			description: "Limit denials should be counted by feature",
+			name:        "hourglass_test.limit_exceeded_total",
+			expected:    `{"feature1": 1}`,
+		},
+		{
+			description: "Redis errors should be counted",
+			name:        "hourglass_test_unreachable.redis_errors_total",
+			expected:    "1",
+		},
+		{
+			description: "Redis errors should not count as limit denials",
+			name:        "hourglass_test_unreachable.limit_exceeded_total",
+			expected:    "{}",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expected, expvar.Get(tc.name).String())
+		})
+	}
+}
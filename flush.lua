@@ -0,0 +1,17 @@
+local key = KEYS[1]
+local amount = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local ttl_ms = tonumber(ARGV[3])
+
+local current = tonumber(redis.call('GET', key) or '0')
+local granted = math.max(math.min(amount, limit - current), 0)
+if granted == 0 then
+    return {current, granted}
+end
+
+local new_value = redis.call('INCRBY', key, granted)
+if redis.call('PTTL', key) == -1 then
+    redis.call('PEXPIRE', key, ttl_ms)
+end
+
+return {new_value, granted}
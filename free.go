@@ -0,0 +1,55 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// freeKey returns the key that counts the free units used against key.
+func freeKey(key string) string {
+	return key + ":free"
+}
+
+// ConsumeIfAbove lets the first freeUnits calls of the day through without
+// touching the feature's counter and only starts consuming quota after that.
+// Free calls are counted separately under the counter key with a ":free"
+// suffix and report the unchanged counter in the result.
+func (hg *HourGlass) ConsumeIfAbove(ctx context.Context, featureName, userName string, freeUnits int) (ConsumeResult, error) {
+	key, limit, exists := hg.lookup(ctx, featureName, userName)
+	// consume rejects invalid user names before any key is written.
+	if !exists || freeUnits <= 0 || !hg.validUserName(userName) || hg.blacklist.contains(userName) || hg.whitelist.contains(userName) {
+		return hg.consume(ctx, featureName, userName)
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		return hg.failureResult(limit, err)
+	}
+
+	// flush.lua counts up to freeUnits. Unlike the consume script it cannot
+	// be replaced through WithConsumeScript.
+	reply, err := hg.flushScript.Run(ctx, hg.redisClient, []string{freeKey(key)}, 1, freeUnits, ttlMillis(hg.ttlFor(ctx, featureName, userName))).Int64Slice()
+	if err != nil {
+		return hg.failureResult(limit, err)
+	}
+	if granted := reply[1]; granted == 0 {
+		return hg.consume(ctx, featureName, userName)
+	}
+
+	current, _, err := hg.get(ctx, featureName, userName)
+	if errors.Is(err, redis.Nil) {
+		current, err = 0, nil
+	}
+	if err != nil {
+		return ConsumeResult{Current: -1, Limit: limit, Allowed: true}, err
+	}
+
+	return ConsumeResult{
+		Current:   current,
+		Limit:     limit,
+		Remaining: max(limit-current, 0),
+		Allowed:   true,
+		ResetsAt:  hg.userWindowEnd(ctx, featureName, userName),
+	}, nil
+}
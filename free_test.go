@@ -0,0 +1,90 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeIfAbove(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 2,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := dailyKey("feature1", "free-user")
+	h.redisClient.Del(ctx, key, freeKey(key))
+
+	t.Run("Calls within the free units should not consume quota", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			result, err := h.ConsumeIfAbove(ctx, "feature1", "free-user", 3)
+			require.Nil(t, err)
+			require.True(t, result.Allowed)
+			require.Equal(t, 0, result.Current)
+			require.Equal(t, 2, result.Remaining)
+		}
+	})
+
+	t.Run("Calls after the free units should consume quota", func(t *testing.T) {
+		for i := 1; i <= 2; i++ {
+			result, err := h.ConsumeIfAbove(ctx, "feature1", "free-user", 3)
+			require.Nil(t, err)
+			require.True(t, result.Allowed)
+			require.Equal(t, i, result.Current)
+		}
+
+		result, err := h.ConsumeIfAbove(ctx, "feature1", "free-user", 3)
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("Unknown features should behave like Consume", func(t *testing.T) {
+		result, err := h.ConsumeIfAbove(ctx, "feature-notexistent", "free-user", 3)
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, -1, result.Limit)
+	})
+
+	t.Run("Invalid user names should be rejected before counting free units", func(t *testing.T) {
+		h, err := New(&Config{
+			RedisAddress:    "localhost:6379",
+			Limits:          map[string]int{"feature1": 2},
+			UserNamePattern: DefaultUserNamePattern,
+		})
+		require.Nil(t, err)
+		defer h.Close()
+
+		key := dailyKey("feature1", "bad user")
+		h.redisClient.Del(ctx, freeKey(key))
+
+		_, err = h.ConsumeIfAbove(ctx, "feature1", "bad user", 3)
+		require.ErrorIs(t, err, ErrInvalidUsername)
+		require.Equal(t, int64(0), h.redisClient.Exists(ctx, freeKey(key)).Val())
+	})
+
+	t.Run("A custom consume script should not count free units", func(t *testing.T) {
+		h, err := New(&Config{
+			RedisAddress: "localhost:6379",
+			Limits:       map[string]int{"feature1": 2},
+		}, WithConsumeScript("return {0, tonumber(ARGV[1]), 0}"))
+		require.Nil(t, err)
+		defer h.Close()
+
+		key := dailyKey("feature1", "free-script-user")
+		h.redisClient.Del(ctx, key, freeKey(key))
+
+		result, err := h.ConsumeIfAbove(ctx, "feature1", "free-script-user", 3)
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, "1", h.redisClient.Get(ctx, freeKey(key)).Val())
+	})
+}
@@ -0,0 +1,60 @@
+package hourglass
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// groupsByFeature inverts Config.FeatureGroups into the sorted groups of each
+// member feature.
+func groupsByFeature(featureGroups map[string][]string) map[string][]string {
+	groups := map[string][]string{}
+	for groupName, members := range featureGroups {
+		for _, featureName := range members {
+			if !slices.Contains(groups[featureName], groupName) {
+				groups[featureName] = append(groups[featureName], groupName)
+			}
+		}
+	}
+	for _, names := range groups {
+		slices.Sort(names)
+	}
+
+	return groups
+}
+
+// consumeGrouped consumes the counter at key together with the counters of
+// groups in one run of batch.lua, so either all of them are incremented or
+// none. Groups without a limit are skipped. groupRemaining is the smallest
+// number of units left in any group.
+func (hg *HourGlass) consumeGrouped(ctx context.Context, userName, key string, limit int, ttl time.Duration, groups []string) (current int, allowed bool, groupRemaining int, err error) {
+	keys := []string{key}
+	limits := []int{limit}
+	args := []any{1, limit, ttlMillis(ttl)}
+	for _, groupName := range groups {
+		groupKey, groupLimit, exists := hg.lookup(ctx, groupName, userName)
+		if !exists {
+			continue
+		}
+		keys = append(keys, groupKey)
+		limits = append(limits, groupLimit)
+		args = append(args, 1, groupLimit, ttlMillis(hg.ttlFor(ctx, groupName, userName)))
+	}
+	args = append(args, false)
+
+	reply, err := hg.batchScript.Run(ctx, hg.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		return -1, false, -1, err
+	}
+
+	groupRemaining = -1
+	for i := 1; i < len(keys); i++ {
+		remaining := max(limits[i]-int(reply[1+i*2]), 0)
+		if groupRemaining < 0 || remaining < groupRemaining {
+			groupRemaining = remaining
+		}
+	}
+
+	return int(reply[1]), reply[0] == 1, groupRemaining, nil
+}
@@ -0,0 +1,95 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureGroups(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits: map[string]int{
+			"model-a":   3,
+			"model-b":   5,
+			"inference": 4,
+		},
+		FeatureGroups: map[string][]string{
+			"inference": {"model-a", "model-b"},
+		},
+		KeyPrefix: "groups-test:",
+	})
+	require.NoError(t, err)
+	defer h.Close()
+
+	keys, _ := h.redisClient.Keys(ctx, "groups-test:*").Result()
+	if len(keys) > 0 {
+		h.redisClient.Del(ctx, keys...)
+	}
+
+	tt := []struct {
+		description       string
+		featureName       string
+		expectedAllowed   bool
+		expectedCurrent   int
+		expectedRemaining int
+		expectedGroup     int
+	}{
+		{
+			description:       "Consuming a member should also count against the group",
+			featureName:       "model-a",
+			expectedAllowed:   true,
+			expectedCurrent:   1,
+			expectedRemaining: 2,
+			expectedGroup:     1,
+		},
+		{
+			description:       "Remaining should be capped by the group",
+			featureName:       "model-b",
+			expectedAllowed:   true,
+			expectedCurrent:   1,
+			expectedRemaining: 2,
+			expectedGroup:     2,
+		},
+		{
+			description:       "Another member should share the group",
+			featureName:       "model-b",
+			expectedAllowed:   true,
+			expectedCurrent:   2,
+			expectedRemaining: 1,
+			expectedGroup:     3,
+		},
+		{
+			description:       "The last unit of the group should be allowed",
+			featureName:       "model-a",
+			expectedAllowed:   true,
+			expectedCurrent:   2,
+			expectedRemaining: 0,
+			expectedGroup:     4,
+		},
+		{
+			description:       "An exhausted group should deny a member with quota left",
+			featureName:       "model-b",
+			expectedAllowed:   false,
+			expectedCurrent:   2,
+			expectedRemaining: 0,
+			expectedGroup:     4,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			result, err := h.Consume(ctx, test.featureName, "alice")
+			require.NoError(t, err)
+			require.Equal(t, test.expectedAllowed, result.Allowed)
+			require.Equal(t, test.expectedCurrent, result.Current)
+			require.Equal(t, test.expectedRemaining, result.Remaining)
+
+			group, _ := h.Get(ctx, "inference", "alice")
+			require.Equal(t, test.expectedGroup, group)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package hourglass
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type StreamDirection int
+
+const (
+	StreamSend StreamDirection = iota
+	StreamRecv
+)
+
+// NewStreamServerInterceptor consumes one unit of featureName for every
+// message sent on a server stream, or for every message received when
+// StreamRecv is passed in directions. Once quota is exhausted the stream is
+// aborted with codes.ResourceExhausted.
+func NewStreamServerInterceptor(hg *HourGlass, featureName string, identity func(ctx context.Context) string, directions ...StreamDirection) grpc.StreamServerInterceptor {
+	if len(directions) == 0 {
+		directions = []StreamDirection{StreamSend}
+	}
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		stream := &quotaServerStream{
+			ServerStream: ss,
+			hg:           hg,
+			featureName:  featureName,
+			userName:     identity(ss.Context()),
+		}
+		for _, direction := range directions {
+			switch direction {
+			case StreamSend:
+				stream.onSend = true
+			case StreamRecv:
+				stream.onRecv = true
+			}
+		}
+
+		err := handler(srv, stream)
+		if stream.exhausted != nil {
+			// Handlers often wrap stream errors, make sure the caller
+			// still sees why the stream was aborted.
+			return stream.exhausted
+		}
+
+		return err
+	}
+}
+
+type quotaServerStream struct {
+	grpc.ServerStream
+	hg          *HourGlass
+	featureName string
+	userName    string
+	onSend      bool
+	onRecv      bool
+	exhausted   error
+}
+
+func (s *quotaServerStream) SendMsg(m interface{}) error {
+	if s.onSend {
+		if err := s.consume(); err != nil {
+			return err
+		}
+	}
+
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *quotaServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if s.onRecv {
+		return s.consume()
+	}
+
+	return nil
+}
+
+func (s *quotaServerStream) consume() error {
+	result, _ := s.hg.Consume(s.Context(), s.featureName, s.userName)
+	if !result.Allowed {
+		s.exhausted = status.Errorf(codes.ResourceExhausted, "quota exhausted for %s: %d/%d", s.featureName, result.Current, result.Limit)
+		return s.exhausted
+	}
+
+	return nil
+}
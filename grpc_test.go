@@ -0,0 +1,76 @@
+package hourglass
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestStreamServerInterceptor(t *testing.T) {
+	limits := map[string]int{
+		"feature1": 2,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "stream-user"))
+
+	identity := func(ctx context.Context) string {
+		return "stream-user"
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.StreamInterceptor(NewStreamServerInterceptor(h, "feature1", identity)))
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.Nil(t, err)
+	defer conn.Close()
+
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.Nil(t, err)
+
+	t.Run("Messages within the quota should be delivered", func(t *testing.T) {
+		_, err := stream.Recv()
+		require.Nil(t, err)
+
+		healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		_, err = stream.Recv()
+		require.Nil(t, err)
+	})
+
+	t.Run("Once the quota is exhausted the stream should be aborted", func(t *testing.T) {
+		healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		_, err := stream.Recv()
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		current, _ := h.Get(ctx, "feature1", "stream-user")
+		require.Equal(t, 2, current)
+	})
+}
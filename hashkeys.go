@@ -0,0 +1,28 @@
+package hourglass
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// WithHashedKeys replaces user names in Redis keys with their hex encoded
+// HMAC-SHA256 under secret, so anyone who can list the keys cannot enumerate
+// users. Methods that read user names back from keys, such as ActiveUsers,
+// return the hashes instead. Changing the secret orphans existing counters.
+func WithHashedKeys(secret string) Option {
+	return func(hg *HourGlass) {
+		hg.keySecret = []byte(secret)
+	}
+}
+
+// keyUser returns how userName appears in Redis keys.
+func (hg *HourGlass) keyUser(userName string) string {
+	if hg.keySecret == nil {
+		return hg.hashTag(userName)
+	}
+
+	mac := hmac.New(sha256.New, hg.keySecret)
+	mac.Write([]byte(userName))
+	return hg.hashTag(hex.EncodeToString(mac.Sum(nil)))
+}
@@ -0,0 +1,65 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHashedKeys(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"hashed-feature": 5,
+		},
+	}, WithHashedKeys("secret"))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	hashedKey := h.KeyFor(ctx, "hashed-feature", "hashed-user", time.Now(), false)
+	rawKey := h.KeyFor(ctx, "hashed-feature", "hashed-user", time.Now(), true)
+	h.redisClient.Del(ctx, hashedKey, rawKey)
+
+	t.Run("Keys should hold the hashed user name", func(t *testing.T) {
+		require.Equal(t, dailyKey("hashed-feature", "hashed-user"), rawKey)
+		require.NotContains(t, hashedKey, "hashed-user")
+		require.Equal(t, "hashed-feature:"+h.keyUser("hashed-user")+":"+time.Now().UTC().Format("2006-01-02"), hashedKey)
+		require.Len(t, h.keyUser("hashed-user"), 64)
+
+		result, err := h.Consume(ctx, "hashed-feature", "hashed-user")
+		require.Nil(t, err)
+		require.Equal(t, 1, result.Current)
+
+		value, err := h.redisClient.Get(ctx, hashedKey).Int()
+		require.Nil(t, err)
+		require.Equal(t, 1, value)
+		require.Zero(t, h.redisClient.Exists(ctx, rawKey).Val())
+	})
+
+	t.Run("Reads should find the hashed counter", func(t *testing.T) {
+		current, _ := h.Get(ctx, "hashed-feature", "hashed-user")
+		require.Equal(t, 1, current)
+
+		history, err := h.UserFeatureHistory(ctx, "hashed-user")
+		require.Nil(t, err)
+		require.Equal(t, 1, history["hashed-feature"][0].Count)
+	})
+
+	t.Run("Active users should be reported as hashes", func(t *testing.T) {
+		users, err := h.ActiveUsers(ctx, "hashed-feature")
+		require.Nil(t, err)
+		require.Contains(t, users, h.keyUser("hashed-user"))
+		require.NotContains(t, users, "hashed-user")
+	})
+
+	t.Run("An empty secret should be rejected", func(t *testing.T) {
+		_, err := New(&Config{RedisAddress: "localhost:6379"}, WithHashedKeys(""))
+		require.ErrorIs(t, err, ErrEmptyKeySecret)
+	})
+}
@@ -0,0 +1,33 @@
+package hourglass
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitHeaders returns the X-RateLimit-Limit, X-RateLimit-Remaining and
+// X-RateLimit-Reset headers for result, plus Retry-After when the consume was
+// denied. The limit headers are left out for unknown features and the reset
+// headers when result has no reset time. A reset time in the past gives a
+// Retry-After of 0.
+func RateLimitHeaders(result ConsumeResult) http.Header {
+	header := http.Header{}
+
+	if result.Limit >= 0 {
+		header.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	}
+	if result.ResetsAt.IsZero() {
+		return header
+	}
+
+	header.Set("X-RateLimit-Reset", strconv.FormatInt(result.ResetsAt.Unix(), 10))
+	if !result.Allowed {
+		retryAfter := math.Ceil(time.Until(result.ResetsAt).Seconds())
+		header.Set("Retry-After", strconv.Itoa(int(max(retryAfter, 0))))
+	}
+
+	return header
+}
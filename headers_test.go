@@ -0,0 +1,61 @@
+package hourglass
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitHeaders(t *testing.T) {
+	resetsAt := time.Now().Add(90 * time.Second)
+
+	tt := []struct {
+		description string
+		result      ConsumeResult
+		expected    http.Header
+	}{
+		{
+			description: "An allowed consume should not get a Retry-After",
+			result:      ConsumeResult{Current: 2, Limit: 5, Remaining: 3, Allowed: true, ResetsAt: resetsAt},
+			expected: http.Header{
+				"X-Ratelimit-Limit":     {"5"},
+				"X-Ratelimit-Remaining": {"3"},
+				"X-Ratelimit-Reset":     {strconv.FormatInt(resetsAt.Unix(), 10)},
+			},
+		},
+		{
+			description: "A denied consume should get a Retry-After until the reset",
+			result:      ConsumeResult{Current: 5, Limit: 5, Remaining: 0, Allowed: false, ResetsAt: resetsAt},
+			expected: http.Header{
+				"X-Ratelimit-Limit":     {"5"},
+				"X-Ratelimit-Remaining": {"0"},
+				"X-Ratelimit-Reset":     {strconv.FormatInt(resetsAt.Unix(), 10)},
+				"Retry-After":           {"90"},
+			},
+		},
+		{
+			description: "A reset in the past should give a Retry-After of zero",
+			result:      ConsumeResult{Current: 5, Limit: 5, Remaining: 0, Allowed: false, ResetsAt: time.Unix(1700000000, 0)},
+			expected: http.Header{
+				"X-Ratelimit-Limit":     {"5"},
+				"X-Ratelimit-Remaining": {"0"},
+				"X-Ratelimit-Reset":     {"1700000000"},
+				"Retry-After":           {"0"},
+			},
+		},
+		{
+			description: "An unknown feature should not get any headers",
+			result:      ConsumeResult{Current: -1, Limit: -1, Allowed: true},
+			expected:    http.Header{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.expected, RateLimitHeaders(tc.result))
+		})
+	}
+}
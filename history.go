@@ -0,0 +1,294 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const scanBatchSize = 100
+
+type DailyUsage struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// scanKeys returns every key matching pattern.
+func (hg *HourGlass) scanKeys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+
+	iter := hg.redisClient.Scan(ctx, 0, pattern, scanBatchSize).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+
+	return keys, iter.Err()
+}
+
+// getCounts fetches the counts stored at keys with MGETs of scanBatchSize
+// keys, sent in one pipeline. Keys that have expired or hold a value that
+// cannot be decoded are left out of the result.
+func (hg *HourGlass) getCounts(ctx context.Context, keys []string) (map[string]int, error) {
+	counts := make(map[string]int, len(keys))
+	if len(keys) == 0 {
+		return counts, nil
+	}
+
+	cmds := make([]*redis.SliceCmd, 0, (len(keys)+scanBatchSize-1)/scanBatchSize)
+	_, err := hg.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for start := 0; start < len(keys); start += scanBatchSize {
+			cmds = append(cmds, pipe.MGet(ctx, keys[start:min(start+scanBatchSize, len(keys))]...))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for n, cmd := range cmds {
+		batch := keys[n*scanBatchSize:]
+		for i, value := range cmd.Val() {
+			raw, ok := value.(string)
+			if !ok {
+				continue
+			}
+			count, _, err := hg.serializer().Decode(raw)
+			if err != nil {
+				continue
+			}
+			counts[batch[i]] = count
+		}
+	}
+
+	return counts, nil
+}
+
+// UserFeatureHistory returns the usage of userName per window for every
+// feature that still has a counter in Redis, keyed by feature and sorted by
+// date. Date is the window ID: the date for daily windows and the start time
+// for features with another Window. It scans the whole keyspace and is meant
+// for usage pages, not hot paths.
+func (hg *HourGlass) UserFeatureHistory(ctx context.Context, userName string) (map[string][]DailyUsage, error) {
+	prefix := hg.keyPrefix(ctx)
+	userName = hg.keyUser(userName)
+	keys, err := hg.scanKeys(ctx, globReplacer.Replace(prefix)+"*:"+globReplacer.Replace(userName)+":*")
+	if err != nil {
+		return nil, err
+	}
+
+	features := map[string]string{}
+	dates := map[string]string{}
+	var counterKeys []string
+	for _, key := range keys {
+		featureName, windowID, ok := hg.parseCounterKey(strings.TrimPrefix(key, prefix), userName)
+		if !ok {
+			continue
+		}
+		features[key] = featureName
+		dates[key] = windowID
+		counterKeys = append(counterKeys, key)
+	}
+
+	counts, err := hg.getCounts(ctx, counterKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	history := map[string][]DailyUsage{}
+	for key, count := range counts {
+		history[features[key]] = append(history[features[key]], DailyUsage{Date: dates[key], Count: count})
+	}
+	for _, usage := range history {
+		sort.Slice(usage, func(i, j int) bool { return usage[i].Date < usage[j].Date })
+	}
+
+	return history, nil
+}
+
+// parseCounterKey splits a feature:user:window key into its feature and
+// window ID, rejecting keys that belong to another user or are not counters
+// of the feature's window.
+func (hg *HourGlass) parseCounterKey(key, userName string) (featureName, windowID string, ok bool) {
+	separator := strings.LastIndex(key, ":")
+	if separator < 0 {
+		return "", "", false
+	}
+
+	windowID = key[separator+1:]
+	featureName, found := strings.CutSuffix(key[:separator], ":"+userName)
+	if !found || featureName == "" {
+		return "", "", false
+	}
+	if _, _, ok := hg.windowBounds(featureName, windowID); !ok {
+		return "", "", false
+	}
+
+	return featureName, windowID, true
+}
+
+// ActiveUsers returns the sorted names of the users that have a counter for
+// the current window of featureName, including users counted against a priority limit. Like
+// UserFeatureHistory it scans the keyspace. With WithHashedKeys it returns
+// the hashed names.
+func (hg *HourGlass) ActiveUsers(ctx context.Context, featureName string) ([]string, error) {
+	counters, err := hg.activeCounters(ctx, featureName)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, counter := range counters {
+		seen[counter.keyUser] = true
+	}
+
+	users := make([]string, 0, len(seen))
+	for userName := range seen {
+		users = append(users, userName)
+	}
+	sort.Strings(users)
+
+	return users, nil
+}
+
+// activeCounter is a counter of the current window of a feature.
+type activeCounter struct {
+	key string
+	// keyUser is the user as it appears in the key, hashed with
+	// WithHashedKeys.
+	keyUser  string
+	priority string
+	windowID string
+}
+
+// activeCounters returns the counters of the current window of featureName.
+// With Config.UserTimezone the current daily window of each user depends on
+// their time zone, so every date in use somewhere is scanned. Counters of a
+// user's previous or next date are filtered out by looking up the time zone,
+// except for hashed user names, which cannot be looked up.
+func (hg *HourGlass) activeCounters(ctx context.Context, featureName string) ([]activeCounter, error) {
+	now := time.Now()
+	windowIDs := hg.windowIDsAt(featureName, now)
+	filter := len(windowIDs) > 1 && hg.keySecret == nil
+
+	var counters []activeCounter
+	for _, windowID := range windowIDs {
+		err := hg.scanCounters(ctx, featureName, windowID, func(counter activeCounter) {
+			if filter && hg.userWindowIDAt(ctx, featureName, counter.keyUser, now) != windowID {
+				return
+			}
+			counters = append(counters, counter)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return counters, nil
+}
+
+// scanCounters calls fn for every counter of featureName in the window
+// identified by windowID.
+func (hg *HourGlass) scanCounters(ctx context.Context, featureName, windowID string, fn func(activeCounter)) error {
+	prefix := hg.keyPrefix(ctx) + featureName + ":"
+	suffix := ":" + windowID
+
+	iter := hg.redisClient.Scan(ctx, 0, globReplacer.Replace(prefix)+"*"+suffix, scanBatchSize).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		counter := activeCounter{key: key, keyUser: strings.TrimSuffix(strings.TrimPrefix(key, prefix), suffix), windowID: windowID}
+		if priority, rest, found := strings.Cut(counter.keyUser, ":"); found {
+			if _, ok := hg.appConfig.PriorityLimits[featureName][priority]; ok {
+				counter.keyUser = rest
+				counter.priority = priority
+			}
+		}
+		counter.keyUser = untagUser(counter.keyUser)
+		fn(counter)
+	}
+
+	return iter.Err()
+}
+
+// WindowInfo describes a counter that has not expired yet. WindowStart and
+// WindowEnd are the bounds of the feature's window the counter belongs to;
+// TTL jitter can keep the counter around a little longer.
+type WindowInfo struct {
+	Feature     string    `json:"feature"`
+	WindowStart time.Time `json:"windowStart"`
+	WindowEnd   time.Time `json:"windowEnd"`
+	Count       int       `json:"count"`
+}
+
+// ActiveWindows returns every counter of userName that is still live, sorted
+// by feature and start, so users can see when each of their windows resets.
+// Counters without an expiry are left out. It scans the keyspace like
+// UserFeatureHistory.
+func (hg *HourGlass) ActiveWindows(ctx context.Context, userName string) ([]WindowInfo, error) {
+	prefix := hg.keyPrefix(ctx)
+	location := hg.userLocation(ctx, userName)
+	userName = hg.keyUser(userName)
+	keys, err := hg.scanKeys(ctx, globReplacer.Replace(prefix)+"*:"+globReplacer.Replace(userName)+":*")
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []WindowInfo
+	var counterKeys []string
+	for _, key := range keys {
+		featureName, windowID, ok := hg.parseCounterKey(strings.TrimPrefix(key, prefix), userName)
+		if !ok {
+			continue
+		}
+		start, end, _ := hg.windowBounds(featureName, windowID)
+		if _, windowed := hg.windowStartAt(hg.baseFeature(featureName), start); !windowed {
+			// Daily windows start at midnight in the user's time zone.
+			start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location)
+			end = start.AddDate(0, 0, 1)
+		}
+		windows = append(windows, WindowInfo{Feature: featureName, WindowStart: start, WindowEnd: end})
+		counterKeys = append(counterKeys, key)
+	}
+	if len(counterKeys) == 0 {
+		return nil, nil
+	}
+
+	counts := make([]*redis.StringCmd, len(counterKeys))
+	ttls := make([]*redis.DurationCmd, len(counterKeys))
+	_, err = hg.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range counterKeys {
+			counts[i] = pipe.Get(ctx, key)
+			ttls[i] = pipe.PTTL(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	active := windows[:0]
+	for i, window := range windows {
+		if ttls[i].Val() <= 0 {
+			continue
+		}
+		count, _, err := hg.serializer().Decode(counts[i].Val())
+		if err != nil {
+			continue
+		}
+
+		window.Count = count
+		active = append(active, window)
+	}
+
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].Feature != active[j].Feature {
+			return active[i].Feature < active[j].Feature
+		}
+		return active[i].WindowStart.Before(active[j].WindowStart)
+	})
+
+	return active, nil
+}
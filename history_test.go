@@ -0,0 +1,167 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserFeatureHistory(t *testing.T) {
+	limits := map[string]int{
+		"history1": 5,
+		"history2": 3,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+		Features: map[string]FeatureConfig{
+			"history3": {Limit: 10, Window: time.Hour},
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Set(ctx, "history3:history-user:2026-01-01T140000", 7, 1*time.Minute)
+	h.redisClient.Set(ctx, "history3:history-user:2026-01-01T150000", 2, 1*time.Minute)
+	h.redisClient.Set(ctx, "history3:history-user:2026-01-01", 9, 1*time.Minute)
+	h.redisClient.Set(ctx, "history1:history-user:2026-01-01", 4, 1*time.Minute)
+	h.redisClient.Set(ctx, "history1:history-user:2026-01-02", 2, 1*time.Minute)
+	h.redisClient.Set(ctx, "history2:history-user:2026-01-02", 1, 1*time.Minute)
+	h.redisClient.Set(ctx, "history2:history-user:2026-01-02:lock", "token", 1*time.Minute)
+	h.redisClient.Set(ctx, "history2:other-history-user:2026-01-02", 3, 1*time.Minute)
+	h.redisClient.ZAdd(ctx, "history1:history-user:ts", redis.Z{Score: 1, Member: "event"})
+	h.redisClient.Expire(ctx, "history1:history-user:ts", 1*time.Minute)
+
+	t.Run("The daily usage of every feature should be returned", func(t *testing.T) {
+		history, err := h.UserFeatureHistory(ctx, "history-user")
+		require.Nil(t, err)
+
+		require.Equal(t, []DailyUsage{
+			{Date: "2026-01-01", Count: 4},
+			{Date: "2026-01-02", Count: 2},
+		}, history["history1"])
+		require.Equal(t, []DailyUsage{
+			{Date: "2026-01-02", Count: 1},
+		}, history["history2"])
+	})
+
+	t.Run("Counters of features with other windows should be returned per window", func(t *testing.T) {
+		history, err := h.UserFeatureHistory(ctx, "history-user")
+		require.Nil(t, err)
+
+		require.Equal(t, []DailyUsage{
+			{Date: "2026-01-01T140000", Count: 7},
+			{Date: "2026-01-01T150000", Count: 2},
+		}, history["history3"])
+	})
+
+	t.Run("A user without usage should get an empty history", func(t *testing.T) {
+		history, err := h.UserFeatureHistory(ctx, "history-nobody")
+		require.Nil(t, err)
+		require.Empty(t, history)
+	})
+}
+
+func TestActiveUsers(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"active1": 5,
+		},
+		PriorityLimits: map[string]map[string]int{
+			"active1": {"premium": 10},
+		},
+		KeyPrefix: "active:",
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	today := time.Now().UTC().Format("2006-01-02")
+	h.redisClient.Set(ctx, "active:active1:bob:"+today, 1, 1*time.Minute)
+	h.redisClient.Set(ctx, "active:active1:alice:"+today, 2, 1*time.Minute)
+	h.redisClient.Set(ctx, "active:active1:premium:alice:"+today, 1, 1*time.Minute)
+	h.redisClient.Set(ctx, "active:active1:premium:carol:"+today, 1, 1*time.Minute)
+	h.redisClient.Set(ctx, "active:active1:dave:2026-01-01", 1, 1*time.Minute)
+	h.redisClient.Set(ctx, "active:active1:erin:"+today+":lock", "token", 1*time.Minute)
+	h.redisClient.Set(ctx, "active1:frank:"+today, 1, 1*time.Minute)
+
+	t.Run("Users with a counter for today should be returned once", func(t *testing.T) {
+		users, err := h.ActiveUsers(ctx, "active1")
+		require.Nil(t, err)
+		require.Equal(t, []string{"alice", "bob", "carol"}, users)
+	})
+
+	t.Run("A feature without counters should have no active users", func(t *testing.T) {
+		users, err := h.ActiveUsers(ctx, "active2")
+		require.Nil(t, err)
+		require.Empty(t, users)
+	})
+}
+
+func TestActiveWindows(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"windows1": 5,
+			"windows2": 3,
+		},
+		Features: map[string]FeatureConfig{
+			"windows3": {Limit: 10, Window: time.Hour},
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Set(ctx, "windows1:windows-user:2026-01-01", 4, 1*time.Hour)
+	h.redisClient.Set(ctx, "windows1:windows-user:2026-01-02", 2, 2*time.Hour)
+	h.redisClient.Set(ctx, "windows2:windows-user:2026-01-02", 1, 3*time.Hour)
+	h.redisClient.Set(ctx, "windows2:windows-user:2026-01-03", 1, 0)
+	h.redisClient.Set(ctx, "windows2:windows-user:2026-01-02:lock", "token", 1*time.Minute)
+	h.redisClient.Set(ctx, "windows3:windows-user:2026-01-02T140000", 6, 1*time.Hour)
+
+	t.Run("Live windows should be returned with their bounds", func(t *testing.T) {
+		windows, err := h.ActiveWindows(ctx, "windows-user")
+		require.Nil(t, err)
+		require.Len(t, windows, 4)
+
+		expected := []struct {
+			feature string
+			start   time.Time
+			end     time.Time
+			count   int
+		}{
+			{"windows1", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), 4},
+			{"windows1", time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), 2},
+			{"windows2", time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), 1},
+			{"windows3", time.Date(2026, 1, 2, 14, 0, 0, 0, time.UTC), time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC), 6},
+		}
+		for i, e := range expected {
+			require.Equal(t, e.feature, windows[i].Feature)
+			require.Equal(t, e.start, windows[i].WindowStart)
+			require.Equal(t, e.end, windows[i].WindowEnd)
+			require.Equal(t, e.count, windows[i].Count)
+		}
+	})
+
+	t.Run("A user without counters should have no windows", func(t *testing.T) {
+		windows, err := h.ActiveWindows(ctx, "windows-nobody")
+		require.Nil(t, err)
+		require.Empty(t, windows)
+	})
+}
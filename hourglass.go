@@ -3,15 +3,26 @@ package hourglass
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 )
 
 //go:embed consume.lua
 var consumeScriptData string
 
+//go:embed credit.lua
+var creditScriptData string
+
 type Config struct {
 	RedisAddress  string         `json:"redisAddress"`
 	RedisPassword string         `json:"redisPassword"`
@@ -25,145 +36,876 @@ type Config struct {
 	PoolTimeout   time.Duration  `json:"poolTimeout"`
 	IdleTimeout   time.Duration  `json:"idleTimeout"`
 	MaxConnAge    time.Duration  `json:"maxConnAge"`
+
+	// PriorityLimits maps feature -> priority level -> limit. Users listed in
+	// UserPriorities consume from the pool of their priority level, which is
+	// tracked separately from the default pool.
+	PriorityLimits map[string]map[string]int `json:"priorityLimits"`
+	UserPriorities map[string]string         `json:"userPriorities"`
+
+	// TTLJitterMax spreads key expiry over [0, TTLJitterMax) so that keys do
+	// not all expire at midnight. The jitter is derived from the username and
+	// is stable for a given user.
+	TTLJitterMax time.Duration `json:"ttlJitterMax"`
+
+	// Whitelist lists users that are never rate limited and Blacklist users
+	// that are always denied.
+	Whitelist []string `json:"whitelist"`
+	Blacklist []string `json:"blacklist"`
+
+	// KeyPrefix is prepended to every key written by this instance, so that
+	// several instances can share one Redis without sharing counters.
+	KeyPrefix string `json:"keyPrefix"`
+
+	// RedisReadAddress points Get at a read replica while writes stay on
+	// RedisAddress. Replication is asynchronous, so Get may briefly lag
+	// behind the latest Consume or Credit.
+	RedisReadAddress string `json:"redisReadAddress"`
+
+	// Environments maps an environment name to limits that override Limits
+	// when Environment is set to that name.
+	Environments map[string]map[string]int `json:"environments"`
+	Environment  string                    `json:"environment"`
+
+	// Features holds per feature settings beyond the limit.
+	Features map[string]FeatureConfig `json:"features"`
+
+	// LimitExpressions configures limits as rate expressions such as
+	// "10/min", see ParseLimitExpr. They take precedence over Limits.
+	LimitExpressions map[string]string `json:"limitExpressions"`
+
+	// FeatureScripts replaces the consume script for single features with
+	// custom Lua source, e.g. a token bucket for one feature while the
+	// others keep fixed windows. The scripts follow the contract described
+	// at WithConsumeScript.
+	FeatureScripts map[string]string `json:"featureScripts"`
+
+	// TrustProxyHeaders makes ConsumeIP take the client address from
+	// X-Forwarded-For instead of the connection. Only enable it behind a
+	// proxy that sets the header, clients can send any value otherwise.
+	TrustProxyHeaders bool `json:"trustProxyHeaders"`
+
+	// UserTimezone returns the time zone whose midnight resets the daily
+	// counters of userName. Features with other windows keep using UTC.
+	// Nil, or a nil location, means UTC.
+	UserTimezone func(ctx context.Context, userName string) *time.Location `json:"-"`
+
+	// UserNamePattern, when set, rejects user names that do not match it
+	// with ErrInvalidUsername, see DefaultUserNamePattern.
+	UserNamePattern *regexp.Regexp `json:"userNamePattern"`
+
+	// ThresholdWarnings are the fractions of a limit at which Consume logs a
+	// warning, once per window. Nil means DefaultThresholdWarnings; an empty
+	// slice turns the warnings off.
+	ThresholdWarnings []float64 `json:"thresholdWarnings"`
+
+	// FeatureGroups maps a group name to its member features. Consuming a
+	// member also consumes the group, which needs its own limit in Limits.
+	FeatureGroups map[string][]string `json:"featureGroups"`
+
+	// UseHyperLogLog makes UniqueUsers count with a Redis HyperLogLog, which
+	// uses constant memory but is only accurate to about 1%.
+	UseHyperLogLog bool `json:"useHyperLogLog"`
+}
+
+// DefaultUserNamePattern accepts user names of 1 to 128 letters, digits,
+// dots, dashes and underscores.
+var DefaultUserNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// validUserName reports whether userName matches Config.UserNamePattern.
+func (hg *HourGlass) validUserName(userName string) bool {
+	return hg.appConfig.UserNamePattern == nil || hg.appConfig.UserNamePattern.MatchString(userName)
+}
+
+type FeatureConfig struct {
+	// Limit, when set, takes precedence over the feature's entry in Limits.
+	Limit int `json:"limit"`
+	// Window is how long a counter lasts before it resets. The default is
+	// one day, counted per UTC date; other windows are aligned to the Unix
+	// epoch, so 7 day windows start on Thursdays.
+	Window time.Duration `json:"window"`
+	// BurstAllowance lets a user exceed the limit by up to this many calls
+	// once per day.
+	BurstAllowance int `json:"burstAllowance"`
+	// MaxBurstPerMinute caps how many calls a user can make in any 60 second
+	// window, on top of the daily limit.
+	MaxBurstPerMinute int `json:"maxBurstPerMinute"`
+	// RolloverMax caps how many unused units a user can bank for later
+	// windows, see RolloverJob.
+	RolloverMax int `json:"rolloverMax"`
+	// RollingAverageWindows replaces the limit with the user's average
+	// consumption over that many past windows, see UpdateRollingAverage.
+	RollingAverageWindows int `json:"rollingAverageWindows"`
+	// SoftLimit is the most SoftConsume lets a user reach, with the calls
+	// over the limit reported as penalty units. Zero leaves it uncapped.
+	SoftLimit int `json:"softLimit"`
 }
 
 type HourGlass struct {
-	appConfig     Config
-	redisClient   *redis.Client
-	consumeScript *redis.Script
+	appConfig        Config
+	options          []Option
+	pool             *RedisPool
+	ownsPool         bool
+	redisClient      *redis.Client
+	readClient       *redis.Client
+	consumeScript    *redis.Script
+	featureScripts   map[string]*redis.Script
+	featureGroups    map[string][]string
+	transferScript   *redis.Script
+	unlockScript     *redis.Script
+	flushScript      *redis.Script
+	creditScript     *redis.Script
+	burstRateScript  *redis.Script
+	sharePoolScript  *redis.Script
+	bankScript       *redis.Script
+	rolloverScript   *redis.Script
+	batchScript      *redis.Script
+	leaseScript      *redis.Script
+	throttleScript   *redis.Script
+	lendScript       *redis.Script
+	idempotentScript *redis.Script
+	copyScript       *redis.Script
+
+	consumeScriptSource string
+	logger              *slog.Logger
+	timeSeriesRetention time.Duration
+	limitProvider       LimitProvider
+	whitelist           *userSet
+	blacklist           *userSet
+	localBuffer         *localBuffer
+	janitor             *janitor
+	writeCache          *writeCache
+	valueSerializer     ValueSerializer
+	cooldown            time.Duration
+	timeouts            timeouts
+	spikeDetector       *spikeDetector
+	failureMode         FailureMode
+	breaker             *circuitBreaker
+	onConnect           func(ctx context.Context, conn *redis.Conn) error
+	keySecret           []byte
+	dynamicPrefix       func(ctx context.Context) string
+	scriptResponseHook  func(raw []interface{}) ([]interface{}, error)
+	metrics             *metrics
+	shadowFeatures      map[string]bool
+	compressedKeys      bool
+	recoverFromPanic    bool
+	quotaLending        bool
+	pausing             bool
+	clusterHashTag      bool
+	coalescer           *singleflight.Group
+	backend             counterBackend
+	ownsBackend         bool
+	alertManager        *alertManager
+	alertManagerTimeout time.Duration
+	expvars             atomic.Pointer[expvars]
+	subscriptions       subscriptions
+	lazyConnect         bool
+	connected           atomic.Bool
+	clones              atomic.Int64
 }
 
-func New(config *Config) (*HourGlass, error) {
-	// Set defaults for connection pooling
-	if config.PoolSize == 0 {
-		config.PoolSize = 10
+func New(config *Config, opts ...Option) (*HourGlass, error) {
+	hg, err := newHourGlass(opts...)
+	if err != nil {
+		return nil, err
 	}
-	if config.MinIdleConns == 0 {
-		config.MinIdleConns = 5
+
+	config = hg.timeouts.apply(config)
+	pool, err := newPool(config, !hg.lazyConnect, hg.onConnect)
+	if err != nil {
+		return nil, err
 	}
-	if config.MaxRetries == 0 {
-		config.MaxRetries = 3
+
+	if err := hg.init(pool, config); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	hg.ownsPool = true
+
+	return hg, nil
+}
+
+// NewFromPool creates an HourGlass that shares the connections and script
+// registrations of pool. Closing the HourGlass leaves the pool open.
+func NewFromPool(pool *RedisPool, config *Config, opts ...Option) (*HourGlass, error) {
+	hg, err := newHourGlass(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := hg.init(pool, config); err != nil {
+		return nil, err
+	}
+
+	return hg, nil
+}
+
+func newHourGlass(opts ...Option) (*HourGlass, error) {
+	hg := &HourGlass{
+		consumeScriptSource: consumeScriptData,
+		logger:              slog.Default(),
+		metrics:             newMetrics(),
+		recoverFromPanic:    true,
+		options:             opts,
 	}
-	if config.DialTimeout == 0 {
-		config.DialTimeout = 5 * time.Second
+	for _, opt := range opts {
+		opt(hg)
+	}
+
+	if strings.TrimSpace(hg.consumeScriptSource) == "" {
+		return nil, ErrEmptyConsumeScript
 	}
-	if config.ReadTimeout == 0 {
-		config.ReadTimeout = 3 * time.Second
+	if hg.spikeDetector != nil && hg.timeSeriesRetention <= 0 {
+		return nil, ErrTimeSeriesRequired
 	}
-	if config.WriteTimeout == 0 {
-		config.WriteTimeout = 3 * time.Second
+	if hg.keySecret != nil && len(hg.keySecret) == 0 {
+		return nil, ErrEmptyKeySecret
 	}
-	if config.PoolTimeout == 0 {
-		config.PoolTimeout = 4 * time.Second
+	// The background loops tick at these intervals, and time.NewTicker
+	// panics on a non-positive one.
+	if hg.janitor != nil && hg.janitor.interval <= 0 {
+		return nil, fmt.Errorf("%w: janitor", ErrInvalidInterval)
 	}
-	if config.IdleTimeout == 0 {
-		config.IdleTimeout = 5 * time.Minute
+	if hg.localBuffer != nil && hg.localBuffer.flushInterval <= 0 {
+		return nil, fmt.Errorf("%w: local buffer flush", ErrInvalidInterval)
 	}
-	if config.MaxConnAge == 0 {
-		config.MaxConnAge = 30 * time.Minute
+	if consul, ok := hg.limitProvider.(*consulLimitProvider); ok && consul.pollInterval <= 0 {
+		return nil, fmt.Errorf("%w: consul poll", ErrInvalidInterval)
 	}
 
-	// Connect to Redis with optimized connection pool settings
-	rdb := redis.NewClient(&redis.Options{
-		Addr:            config.RedisAddress,
-		Password:        config.RedisPassword,
-		DB:              0,
-		PoolSize:        config.PoolSize,
-		MinIdleConns:    config.MinIdleConns,
-		MaxRetries:      config.MaxRetries,
-		DialTimeout:     config.DialTimeout,
-		ReadTimeout:     config.ReadTimeout,
-		WriteTimeout:    config.WriteTimeout,
-		PoolTimeout:     config.PoolTimeout,
-		ConnMaxIdleTime: config.IdleTimeout,
-		ConnMaxLifetime: config.MaxConnAge,
-	})
+	return hg, nil
+}
 
-	_, err := rdb.Ping(context.Background()).Result()
+func (hg *HourGlass) init(pool *RedisPool, config *Config) error {
+	features, err := parseFeatures(config)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	consumeScript := redis.NewScript(consumeScriptData)
+	if hg.limitProvider == nil {
+		limits, err := configuredLimits(config, features)
+		if err != nil {
+			return err
+		}
+		hg.limitProvider = newStaticLimitProvider(limits)
+	}
+	hg.featureScripts = make(map[string]*redis.Script, len(config.FeatureScripts))
+	for featureName, source := range config.FeatureScripts {
+		if strings.TrimSpace(source) == "" {
+			return fmt.Errorf("%w: feature %q", ErrEmptyConsumeScript, featureName)
+		}
+		hg.featureScripts[featureName] = redis.NewScript(source)
+	}
 
-	return &HourGlass{
-		appConfig:     *config,
-		redisClient:   rdb,
-		consumeScript: consumeScript,
-	}, nil
+	hg.appConfig = *config
+	hg.appConfig.Features = features
+	hg.pool = pool
+	hg.redisClient = pool.client
+	hg.readClient = pool.readClient
+	hg.connected.Store(!hg.lazyConnect)
+	hg.subscriptions.active = map[string]*subscription{}
+	hg.whitelist = newUserSet(config.Whitelist)
+	hg.blacklist = newUserSet(config.Blacklist)
+	hg.consumeScript = pool.consumeScript
+	if hg.consumeScriptSource != consumeScriptData {
+		hg.consumeScript = redis.NewScript(hg.consumeScriptSource)
+	}
+	hg.featureGroups = groupsByFeature(config.FeatureGroups)
+	if hg.alertManager != nil && hg.alertManagerTimeout > 0 {
+		hg.alertManager.client.Timeout = hg.alertManagerTimeout
+	}
+	hg.transferScript = pool.transferScript
+	hg.unlockScript = pool.unlockScript
+	hg.flushScript = pool.flushScript
+	hg.creditScript = pool.creditScript
+	hg.burstRateScript = pool.burstRateScript
+	hg.sharePoolScript = pool.sharePoolScript
+	hg.bankScript = pool.bankScript
+	hg.rolloverScript = pool.rolloverScript
+	hg.batchScript = pool.batchScript
+	hg.leaseScript = pool.leaseScript
+	hg.throttleScript = pool.throttleScript
+	hg.lendScript = pool.lendScript
+	hg.idempotentScript = pool.idempotentScript
+	hg.copyScript = pool.copyScript
+
+	// Background work starts only once the config is validated. The steps
+	// that can still fail come first and undo the ones before them.
+	if starter, ok := hg.limitProvider.(limitProviderStarter); ok {
+		if err := starter.start(context.Background(), hg.logger); err != nil {
+			return err
+		}
+	}
+	if hg.backend != nil {
+		if err := hg.backend.start(hg.logger); err != nil {
+			hg.closeLimitProvider()
+			return err
+		}
+		hg.ownsBackend = true
+	}
+	if hg.breaker != nil {
+		hg.breaker = pool.installBreaker(hg.breaker)
+	}
+	if hg.localBuffer != nil {
+		hg.localBuffer.start(hg)
+	}
+	if hg.janitor != nil {
+		hg.janitor.start(hg)
+	}
+	if hg.alertManager != nil {
+		hg.alertManager.start(hg)
+	}
+
+	return nil
 }
 
-func getKey(featureName, username string) string {
+// dailyKey returns the unprefixed key of today's counter of featureName for
+// username.
+func dailyKey(featureName, username string) string {
 	return fmt.Sprintf("%s:%s:%s", featureName, username, time.Now().UTC().Format("2006-01-02"))
 }
 
+// getKey returns the key of today's counter of featureName for username in
+// location, including the prefix for ctx.
+func (hg *HourGlass) getKey(ctx context.Context, featureName, username string, location *time.Location) string {
+	return fmt.Sprintf("%s%s:%s:%s", hg.keyPrefix(ctx), featureName, username, time.Now().In(location).Format("2006-01-02"))
+}
+
+// counterKey returns the key of the current window of featureName for
+// username. keyFeature is the feature part of the key, which includes the
+// priority level for priority counters.
+func (hg *HourGlass) counterKey(ctx context.Context, featureName, keyFeature, username string) string {
+	if hg.compressedKeys {
+		return hg.compressedCounterKey(ctx, featureName, keyFeature, hg.keyUser(username), time.Now().In(hg.userLocation(ctx, username)))
+	}
+	if _, windowed := hg.windowStart(featureName); !windowed {
+		return hg.getKey(ctx, keyFeature, hg.keyUser(username), hg.userLocation(ctx, username))
+	}
+	username = hg.keyUser(username)
+
+	return fmt.Sprintf("%s%s:%s:%s", hg.keyPrefix(ctx), keyFeature, username, hg.windowID(featureName))
+}
+
+// KeyFor returns the Redis key of the counter featureName keeps for userName
+// in the window that contains at, including the key prefix and the user's
+// priority level. It is meant for inspecting counters with redis-cli. With
+// WithHashedKeys the key holds the hashed user name unless raw is set, in
+// which case the plain name is used and the key does not exist in Redis.
+func (hg *HourGlass) KeyFor(ctx context.Context, featureName, userName string, at time.Time, raw bool) string {
+	keyFeature, _, _ := hg.lookupLimit(featureName, userName)
+	windowID := hg.userWindowIDAt(ctx, featureName, userName, at)
+	location := hg.userLocation(ctx, userName)
+	if !raw {
+		userName = hg.keyUser(userName)
+	}
+	if hg.compressedKeys {
+		return hg.compressedCounterKey(ctx, featureName, keyFeature, userName, at.In(location))
+	}
+	return fmt.Sprintf("%s%s:%s:%s", hg.keyPrefix(ctx), keyFeature, userName, windowID)
+}
+
+// lookup resolves the counter key and limit for a user, taking the user's
+// priority level into account before falling back to the default limit.
+func (hg *HourGlass) lookup(ctx context.Context, featureName, userName string) (key string, limit int, exists bool) {
+	keyFeature, limit, exists := hg.lookupLimit(featureName, userName)
+	return hg.counterKey(ctx, featureName, keyFeature, userName), limit, exists
+}
+
+// lookupLimit resolves the limit for a user and the feature part of its
+// counter keys.
+func (hg *HourGlass) lookupLimit(featureName, userName string) (keyFeature string, limit int, exists bool) {
+	if priority, ok := hg.appConfig.UserPriorities[userName]; ok {
+		if limit, ok := hg.appConfig.PriorityLimits[featureName][priority]; ok {
+			return featureName + ":" + priority, limit, true
+		}
+	}
+
+	limit, exists = hg.limitProvider.Limit(featureName)
+	return featureName, limit, exists
+}
+
 func (hg *HourGlass) Get(ctx context.Context, featureName, userName string) (current int, limit int) {
+	current, limit, err := hg.get(ctx, featureName, userName)
+	if err != nil {
+		return -1, limit
+	}
 
-	limit, exists := hg.appConfig.Limits[featureName]
+	return current, limit
+}
+
+func (hg *HourGlass) get(ctx context.Context, featureName, userName string) (current int, limit int, err error) {
+	key, limit, exists := hg.lookup(ctx, featureName, userName)
 	if !exists {
-		return -1, -1
+		return -1, -1, ErrUnknownFeature
+	}
+	if !hg.validUserName(userName) {
+		return -1, limit, ErrInvalidUsername
+	}
+	if hg.backend != nil {
+		return hg.backend.get(key), limit, nil
 	}
 
-	cmd := hg.redisClient.Get(ctx, getKey(featureName, userName))
-	if cmd.Err() != nil {
-		return -1, limit
+	if err := hg.ensureConnected(ctx); err != nil {
+		return -1, limit, err
 	}
 
-	consumed, err := cmd.Int()
+	raw, err := hg.readCounter(ctx, key)
 	if err != nil {
-		return -1, limit
+		return -1, limit, err
+	}
+	consumed, _, err := hg.serializer().Decode(raw)
+	if err != nil {
+		return -1, limit, err
+	}
+
+	return consumed, limit, nil
+}
+
+type ConsumeResult struct {
+	Current   int       `json:"current"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Allowed   bool      `json:"allowed"`
+	ResetsAt  time.Time `json:"resetsAt"`
+	// BurstUsed reports that the consume was only allowed by the feature's
+	// burst allowance.
+	BurstUsed bool `json:"burstUsed"`
+	// Banked is how many rolled over units the user has left. Remaining
+	// includes them.
+	Banked int `json:"banked"`
+}
+
+// Consume attempts to consume one unit of quota. The error reports why a
+// consume was denied, or that it was answered by the failure mode because
+// Redis could not be reached.
+func (hg *HourGlass) Consume(ctx context.Context, featureName, userName string, opts ...ConsumeOption) (ConsumeResult, error) {
+	return hg.consumeWith(ctx, featureName, userName, newConsumeOptions(opts))
+}
+
+func (hg *HourGlass) consume(ctx context.Context, featureName, userName string) (ConsumeResult, error) {
+	return hg.consumeWith(ctx, featureName, userName, consumeOptions{})
+}
+
+// consumeWith consumes from the counter of the current window, or from the
+// custom TTL counter when options has a TTL.
+func (hg *HourGlass) consumeWith(ctx context.Context, featureName, userName string, options consumeOptions) (ConsumeResult, error) {
+	if !hg.validUserName(userName) {
+		_, limit, exists := hg.lookupLimit(featureName, userName)
+		if !exists {
+			limit = -1
+		}
+		return ConsumeResult{Current: -1, Limit: limit, Allowed: false}, ErrInvalidUsername
 	}
 
-	return consumed, limit
+	ctx = hg.withUserLocation(ctx, userName)
+	result, err := hg.checkConsume(ctx, featureName, userName, options)
+	if result.Limit >= 0 {
+		hg.metrics.observeConsume(featureName, result)
+		if vars := hg.expvars.Load(); vars != nil {
+			vars.observeConsume(featureName, result, err)
+		}
+	}
+	if err == nil && result.Allowed && result.Limit > 0 {
+		hg.warnThresholds(ctx, featureName, userName, result)
+	}
+	if hg.compressedKeys && result.Allowed && result.Current == 1 {
+		if err := hg.recordCompressedKey(ctx, featureName, userName); err != nil {
+			hg.logger.WarnContext(ctx, "failed to record compressed key", "feature", featureName, "user", userName, "error", err)
+		}
+	}
+	if hg.shadowFeatures[featureName] && isLimitDenial(result, err) {
+		hg.logger.WarnContext(ctx, "shadow mode allowed denied consume", "feature", featureName, "user", userName, "current", result.Current, "limit", result.Limit, "error", err)
+		hg.metrics.shadowDenials.WithLabelValues(featureName).Inc()
+		result.Allowed = true
+		return result, nil
+	}
+
+	return result, err
 }
 
-func (hg *HourGlass) Consume(ctx context.Context, featureName, userName string) (current int, limit int, can bool) {
-	key := getKey(featureName, userName)
-	limit, exists := hg.appConfig.Limits[featureName]
+// isLimitDenial reports whether a consume was denied by a limit, as opposed
+// to an access rule or a Redis failure.
+func isLimitDenial(result ConsumeResult, err error) bool {
+	if result.Allowed {
+		return false
+	}
+
+	return err == nil || errors.Is(err, ErrCoolingDown) || errors.Is(err, ErrBurstLimitExceeded)
+}
+
+func (hg *HourGlass) checkConsume(ctx context.Context, featureName, userName string, options consumeOptions) (ConsumeResult, error) {
+	key, limit, exists := hg.lookup(ctx, featureName, userName)
+	ttl := options.ttl
+	customTTL := ttl > 0
+	if customTTL {
+		key = hg.ttlCounterKey(ctx, featureName, userName)
+	}
+	if hg.blacklist.contains(userName) {
+		if !exists {
+			limit = -1
+		}
+		return ConsumeResult{Current: -1, Limit: limit, Allowed: false}, ErrUserBlacklisted
+	}
 	if !exists {
-		return -1, -1, true
+		return ConsumeResult{Current: -1, Limit: -1, Allowed: true}, nil
+	}
+
+	if hg.whitelist.contains(userName) {
+		return ConsumeResult{Current: 0, Limit: limit, Remaining: limit, Allowed: true, ResetsAt: hg.userWindowEnd(ctx, featureName, userName)}, nil
+	}
+
+	if hg.backend != nil {
+		resetsAt := hg.userWindowEnd(ctx, featureName, userName)
+		current, allowed := hg.backend.consume(key, limit, resetsAt)
+		return ConsumeResult{Current: current, Limit: limit, Remaining: max(limit-current, 0), Allowed: allowed, ResetsAt: resetsAt}, nil
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		return hg.failureResult(limit, err)
+	}
+	if hg.breaker != nil && hg.breaker.isOpen() {
+		return hg.failureResult(limit, ErrCircuitOpen)
+	}
+
+	if hg.pausing {
+		paused, err := hg.isPaused(ctx, userName)
+		if err != nil {
+			hg.logger.WarnContext(ctx, "failed to check pause", "user", userName, "error", err)
+		} else if paused {
+			return ConsumeResult{Current: 0, Limit: limit, Remaining: limit, Allowed: true, ResetsAt: hg.userWindowEnd(ctx, featureName, userName)}, nil
+		}
+	}
+
+	if hg.cooldown > 0 {
+		remaining, err := hg.cooldownRemaining(ctx, key)
+		if err != nil {
+			hg.logger.WarnContext(ctx, "failed to check cooldown", "feature", featureName, "user", userName, "error", err)
+		} else if remaining > 0 {
+			return ConsumeResult{Current: -1, Limit: limit, Allowed: false, ResetsAt: time.Now().Add(remaining)}, ErrCoolingDown
+		}
+	}
+
+	featureConfig := hg.appConfig.Features[featureName]
+
+	var err error
+	if featureConfig.RollingAverageWindows > 0 {
+		limit, err = hg.rollingLimit(ctx, key, limit)
+		if err != nil {
+			hg.logger.WarnContext(ctx, "failed to read rolling average limit", "feature", featureName, "user", userName, "error", err)
+		}
+	}
+
+	if hg.quotaLending {
+		borrowed, err := hg.borrowedQuota(ctx, key)
+		if err != nil {
+			hg.logger.WarnContext(ctx, "failed to read borrowed quota", "feature", featureName, "user", userName, "error", err)
+		}
+		limit += borrowed
+	}
+
+	var releaseBurstRate func()
+	if featureConfig.MaxBurstPerMinute > 0 {
+		allowed, release, err := hg.checkBurstRate(ctx, key, featureConfig.MaxBurstPerMinute)
+		if err != nil {
+			hg.logger.WarnContext(ctx, "failed to check burst rate", "feature", featureName, "user", userName, "error", err)
+		} else if !allowed {
+			return ConsumeResult{Current: -1, Limit: limit, Allowed: false, ResetsAt: time.Now().Add(burstRateWindow)}, ErrBurstLimitExceeded
+		}
+		releaseBurstRate = release
 	}
 
 	// Calculate TTL until end of day
-	ttl := int(timeUntilEndOfDay().Seconds())
+	resetsAt := hg.userWindowEnd(ctx, featureName, userName)
+	if !customTTL {
+		ttl = hg.ttlFor(ctx, featureName, userName)
+	}
+
+	var current, banked int
+	var allowed, burstUsed bool
+	groupRemaining := -1
+	if options.idempotencyKey != "" {
+		var replayed bool
+		current, limit, allowed, burstUsed, replayed, err = hg.runIdempotentScript(ctx, key, limit, featureConfig.BurstAllowance, ttl, options)
+		if err == nil && replayed {
+			if releaseBurstRate != nil {
+				releaseBurstRate()
+			}
+			return ConsumeResult{Current: current, Limit: limit, Remaining: max(limit-current, 0), Allowed: allowed, ResetsAt: resetsAt, BurstUsed: burstUsed}, nil
+		}
+	} else if featureConfig.RolloverMax > 0 {
+		current, limit, allowed, banked, err = hg.runBankScript(ctx, hg.bankKey(ctx, featureName, userName), key, limit, ttl)
+	} else if groups := hg.featureGroups[featureName]; len(groups) > 0 {
+		current, allowed, groupRemaining, err = hg.consumeGrouped(ctx, userName, key, limit, ttl, groups)
+	} else if hg.valueSerializer != nil {
+		current, allowed, err = hg.consumeSerialized(ctx, key, limit, ttl)
+	} else if hg.localBuffer != nil {
+		current, allowed, err = hg.localBuffer.consume(ctx, key, limit, ttl)
+	} else if hg.writeCache != nil {
+		current, limit, allowed, burstUsed, err = hg.consumeWriteThrough(ctx, hg.consumeScriptFor(featureName), key, limit, featureConfig.BurstAllowance, ttl)
+	} else {
+		current, limit, allowed, burstUsed, err = hg.runConsumeScript(ctx, hg.consumeScriptFor(featureName), key, limit, featureConfig.BurstAllowance, ttl)
+	}
+	if isClusterError(err) {
+		hg.logger.ErrorContext(ctx, "redis cluster rejected consume", "feature", featureName, "user", userName, "error", err)
+	}
+	if err != nil {
+		return hg.failureResult(limit, err)
+	}
+
+	if !allowed && releaseBurstRate != nil {
+		releaseBurstRate()
+	}
+
+	if !allowed && hg.cooldown > 0 {
+		if err := hg.startCooldown(ctx, key); err != nil {
+			hg.logger.WarnContext(ctx, "failed to start cooldown", "feature", featureName, "user", userName, "error", err)
+		}
+	}
+
+	if !allowed {
+		hg.publishLimitExceeded(ctx, LimitEvent{
+			Feature:  featureName,
+			User:     userName,
+			Current:  current,
+			Limit:    limit,
+			Time:     time.Now().UTC(),
+			Metadata: options.metadata,
+		})
+	}
+
+	if allowed && hg.timeSeriesRetention > 0 {
+		if err := hg.recordTimeSeries(ctx, featureName, userName); err != nil {
+			hg.logger.WarnContext(ctx, "failed to record consume event", "feature", featureName, "user", userName, "error", err)
+		} else if hg.spikeDetector != nil {
+			if err := hg.detectSpike(ctx, featureName, userName); err != nil {
+				hg.logger.WarnContext(ctx, "failed to detect usage spike", "feature", featureName, "user", userName, "error", err)
+			}
+		}
+	}
+
+	if customTTL {
+		resetsAt = hg.ttlCounterReset(ctx, key, ttl)
+	}
+
+	remaining := max(limit-current, 0) + banked
+	if groupRemaining >= 0 {
+		remaining = min(remaining, groupRemaining)
+	}
+
+	return ConsumeResult{
+		Current:   current,
+		Limit:     limit,
+		Remaining: remaining,
+		Allowed:   allowed,
+		ResetsAt:  resetsAt,
+		BurstUsed: burstUsed,
+		Banked:    banked,
+	}, nil
+}
+
+// failureResult is the result of a consume that could not be checked against
+// Redis, allowed or denied according to the failure mode. Cluster redirects
+// and outages are always denied with ErrClusterUnavailable.
+func (hg *HourGlass) failureResult(limit int, err error) (ConsumeResult, error) {
+	if vars := hg.expvars.Load(); vars != nil {
+		vars.redisErrors.Add(1)
+	}
+	if isClusterError(err) {
+		return ConsumeResult{Current: -1, Limit: limit, Allowed: false}, ErrClusterUnavailable
+	}
+
+	return ConsumeResult{Current: -1, Limit: limit, Allowed: hg.failureMode == FailOpen}, err
+}
+
+// consumeScriptFor returns the consume script configured for featureName in
+// FeatureScripts, or the default one.
+func (hg *HourGlass) consumeScriptFor(featureName string) *redis.Script {
+	if script, ok := hg.featureScripts[featureName]; ok {
+		return script
+	}
+
+	return hg.consumeScript
+}
 
-	result := hg.consumeScript.Run(ctx, hg.redisClient, []string{key}, limit, ttl)
+func (hg *HourGlass) runConsumeScript(ctx context.Context, script *redis.Script, key string, limit, burst int, ttl time.Duration) (current int, newLimit int, allowed, burstUsed bool, err error) {
+	keys := []string{key, burstKey(key)}
+	result := script.Run(ctx, hg.redisClient, keys, limit, ttlMillis(ttl), burst)
 	if result.Err() != nil {
-		// Fail open
-		return -1, limit, true
+		return -1, limit, false, false, result.Err()
 	}
 
-	resultArray := result.Val().([]interface{})
+	current, newLimit, allowed, burstUsed, err = hg.parseConsumeResult(ctx, result.Val())
+	if err != nil {
+		return -1, limit, false, false, err
+	}
+
+	return current, newLimit, allowed, burstUsed, nil
+}
+
+// parseConsumeResult passes the reply of a consume script through the
+// script response hook and parses it. With WithRecoverFromPanic a reply of
+// the wrong shape returns ErrUnexpectedRedisResponse instead of panicking.
+func (hg *HourGlass) parseConsumeResult(ctx context.Context, reply interface{}) (current int, limit int, allowed, burstUsed bool, err error) {
+	if hg.recoverFromPanic {
+		defer func() {
+			if r := recover(); r != nil {
+				hg.logger.DebugContext(ctx, "unexpected consume script response", "response", reply, "panic", r)
+				current, limit, allowed, burstUsed, err = -1, -1, false, false, ErrUnexpectedRedisResponse
+			}
+		}()
+	}
+
+	resultArray := reply.([]interface{})
+	if hg.scriptResponseHook != nil {
+		resultArray, err = hg.scriptResponseHook(resultArray)
+		if err != nil {
+			return -1, -1, false, false, err
+		}
+		if len(resultArray) < 3 {
+			return -1, -1, false, false, ErrInvalidScriptResponse
+		}
+	}
+
+	current, limit, allowed, burstUsed = parseConsumeReply(resultArray)
+
+	return current, limit, allowed, burstUsed, nil
+}
+
+func parseConsumeReply(resultArray []interface{}) (current int, limit int, allowed, burstUsed bool) {
 	current = int(resultArray[0].(int64))
 	limit = int(resultArray[1].(int64))
-	can = resultArray[2].(int64) == 1
+	allowed = resultArray[2].(int64) == 1
+	// Custom scripts may leave out the burst flag.
+	burstUsed = len(resultArray) > 3 && resultArray[3].(int64) == 1
+
+	return current, limit, allowed, burstUsed
+}
 
-	return current, limit, can
+// burstKey returns the key that counts the burst allowance used against key.
+func burstKey(key string) string {
+	return key + ":burst"
 }
 
+// Credit gives back one unit of quota, e.g. when the work it was consumed for
+// failed. Counters never go below zero.
 func (hg *HourGlass) Credit(ctx context.Context, featureName, userName string) (current int, limit int) {
-	key := fmt.Sprintf("%s:%s:%s", featureName, userName, time.Now().UTC().Format("2006-01-02"))
+	return hg.CreditN(ctx, featureName, userName, 1)
+}
+
+// CreditN gives back amount units of quota in one atomic step, clamping the
+// counter at zero. It returns a counter of -1 for a non-positive amount.
+func (hg *HourGlass) CreditN(ctx context.Context, featureName, userName string, amount int) (current int, limit int) {
+	current, limit = hg.credit(ctx, featureName, userName, amount)
+	if current != -1 {
+		hg.metrics.credits.WithLabelValues(featureName).Add(float64(amount))
+	}
 
-	limit, exists := hg.appConfig.Limits[featureName]
+	return current, limit
+}
+
+func (hg *HourGlass) credit(ctx context.Context, featureName, userName string, amount int) (current int, limit int) {
+	key, limit, exists := hg.lookup(ctx, featureName, userName)
 	if !exists {
 		return -1, -1
 	}
+	if !hg.validUserName(userName) || amount <= 0 {
+		return -1, limit
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		return -1, limit
+	}
+
+	if hg.valueSerializer != nil {
+		current, err := hg.creditSerialized(ctx, key, amount)
+		if err != nil {
+			return -1, limit
+		}
+		return current, limit
+	}
 
-	cmd := hg.redisClient.Decr(ctx, key)
-	if cmd.Err() != nil {
+	if hg.localBuffer != nil {
+		if current, credited := hg.localBuffer.credit(key, amount); credited {
+			return current, limit
+		}
+	}
+
+	if hg.writeCache != nil {
+		hg.writeCache.invalidate(key)
+	}
+
+	current, err := hg.creditScript.Run(ctx, hg.redisClient, []string{key, burstKey(key)}, limit, amount).Int()
+	if isClusterError(err) {
+		hg.logger.ErrorContext(ctx, "redis cluster rejected credit", "feature", featureName, "user", userName, "error", err)
+	}
+	if err != nil {
 		return -1, limit
 	}
 
-	return int(cmd.Val()), hg.appConfig.Limits[featureName]
+	return current, limit
 }
 
 func (hg *HourGlass) Close() error {
-	return hg.redisClient.Close()
+	hg.closeLimitProvider()
+	if hg.localBuffer != nil {
+		hg.localBuffer.Close()
+	}
+	if hg.janitor != nil {
+		hg.janitor.Close()
+	}
+	if hg.backend != nil && hg.ownsBackend {
+		hg.backend.close()
+	}
+	hg.unsubscribeAll()
+	if hg.alertManager != nil {
+		hg.alertManager.Close()
+	}
+
+	if !hg.ownsPool {
+		return nil
+	}
+
+	return hg.pool.Close()
+}
+
+// closeLimitProvider stops the background work of the limit provider, if it
+// has any.
+func (hg *HourGlass) closeLimitProvider() {
+	if closer, ok := hg.limitProvider.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// ttlMillis converts ttl to the milliseconds the scripts expect. It is at
+// least 1, since Redis rejects a zero expiry and a counter created in the
+// last millisecond of its window still has to expire.
+func ttlMillis(ttl time.Duration) int64 {
+	return max(ttl.Milliseconds(), 1)
+}
+
+// ttlFor returns the TTL for a new key of featureName and userName,
+// including jitter.
+func (hg *HourGlass) ttlFor(ctx context.Context, featureName, userName string) time.Duration {
+	ttl := time.Until(hg.userWindowEnd(ctx, featureName, userName))
+	if hg.appConfig.TTLJitterMax <= 0 {
+		return ttl
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(userName))
+	return ttl + time.Duration(h.Sum64()%uint64(hg.appConfig.TTLJitterMax))
 }
 
 func timeUntilEndOfDay() time.Duration {
-	timeRightNow := time.Now().UTC()
-	endOfDay := time.Date(timeRightNow.Year(), timeRightNow.Month(), timeRightNow.Day()+1, 0, 0, 0, 0, time.UTC)
-	duration := endOfDay.Sub(timeRightNow)
-	return duration
+	return time.Until(endOfDay())
+}
+
+func endOfDay() time.Time {
+	return endOfDayIn(time.UTC)
+}
+
+// endOfDayIn returns the next midnight in location.
+func endOfDayIn(location *time.Location) time.Time {
+	timeRightNow := time.Now().In(location)
+	return time.Date(timeRightNow.Year(), timeRightNow.Month(), timeRightNow.Day()+1, 0, 0, 0, 0, location)
 }
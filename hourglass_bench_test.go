@@ -0,0 +1,132 @@
+package hourglass
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	benchRedisAddress   = "localhost:6379"
+	benchRedisAvailable bool
+)
+
+func TestMain(m *testing.M) {
+	if address := os.Getenv("HOURGLASS_BENCH_REDIS_ADDRESS"); address != "" {
+		benchRedisAddress = address
+	}
+
+	rdb := redis.NewClient(&redis.Options{Addr: benchRedisAddress})
+	benchRedisAvailable = rdb.Ping(context.Background()).Err() == nil
+	rdb.Close()
+
+	os.Exit(m.Run())
+}
+
+func newBenchHourGlass(b *testing.B) *HourGlass {
+	b.Helper()
+
+	if !benchRedisAvailable {
+		b.Skipf("redis is not available at %s", benchRedisAddress)
+	}
+
+	h, err := New(&Config{
+		RedisAddress: benchRedisAddress,
+		Limits: map[string]int{
+			"bench": 1 << 30,
+		},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { h.Close() })
+
+	return h
+}
+
+func BenchmarkConsume(b *testing.B) {
+	h := newBenchHourGlass(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Consume(ctx, "bench", "bench-consume")
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	h := newBenchHourGlass(b)
+	ctx := context.Background()
+	h.Consume(ctx, "bench", "bench-get")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Get(ctx, "bench", "bench-get")
+	}
+}
+
+func BenchmarkCredit(b *testing.B) {
+	h := newBenchHourGlass(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Credit(ctx, "bench", "bench-credit")
+	}
+}
+
+func BenchmarkConsumeParallel(b *testing.B) {
+	h := newBenchHourGlass(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			h.Consume(ctx, "bench", "bench-parallel")
+		}
+	})
+}
+
+func BenchmarkPipelinedGet(b *testing.B) {
+	h := newBenchHourGlass(b)
+	ctx := context.Background()
+
+	const users = 100
+	keys := make([]string, users)
+	for i := range keys {
+		keys[i] = dailyKey("bench", fmt.Sprintf("bench-pipelined-%d", i))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := h.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Get(ctx, key)
+			}
+			return nil
+		})
+		if err != nil && err != redis.Nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConsumeWhitelisted(b *testing.B) {
+	h := newBenchHourGlass(b)
+	ctx := context.Background()
+	h.AddToWhitelist("bench-whitelisted")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Consume(ctx, "bench", "bench-whitelisted")
+	}
+}
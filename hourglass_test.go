@@ -2,6 +2,7 @@ package hourglass
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -96,14 +97,14 @@ func TestConsume(t *testing.T) {
 	for _, test := range tt {
 		t.Run(test.description, func(t *testing.T) {
 			for feature, limit := range test.existingLimits {
-				key := getKey(feature, test.username)
+				key := dailyKey(feature, test.username)
 				h.redisClient.Set(ctx, key, limit, 1*time.Minute)
 			}
 
-			currrent, _, can := h.Consume(ctx, test.featureName, test.username)
+			result, _ := h.Consume(ctx, test.featureName, test.username)
 
-			require.Equal(t, test.expectedCanRunFeature, can)
-			require.Equal(t, test.expectedIncrementedValue, currrent)
+			require.Equal(t, test.expectedCanRunFeature, result.Allowed)
+			require.Equal(t, test.expectedIncrementedValue, result.Current)
 		})
 	}
 
@@ -172,7 +173,7 @@ func TestGet(t *testing.T) {
 	for _, test := range tt {
 		t.Run(test.description, func(t *testing.T) {
 			for feature, limit := range test.existingLimits {
-				key := getKey(feature, test.username)
+				key := dailyKey(feature, test.username)
 				h.redisClient.Set(ctx, key, limit, 1*time.Minute)
 			}
 
@@ -237,7 +238,7 @@ func TestCredit(t *testing.T) {
 	for _, test := range tt {
 		t.Run(test.description, func(t *testing.T) {
 			for feature, limit := range test.existingLimits {
-				key := getKey(feature, test.username)
+				key := dailyKey(feature, test.username)
 				h.redisClient.Set(ctx, key, limit, 1*time.Minute)
 			}
 
@@ -249,3 +250,399 @@ func TestCredit(t *testing.T) {
 	}
 
 }
+
+func TestCreditN(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits:       map[string]int{"feature1": 5},
+	})
+	require.Nil(t, err)
+	defer h.Close()
+
+	tt := []struct {
+		description          string
+		existing             int
+		amount               int
+		expectedCurrentValue int
+	}{
+		{
+			description:          "Credits several units at once",
+			existing:             4,
+			amount:               3,
+			expectedCurrentValue: 1,
+		},
+		{
+			description:          "Clamps the counter at zero",
+			existing:             2,
+			amount:               5,
+			expectedCurrentValue: 0,
+		},
+		{
+			description:          "Does not create a missing counter",
+			existing:             -1,
+			amount:               2,
+			expectedCurrentValue: 0,
+		},
+		{
+			description:          "Rejects a non-positive amount",
+			existing:             4,
+			amount:               0,
+			expectedCurrentValue: -1,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			key := dailyKey("feature1", "creditn")
+			h.redisClient.Del(ctx, key)
+			if test.existing >= 0 {
+				h.redisClient.Set(ctx, key, test.existing, time.Minute)
+			}
+
+			current, limit := h.CreditN(ctx, "feature1", "creditn", test.amount)
+			require.Equal(t, test.expectedCurrentValue, current)
+			require.Equal(t, 5, limit)
+
+			if test.existing < 0 {
+				require.Zero(t, h.redisClient.Exists(ctx, key).Val())
+			}
+		})
+	}
+}
+
+func TestConsumeWithPriority(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+		PriorityLimits: map[string]map[string]int{
+			"feature1": {
+				"premium": 3,
+			},
+		},
+		UserPriorities: map[string]string{
+			"vip": "premium",
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "regular"), dailyKey("feature1:premium", "vip"))
+
+	tt := []struct {
+		description           string
+		username              string
+		expectedCurrent       int
+		expectedLimit         int
+		expectedCanRunFeature bool
+	}{
+		{
+			description:           "A user without a priority should use the default limit",
+			username:              "regular",
+			expectedCurrent:       1,
+			expectedLimit:         1,
+			expectedCanRunFeature: true,
+		},
+		{
+			description:           "A user without a priority should be denied once the default limit is hit",
+			username:              "regular",
+			expectedCurrent:       1,
+			expectedLimit:         1,
+			expectedCanRunFeature: false,
+		},
+		{
+			description:           "A user with a priority should consume from the priority pool",
+			username:              "vip",
+			expectedCurrent:       1,
+			expectedLimit:         3,
+			expectedCanRunFeature: true,
+		},
+		{
+			description:           "A user with a priority should not be limited by the default pool",
+			username:              "vip",
+			expectedCurrent:       2,
+			expectedLimit:         3,
+			expectedCanRunFeature: true,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			result, _ := h.Consume(ctx, "feature1", test.username)
+
+			require.Equal(t, test.expectedCanRunFeature, result.Allowed)
+			require.Equal(t, test.expectedCurrent, result.Current)
+			require.Equal(t, test.expectedLimit, result.Limit)
+		})
+	}
+}
+
+func TestTTLJitter(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+		TTLJitterMax: 10 * time.Minute,
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	t.Run("The jitter should be stable for a user and within the configured window", func(t *testing.T) {
+		jitter := h.ttlFor(ctx, "feature1", "jitter-user") - timeUntilEndOfDay()
+		require.GreaterOrEqual(t, jitter, time.Duration(0))
+		require.Less(t, jitter, 10*time.Minute)
+
+		again := h.ttlFor(ctx, "feature1", "jitter-user") - timeUntilEndOfDay()
+		require.InDelta(t, float64(jitter), float64(again), float64(time.Second))
+	})
+
+	t.Run("The jitter should be applied to the key expiry", func(t *testing.T) {
+		h.redisClient.Del(ctx, dailyKey("feature1", "jitter-user"))
+		h.Consume(ctx, "feature1", "jitter-user")
+
+		ttl, err := h.redisClient.TTL(ctx, dailyKey("feature1", "jitter-user")).Result()
+		require.Nil(t, err)
+		require.InDelta(t, h.ttlFor(ctx, "feature1", "jitter-user").Seconds(), ttl.Seconds(), 2)
+	})
+}
+
+func TestConsumePreservesTTL(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := dailyKey("feature1", "ttl-user")
+
+	t.Run("The first consume should set the TTL to the end of the day", func(t *testing.T) {
+		h.redisClient.Del(ctx, key)
+		h.Consume(ctx, "feature1", "ttl-user")
+
+		ttl, err := h.redisClient.TTL(ctx, key).Result()
+		require.Nil(t, err)
+		require.InDelta(t, timeUntilEndOfDay().Seconds(), ttl.Seconds(), 2)
+	})
+
+	t.Run("Later consumes should not extend the existing TTL", func(t *testing.T) {
+		h.redisClient.Set(ctx, key, 1, 1*time.Minute)
+		result, _ := h.Consume(ctx, "feature1", "ttl-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 2, result.Current)
+
+		ttl, err := h.redisClient.TTL(ctx, key).Result()
+		require.Nil(t, err)
+		require.LessOrEqual(t, ttl, 1*time.Minute)
+	})
+	t.Run("A counter created in the last second of its window should get an expiry", func(t *testing.T) {
+		for _, ttl := range []time.Duration{300 * time.Millisecond, 0} {
+			h.redisClient.Del(ctx, key)
+			current, _, allowed, _, err := h.runConsumeScript(ctx, h.consumeScript, key, 5, 0, ttl)
+			require.Nil(t, err)
+			require.True(t, allowed)
+			require.Equal(t, 1, current)
+
+			pttl := h.redisClient.PTTL(ctx, key).Val()
+			require.LessOrEqual(t, pttl, max(ttl, time.Millisecond))
+		}
+	})
+}
+
+func TestBurstAllowance(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"burst-feature":    2,
+			"feature-no-burst": 1,
+		},
+		Features: map[string]FeatureConfig{
+			"burst-feature": {BurstAllowance: 1},
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := dailyKey("burst-feature", "burst-user")
+	h.redisClient.Del(ctx, key, burstKey(key))
+
+	for i := 0; i < 2; i++ {
+		result, err := h.consume(ctx, "burst-feature", "burst-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.False(t, result.BurstUsed)
+	}
+
+	t.Run("The first call over the limit should use the burst allowance", func(t *testing.T) {
+		result, err := h.consume(ctx, "burst-feature", "burst-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.True(t, result.BurstUsed)
+		require.Equal(t, 3, result.Current)
+	})
+
+	t.Run("A second call over the limit should be denied", func(t *testing.T) {
+		result, err := h.consume(ctx, "burst-feature", "burst-user")
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+		require.False(t, result.BurstUsed)
+		require.Equal(t, 3, result.Current)
+	})
+
+	t.Run("Crediting the burst call should restore the allowance", func(t *testing.T) {
+		current, _ := h.Credit(ctx, "burst-feature", "burst-user")
+		require.Equal(t, 2, current)
+
+		result, err := h.consume(ctx, "burst-feature", "burst-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.True(t, result.BurstUsed)
+	})
+
+	t.Run("Features without a burst allowance should stop at the limit", func(t *testing.T) {
+		h.redisClient.Del(ctx, dailyKey("feature-no-burst", "burst-user"))
+
+		result, _ := h.Consume(ctx, "feature-no-burst", "burst-user")
+		require.True(t, result.Allowed)
+		result, _ = h.Consume(ctx, "feature-no-burst", "burst-user")
+		require.False(t, result.Allowed)
+	})
+}
+
+func TestKeyFor(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+		LimitExpressions: map[string]string{
+			"hourly": "10/hour",
+		},
+		PriorityLimits: map[string]map[string]int{
+			"feature1": {"high": 10},
+		},
+		UserPriorities: map[string]string{
+			"premium-user": "high",
+		},
+		KeyPrefix: "app:",
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	at := time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		feature  string
+		user     string
+		at       time.Time
+		expected string
+	}{
+		{name: "Daily feature", feature: "feature1", user: "user", at: at, expected: "app:feature1:user:2024-03-15"},
+		{name: "Priority user", feature: "feature1", user: "premium-user", at: at, expected: "app:feature1:high:premium-user:2024-03-15"},
+		{name: "Windowed feature", feature: "hourly", user: "user", at: at, expected: "app:hourly:user:2024-03-15T140000"},
+		{name: "Time in another zone", feature: "feature1", user: "user", at: at.In(time.FixedZone("UTC+12", 12*60*60)), expected: "app:feature1:user:2024-03-15"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, h.KeyFor(ctx, tt.feature, tt.user, tt.at, false))
+		})
+	}
+
+	t.Run("The current key should match the counter key", func(t *testing.T) {
+		key, _, _ := h.lookup(ctx, "feature1", "user")
+		require.Equal(t, key, h.KeyFor(ctx, "feature1", "user", time.Now(), false))
+	})
+}
+
+func TestUserNamePattern(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+		UserNamePattern: DefaultUserNamePattern,
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "valid.user-1_a"))
+
+	tt := []struct {
+		description string
+		userName    string
+		expectedErr error
+	}{
+		{
+			description: "A user name of letters, digits, dots, dashes and underscores should be accepted",
+			userName:    "valid.user-1_a",
+		},
+		{
+			description: "An empty user name should be rejected",
+			userName:    "",
+			expectedErr: ErrInvalidUsername,
+		},
+		{
+			description: "A user name with other characters should be rejected",
+			userName:    "x' OR '1'='1",
+			expectedErr: ErrInvalidUsername,
+		},
+		{
+			description: "A user name over 128 characters should be rejected",
+			userName:    strings.Repeat("a", 129),
+			expectedErr: ErrInvalidUsername,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := h.Consume(ctx, "feature1", tc.userName)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedErr == nil, result.Allowed)
+
+			_, _, err = h.get(ctx, "feature1", tc.userName)
+			require.ErrorIs(t, err, tc.expectedErr)
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, h.ScheduleReset(ctx, "feature1", tc.userName, time.Now().Add(time.Hour)), tc.expectedErr)
+			}
+		})
+	}
+
+	t.Run("Credit should ignore an invalid user name", func(t *testing.T) {
+		current, limit := h.Credit(ctx, "feature1", "")
+		require.Equal(t, -1, current)
+		require.Equal(t, 5, limit)
+	})
+}
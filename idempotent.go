@@ -0,0 +1,52 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+	"time"
+)
+
+//go:embed idempotent.lua
+var idempotentScriptData string
+
+// ConsumeIdempotent consumes one unit like Consume, but only once per
+// idempotencyKey: the result of the first call is stored for ttl, with
+// millisecond precision, and retries
+// with the same key get it back without consuming again. Checking for the key
+// and consuming happen in one script. Idempotency keys are scoped to the
+// feature and user. The feature's custom consume script, the local buffer,
+// the write-through cache and value serializers are not used.
+func (hg *HourGlass) ConsumeIdempotent(ctx context.Context, featureName, userName, idempotencyKey string, ttl time.Duration) (ConsumeResult, error) {
+	if idempotencyKey == "" {
+		return ConsumeResult{Current: -1, Limit: -1, Allowed: false}, ErrEmptyIdempotencyKey
+	}
+	if ttl < time.Millisecond {
+		return ConsumeResult{Current: -1, Limit: -1, Allowed: false}, ErrInvalidTTL
+	}
+
+	return hg.consumeWith(ctx, featureName, userName, consumeOptions{idempotencyKey: idempotencyKey, idempotencyTTL: ttl})
+}
+
+// idempotencyKey returns the key that holds the result of the consume made
+// for the counter at key with idempotencyKey.
+func idempotencyKey(key, idempotencyKey string) string {
+	return key + ":idempotency:" + idempotencyKey
+}
+
+// runIdempotentScript runs idempotent.lua. replayed reports that the result
+// was stored by an earlier call with the same idempotency key.
+func (hg *HourGlass) runIdempotentScript(ctx context.Context, key string, limit, burst int, ttl time.Duration, options consumeOptions) (current int, newLimit int, allowed, burstUsed, replayed bool, err error) {
+	keys := []string{key, burstKey(key), idempotencyKey(key, options.idempotencyKey)}
+	reply, err := hg.idempotentScript.Run(ctx, hg.redisClient, keys, limit, ttlMillis(ttl), burst, options.idempotencyTTL.Milliseconds()).Slice()
+	if err != nil {
+		return -1, limit, false, false, false, err
+	}
+	if len(reply) != 5 {
+		return -1, limit, false, false, false, ErrUnexpectedRedisResponse
+	}
+
+	current, newLimit, allowed, burstUsed = parseConsumeReply(reply)
+	replayed = reply[4].(int64) == 1
+
+	return current, newLimit, allowed, burstUsed, replayed, nil
+}
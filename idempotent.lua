@@ -0,0 +1,48 @@
+local key = KEYS[1]
+local burst_key = KEYS[2]
+local idempotency_key = KEYS[3]
+local limit = tonumber(ARGV[1])
+local ttl_ms = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3]) or 0
+local idempotency_ttl_ms = tonumber(ARGV[4])
+
+-- A retry gets the reply of the first call, flagged as replayed.
+local cached = redis.call('GET', idempotency_key)
+if cached then
+    local reply = cjson.decode(cached)
+    table.insert(reply, 1)
+    return reply
+end
+
+local function remember(reply)
+    redis.call('SET', idempotency_key, cjson.encode(reply), 'PX', idempotency_ttl_ms)
+    table.insert(reply, 0)
+    return reply
+end
+
+local current = tonumber(redis.call('GET', key) or '0')
+
+if current >= limit then
+    -- Over the limit, the burst allowance covers up to burst extra calls.
+    if burst <= 0 then
+        return remember({current, limit, 0, 0})
+    end
+
+    local used = tonumber(redis.call('GET', burst_key) or '0')
+    if used >= burst then
+        return remember({current, limit, 0, 0})
+    end
+
+    if redis.call('INCR', burst_key) == 1 then
+        redis.call('PEXPIRE', burst_key, ttl_ms)
+    end
+
+    return remember({redis.call('INCR', key), limit, 1, 1})
+end
+
+-- Only a new key gets a TTL, later increments keep the original expiry.
+if current == 0 and redis.call('SET', key, 1, 'PX', ttl_ms, 'NX') then
+    return remember({1, limit, 1, 0})
+end
+
+return remember({redis.call('INCR', key), limit, 1, 0})
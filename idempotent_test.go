@@ -0,0 +1,118 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeIdempotent(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits:       map[string]int{"idempotent": 2},
+		KeyPrefix:    "idempotent-test:",
+	})
+	require.NoError(t, err)
+	defer h.Close()
+
+	keys, _ := h.redisClient.Keys(ctx, "idempotent-test:*").Result()
+	if len(keys) > 0 {
+		h.redisClient.Del(ctx, keys...)
+	}
+
+	tt := []struct {
+		description     string
+		idempotencyKey  string
+		ttl             time.Duration
+		expectedCurrent int
+		expectedAllowed bool
+		expectedErr     error
+	}{
+		{
+			description:     "The first call should consume",
+			idempotencyKey:  "request-1",
+			ttl:             time.Minute,
+			expectedCurrent: 1,
+			expectedAllowed: true,
+		},
+		{
+			description:     "A retry should return the stored result without consuming",
+			idempotencyKey:  "request-1",
+			ttl:             time.Minute,
+			expectedCurrent: 1,
+			expectedAllowed: true,
+		},
+		{
+			description:     "Another key should consume",
+			idempotencyKey:  "request-2",
+			ttl:             time.Minute,
+			expectedCurrent: 2,
+			expectedAllowed: true,
+		},
+		{
+			description:     "A call over the limit should be denied",
+			idempotencyKey:  "request-3",
+			ttl:             time.Minute,
+			expectedCurrent: 2,
+			expectedAllowed: false,
+		},
+		{
+			description:     "A retry of an allowed call should stay allowed after the limit is reached",
+			idempotencyKey:  "request-2",
+			ttl:             time.Minute,
+			expectedCurrent: 2,
+			expectedAllowed: true,
+		},
+		{
+			description:     "An empty key should fail",
+			ttl:             time.Minute,
+			expectedCurrent: -1,
+			expectedErr:     ErrEmptyIdempotencyKey,
+		},
+		{
+			description:     "A non-positive TTL should fail",
+			idempotencyKey:  "request-4",
+			expectedCurrent: -1,
+			expectedErr:     ErrInvalidTTL,
+		},
+		{
+			description:     "A TTL under a millisecond should fail",
+			idempotencyKey:  "request-4",
+			ttl:             time.Microsecond,
+			expectedCurrent: -1,
+			expectedErr:     ErrInvalidTTL,
+		},
+		{
+			description:     "A sub-second TTL should be accepted",
+			idempotencyKey:  "request-5",
+			ttl:             500 * time.Millisecond,
+			expectedCurrent: 2,
+			expectedAllowed: false,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			result, err := h.ConsumeIdempotent(ctx, "idempotent", "alice", test.idempotencyKey, test.ttl)
+			require.ErrorIs(t, err, test.expectedErr)
+			require.Equal(t, test.expectedCurrent, result.Current)
+			require.Equal(t, test.expectedAllowed, result.Allowed)
+		})
+	}
+
+	current, _ := h.Get(ctx, "idempotent", "alice")
+	require.Equal(t, 2, current)
+
+	key, _, _ := h.lookup(ctx, "idempotent", "alice")
+	ttl := h.redisClient.TTL(ctx, idempotencyKey(key, "request-1")).Val()
+	require.Greater(t, ttl, time.Duration(0))
+	require.LessOrEqual(t, ttl, time.Minute)
+
+	pttl := h.redisClient.PTTL(ctx, idempotencyKey(key, "request-5")).Val()
+	require.Greater(t, pttl, time.Duration(0))
+	require.LessOrEqual(t, pttl, 500*time.Millisecond)
+}
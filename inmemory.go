@@ -0,0 +1,129 @@
+package hourglass
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InMemory is a Limiter that keeps its counters in process instead of Redis,
+// for unit tests without external dependencies. Counters behave like the
+// ones of Consume with daily windows and expire at the end of the UTC day.
+// Whitelists, blacklists and feature settings are not supported.
+type InMemory struct {
+	limits   map[string]int
+	counters sync.Map
+}
+
+type memCounter struct {
+	value    atomic.Int64
+	resetsAt time.Time
+	timer    *time.Timer
+}
+
+// NewInMemory returns an InMemory limiter for limits.
+func NewInMemory(limits map[string]int) *InMemory {
+	return &InMemory{limits: maps.Clone(limits)}
+}
+
+// counter returns the counter of key, creating one that expires at resetsAt
+// when there is none.
+func (m *InMemory) counter(key string, resetsAt time.Time) *memCounter {
+	if c, ok := m.counters.Load(key); ok {
+		return c.(*memCounter)
+	}
+
+	// The timer is set before the counter is published, so Close never
+	// sees a counter without one.
+	c := &memCounter{resetsAt: resetsAt}
+	c.timer = time.AfterFunc(time.Until(resetsAt), func() {
+		m.counters.CompareAndDelete(key, c)
+	})
+	actual, loaded := m.counters.LoadOrStore(key, c)
+	if loaded {
+		c.timer.Stop()
+		return actual.(*memCounter)
+	}
+
+	return c
+}
+
+// Consume attempts to consume one unit of quota like HourGlass.Consume.
+// Metadata is ignored.
+func (m *InMemory) Consume(ctx context.Context, featureName, userName string, opts ...ConsumeOption) (ConsumeResult, error) {
+	limit, exists := m.limits[featureName]
+	if !exists {
+		return ConsumeResult{Current: -1, Limit: -1, Allowed: true}, nil
+	}
+
+	c := m.counter(dailyKey(featureName, userName), endOfDay())
+	for {
+		current := c.value.Load()
+		if current >= int64(limit) {
+			return ConsumeResult{Current: int(current), Limit: limit, Allowed: false, ResetsAt: c.resetsAt}, nil
+		}
+		if c.value.CompareAndSwap(current, current+1) {
+			return ConsumeResult{
+				Current:   int(current + 1),
+				Limit:     limit,
+				Remaining: limit - int(current+1),
+				Allowed:   true,
+				ResetsAt:  c.resetsAt,
+			}, nil
+		}
+	}
+}
+
+// Get returns the counter and limit of featureName for userName. Like
+// HourGlass.Get it returns -1 for users without a counter.
+func (m *InMemory) Get(ctx context.Context, featureName, userName string) (current int, limit int) {
+	limit, exists := m.limits[featureName]
+	if !exists {
+		return -1, -1
+	}
+
+	c, ok := m.counters.Load(dailyKey(featureName, userName))
+	if !ok {
+		return -1, limit
+	}
+
+	return int(c.(*memCounter).value.Load()), limit
+}
+
+// Credit gives back one unit of quota like HourGlass.Credit.
+func (m *InMemory) Credit(ctx context.Context, featureName, userName string) (current int, limit int) {
+	limit, exists := m.limits[featureName]
+	if !exists {
+		return -1, -1
+	}
+
+	// Like the Redis counters, crediting a user without a counter creates
+	// none.
+	loaded, ok := m.counters.Load(dailyKey(featureName, userName))
+	if !ok {
+		return 0, limit
+	}
+	c := loaded.(*memCounter)
+	for {
+		count := c.value.Load()
+		if count <= 0 {
+			return int(count), limit
+		}
+		if c.value.CompareAndSwap(count, count-1) {
+			return int(count - 1), limit
+		}
+	}
+}
+
+// Close stops the expiry timers of all counters.
+func (m *InMemory) Close() error {
+	m.counters.Range(func(key, c any) bool {
+		c.(*memCounter).timer.Stop()
+		m.counters.Delete(key)
+		return true
+	})
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package hourglass
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemory(t *testing.T) {
+	ctx := context.Background()
+
+	m := NewInMemory(map[string]int{
+		"feature1": 2,
+	})
+	defer m.Close()
+
+	tt := []struct {
+		description     string
+		featureName     string
+		expectedCurrent int
+		expectedLimit   int
+		expectedAllowed bool
+	}{
+		{
+			description:     "The first consume should be allowed",
+			featureName:     "feature1",
+			expectedCurrent: 1,
+			expectedLimit:   2,
+			expectedAllowed: true,
+		},
+		{
+			description:     "A consume up to the limit should be allowed",
+			featureName:     "feature1",
+			expectedCurrent: 2,
+			expectedLimit:   2,
+			expectedAllowed: true,
+		},
+		{
+			description:     "A consume over the limit should be denied",
+			featureName:     "feature1",
+			expectedCurrent: 2,
+			expectedLimit:   2,
+			expectedAllowed: false,
+		},
+		{
+			description:     "An unknown feature should be allowed",
+			featureName:     "feature-notexistent",
+			expectedCurrent: -1,
+			expectedLimit:   -1,
+			expectedAllowed: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := m.Consume(ctx, tc.featureName, "memory-user")
+			require.Nil(t, err)
+			require.Equal(t, tc.expectedCurrent, result.Current)
+			require.Equal(t, tc.expectedLimit, result.Limit)
+			require.Equal(t, tc.expectedAllowed, result.Allowed)
+		})
+	}
+
+	t.Run("Credit should give back a unit", func(t *testing.T) {
+		current, _ := m.Credit(ctx, "feature1", "memory-user")
+		require.Equal(t, 1, current)
+
+		current, limit := m.Get(ctx, "feature1", "memory-user")
+		require.Equal(t, 1, current)
+		require.Equal(t, 2, limit)
+	})
+
+	t.Run("Credit should not create a counter for a user without one", func(t *testing.T) {
+		current, limit := m.Credit(ctx, "feature1", "memory-credit-user")
+		require.Equal(t, 0, current)
+		require.Equal(t, 2, limit)
+
+		current, _ = m.Get(ctx, "feature1", "memory-credit-user")
+		require.Equal(t, -1, current)
+	})
+
+	t.Run("Concurrent consumes should not exceed the limit", func(t *testing.T) {
+		m := NewInMemory(map[string]int{"feature1": 50})
+		defer m.Close()
+
+		var wg sync.WaitGroup
+		for range 100 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				m.Consume(ctx, "feature1", "concurrent-user")
+			}()
+		}
+		wg.Wait()
+
+		current, _ := m.Get(ctx, "feature1", "concurrent-user")
+		require.Equal(t, 50, current)
+	})
+
+	t.Run("Closing while counters are created should not panic", func(t *testing.T) {
+		m := NewInMemory(map[string]int{"feature1": 50})
+
+		var wg sync.WaitGroup
+		for i := range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				m.Consume(ctx, "feature1", fmt.Sprintf("closing-user-%d", i))
+			}()
+		}
+		require.NotPanics(t, func() { m.Close() })
+		wg.Wait()
+		m.Close()
+	})
+}
@@ -0,0 +1,37 @@
+package hourglass
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ConsumeIP consumes one unit of featureName for the client address of r, for
+// endpoints without an authenticated user. The address is taken from
+// r.RemoteAddr, or with Config.TrustProxyHeaders from the last entry of
+// X-Forwarded-For, which is the one appended by the proxy in front of the
+// service. Addresses share counters with users of the same name.
+func (hg *HourGlass) ConsumeIP(ctx context.Context, featureName string, r *http.Request) (ConsumeResult, error) {
+	return hg.consume(ctx, featureName, clientIP(r, hg.appConfig.TrustProxyHeaders))
+}
+
+// clientIP returns the client address of r without the port.
+func clientIP(r *http.Request, trustProxyHeaders bool) string {
+	if trustProxyHeaders {
+		forwarded := r.Header.Values("X-Forwarded-For")
+		if len(forwarded) > 0 {
+			addresses := strings.Split(forwarded[len(forwarded)-1], ",")
+			if ip := strings.TrimSpace(addresses[len(addresses)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
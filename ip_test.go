@@ -0,0 +1,101 @@
+package hourglass
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	tt := []struct {
+		description       string
+		remoteAddr        string
+		forwardedFor      []string
+		trustProxyHeaders bool
+		expected          string
+	}{
+		{
+			description: "The port should be stripped from the remote address",
+			remoteAddr:  "203.0.113.7:51234",
+			expected:    "203.0.113.7",
+		},
+		{
+			description: "IPv6 remote addresses should be supported",
+			remoteAddr:  "[2001:db8::1]:51234",
+			expected:    "2001:db8::1",
+		},
+		{
+			description:  "Proxy headers should be ignored unless trusted",
+			remoteAddr:   "10.0.0.1:51234",
+			forwardedFor: []string{"203.0.113.7"},
+			expected:     "10.0.0.1",
+		},
+		{
+			description:       "The address appended by the proxy should be used when trusted",
+			remoteAddr:        "10.0.0.1:51234",
+			forwardedFor:      []string{"198.51.100.9, 203.0.113.7"},
+			trustProxyHeaders: true,
+			expected:          "203.0.113.7",
+		},
+		{
+			description:       "The last of several headers should be used",
+			remoteAddr:        "10.0.0.1:51234",
+			forwardedFor:      []string{"198.51.100.9", "203.0.113.7"},
+			trustProxyHeaders: true,
+			expected:          "203.0.113.7",
+		},
+		{
+			description:       "The remote address should be used without a header",
+			remoteAddr:        "10.0.0.1:51234",
+			trustProxyHeaders: true,
+			expected:          "10.0.0.1",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tc.remoteAddr
+			for _, value := range tc.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			require.Equal(t, tc.expected, clientIP(r, tc.trustProxyHeaders))
+		})
+	}
+}
+
+func TestConsumeIP(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "203.0.113.7"))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "203.0.113.7:51234"
+
+	t.Run("The first request from an address should be allowed", func(t *testing.T) {
+		result, err := h.ConsumeIP(ctx, "feature1", r)
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+	})
+
+	t.Run("A request from the same address on another port should be denied", func(t *testing.T) {
+		r.RemoteAddr = "203.0.113.7:40000"
+		result, err := h.ConsumeIP(ctx, "feature1", r)
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+	})
+}
@@ -0,0 +1,125 @@
+package hourglass
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithJanitor starts a background goroutine that scans the keys of the
+// configured features every interval and repairs keys that have no expiry.
+// Keys for the current window get their regular end of window TTL, keys from
+// earlier windows are deleted.
+func WithJanitor(interval time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.janitor = &janitor{
+			interval: interval,
+			done:     make(chan struct{}),
+			stopped:  make(chan struct{}),
+		}
+	}
+}
+
+type janitor struct {
+	hg       *HourGlass
+	interval time.Duration
+
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+}
+
+func (j *janitor) start(hg *HourGlass) {
+	j.hg = hg
+
+	go func() {
+		defer close(j.stopped)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-j.done:
+				return
+			case <-ticker.C:
+				if _, err := j.sweep(context.Background()); err != nil {
+					hg.logger.Warn("janitor sweep failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// sweep repairs every key without an expiry and returns how many it touched.
+func (j *janitor) sweep(ctx context.Context) (int, error) {
+	var cleaned int
+
+	for featureName := range j.hg.limitProvider.Limits() {
+		pattern := globReplacer.Replace(j.hg.keyPrefix(ctx)+featureName+":") + "*"
+
+		keys, err := j.hg.scanKeys(ctx, pattern)
+		if err != nil {
+			return cleaned, err
+		}
+
+		for _, key := range keys {
+			ttl, err := j.hg.redisClient.TTL(ctx, key).Result()
+			if err != nil {
+				return cleaned, err
+			}
+			// go-redis reports a key without expiry as a TTL of -1ns.
+			if ttl != -1 {
+				continue
+			}
+
+			expiresAt, ok := j.hg.keyExpiry(featureName, key)
+			if !ok {
+				continue
+			}
+
+			// A key that cannot be repaired is reported and left for the next
+			// sweep, the others are still repaired.
+			if time.Until(expiresAt) <= 0 {
+				if err := j.hg.redisClient.Del(ctx, key).Err(); err != nil {
+					j.hg.logger.WarnContext(ctx, "janitor failed to delete key without expiry", "key", key, "error", err)
+					continue
+				}
+				j.hg.logger.WarnContext(ctx, "janitor deleted key without expiry", "key", key)
+			} else {
+				if err := j.hg.redisClient.ExpireAt(ctx, key, expiresAt).Err(); err != nil {
+					j.hg.logger.WarnContext(ctx, "janitor failed to set missing expiry", "key", key, "error", err)
+					continue
+				}
+				j.hg.logger.WarnContext(ctx, "janitor set missing expiry", "key", key, "expires_at", expiresAt)
+			}
+			cleaned++
+		}
+	}
+
+	return cleaned, nil
+}
+
+func (j *janitor) Close() error {
+	j.closeOnce.Do(func() {
+		close(j.done)
+		<-j.stopped
+	})
+	return nil
+}
+
+// keyExpiry returns the end of the window a key of featureName was written
+// for. It finds the window among the trailing segments so that suffixed keys
+// such as burst markers are covered too. Keys without a window are left
+// alone.
+func (hg *HourGlass) keyExpiry(featureName, key string) (time.Time, bool) {
+	segments := strings.Split(key, ":")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if _, end, ok := hg.windowBounds(featureName, segments[i]); ok {
+			return end, true
+		}
+	}
+
+	return time.Time{}, false
+}
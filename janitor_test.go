@@ -0,0 +1,132 @@
+package hourglass
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJanitor(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"janitor-feature": 5,
+		},
+		Features: map[string]FeatureConfig{
+			"janitor-hourly": {Limit: 5, Window: time.Hour},
+		},
+	}, WithJanitor(20*time.Millisecond))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	todayKey := dailyKey("janitor-feature", "janitor-user")
+	staleKey := "janitor-feature:janitor-user:" + time.Now().UTC().AddDate(0, 0, -2).Format("2006-01-02")
+	expiringKey := dailyKey("janitor-feature", "janitor-other")
+	h.redisClient.Del(ctx, todayKey, staleKey, expiringKey)
+
+	hourlyKey := h.KeyFor(ctx, "janitor-hourly", "janitor-user", time.Now(), false)
+	staleHourlyKey := h.KeyFor(ctx, "janitor-hourly", "janitor-user", time.Now().Add(-2*time.Hour), false)
+	h.redisClient.Del(ctx, hourlyKey, staleHourlyKey)
+	require.Nil(t, h.redisClient.Set(ctx, hourlyKey, 3, 0).Err())
+	require.Nil(t, h.redisClient.Set(ctx, staleHourlyKey, 3, 0).Err())
+
+	require.Nil(t, h.redisClient.Set(ctx, todayKey, 3, 0).Err())
+	require.Nil(t, h.redisClient.Set(ctx, staleKey, 3, 0).Err())
+	require.Nil(t, h.redisClient.Set(ctx, expiringKey, 3, time.Hour).Err())
+
+	t.Run("Keys for today should get an end of day expiry", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			return h.redisClient.TTL(ctx, todayKey).Val() > 0
+		}, time.Second, 10*time.Millisecond)
+
+		ttl := h.redisClient.TTL(ctx, todayKey).Val()
+		require.InDelta(t, timeUntilEndOfDay().Seconds(), ttl.Seconds(), 2)
+		require.Equal(t, "3", h.redisClient.Get(ctx, todayKey).Val())
+	})
+
+	t.Run("Keys from earlier days should be deleted", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			return h.redisClient.Exists(ctx, staleKey).Val() == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Keys with an expiry should be left alone", func(t *testing.T) {
+		ttl := h.redisClient.TTL(ctx, expiringKey).Val()
+		require.InDelta(t, time.Hour.Seconds(), ttl.Seconds(), 2)
+	})
+
+	t.Run("Keys of features with other windows should expire with their window", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			return h.redisClient.TTL(ctx, hourlyKey).Val() > 0
+		}, time.Second, 10*time.Millisecond)
+
+		ttl := h.redisClient.TTL(ctx, hourlyKey).Val()
+		require.InDelta(t, time.Until(h.windowEnd("janitor-hourly")).Seconds(), ttl.Seconds(), 2)
+
+		require.Eventually(t, func() bool {
+			return h.redisClient.Exists(ctx, staleHourlyKey).Val() == 0
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+// failingDelHook fails every DEL sent to Redis.
+type failingDelHook struct{}
+
+func (failingDelHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (failingDelHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == "del" {
+			cmd.SetErr(errors.New("del failed"))
+			return cmd.Err()
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (failingDelHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestJanitorFailedRepair(t *testing.T) {
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits: map[string]int{
+			"janitor-failing": 5,
+		},
+	}, WithJanitor(time.Hour), WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	staleKey := "janitor-failing:janitor-user:" + time.Now().UTC().AddDate(0, 0, -2).Format("2006-01-02")
+	todayKey := dailyKey("janitor-failing", "janitor-user")
+	require.Nil(t, h.redisClient.Set(ctx, staleKey, 3, 0).Err())
+	require.Nil(t, h.redisClient.Set(ctx, todayKey, 3, 0).Err())
+	defer h.redisClient.Del(ctx, staleKey, todayKey)
+
+	client := redis.NewClient(h.redisClient.Options())
+	defer client.Close()
+	client.AddHook(failingDelHook{})
+	h.redisClient = client
+
+	cleaned, err := h.janitor.sweep(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 1, cleaned)
+	require.Contains(t, buf.String(), "janitor failed to delete key without expiry")
+	require.NotContains(t, buf.String(), "janitor deleted key without expiry")
+	require.Greater(t, client.TTL(ctx, todayKey).Val(), time.Duration(0))
+}
@@ -0,0 +1,151 @@
+// Package jsonrpc serves an HourGlass over JSON-RPC 1.0 using net/rpc/jsonrpc,
+// for environments that manage rate limits without HTTP.
+package jsonrpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/rpc"
+	stdjsonrpc "net/rpc/jsonrpc"
+
+	"hourglass"
+)
+
+// serviceName prefixes every method, e.g. hourglass.Consume.
+const serviceName = "hourglass"
+
+// Args names the feature and user of a call.
+type Args struct {
+	Feature string `json:"feature"`
+	User    string `json:"user"`
+}
+
+// CounterReply is the counter and limit returned by Get and Credit.
+type CounterReply struct {
+	Current int `json:"current"`
+	Limit   int `json:"limit"`
+}
+
+// Empty is the argument and reply of calls that do not need one.
+type Empty struct{}
+
+// Service holds the methods exposed over JSON-RPC. It is exported for
+// net/rpc; use NewJSONRPCServer to serve it.
+type Service struct {
+	hg *hourglass.HourGlass
+}
+
+// Get returns the counter of a user.
+func (s *Service) Get(args *Args, reply *CounterReply) error {
+	reply.Current, reply.Limit = s.hg.Get(context.Background(), args.Feature, args.User)
+	return nil
+}
+
+// Consume consumes one unit for a user. A denied consume is not an error;
+// the error reports why a consume failed, as with HourGlass.Consume.
+func (s *Service) Consume(args *Args, reply *hourglass.ConsumeResult) error {
+	result, err := s.hg.Consume(context.Background(), args.Feature, args.User)
+	*reply = result
+	return err
+}
+
+// Credit gives one unit back to a user.
+func (s *Service) Credit(args *Args, reply *CounterReply) error {
+	reply.Current, reply.Limit = s.hg.Credit(context.Background(), args.Feature, args.User)
+	return nil
+}
+
+// Reset sets the counter of a user back to zero.
+func (s *Service) Reset(args *Args, reply *Empty) error {
+	return s.hg.SetUsage(context.Background(), args.Feature, args.User, 0)
+}
+
+// ListFeatures returns the limit of every feature.
+func (s *Service) ListFeatures(args *Empty, reply *map[string]int) error {
+	*reply = s.hg.Limits()
+	return nil
+}
+
+// Server serves the hourglass methods over JSON-RPC.
+type Server struct {
+	rpc *rpc.Server
+}
+
+// NewJSONRPCServer returns a Server exposing hourglass.Get, hourglass.Consume,
+// hourglass.Credit, hourglass.Reset and hourglass.ListFeatures for hg. The
+// server does no authentication; only expose it on trusted networks.
+func NewJSONRPCServer(hg *hourglass.HourGlass) *Server {
+	server := rpc.NewServer()
+	// Service has only valid methods, so registration cannot fail.
+	if err := server.RegisterName(serviceName, &Service{hg: hg}); err != nil {
+		panic(err)
+	}
+
+	return &Server{rpc: server}
+}
+
+// ServeConn serves a single connection until the client hangs up.
+func (s *Server) ServeConn(conn io.ReadWriteCloser) {
+	s.rpc.ServeCodec(stdjsonrpc.NewServerCodec(conn))
+}
+
+// Serve accepts connections on listener and serves each of them in its own
+// goroutine until the listener is closed.
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.ServeConn(conn)
+	}
+}
+
+// JSONRPCClient calls a Server.
+type JSONRPCClient struct {
+	rpc *rpc.Client
+}
+
+// NewJSONRPCClient returns a client that talks to a Server over conn.
+func NewJSONRPCClient(conn io.ReadWriteCloser) *JSONRPCClient {
+	return &JSONRPCClient{rpc: stdjsonrpc.NewClient(conn)}
+}
+
+// Get returns the counter and limit of a user.
+func (c *JSONRPCClient) Get(featureName, userName string) (current int, limit int, err error) {
+	var reply CounterReply
+	err = c.rpc.Call(serviceName+".Get", &Args{Feature: featureName, User: userName}, &reply)
+	return reply.Current, reply.Limit, err
+}
+
+// Consume consumes one unit for a user.
+func (c *JSONRPCClient) Consume(featureName, userName string) (hourglass.ConsumeResult, error) {
+	var reply hourglass.ConsumeResult
+	err := c.rpc.Call(serviceName+".Consume", &Args{Feature: featureName, User: userName}, &reply)
+	return reply, err
+}
+
+// Credit gives one unit back to a user.
+func (c *JSONRPCClient) Credit(featureName, userName string) (current int, limit int, err error) {
+	var reply CounterReply
+	err = c.rpc.Call(serviceName+".Credit", &Args{Feature: featureName, User: userName}, &reply)
+	return reply.Current, reply.Limit, err
+}
+
+// Reset sets the counter of a user back to zero.
+func (c *JSONRPCClient) Reset(featureName, userName string) error {
+	return c.rpc.Call(serviceName+".Reset", &Args{Feature: featureName, User: userName}, &Empty{})
+}
+
+// ListFeatures returns the limit of every feature.
+func (c *JSONRPCClient) ListFeatures() (map[string]int, error) {
+	var reply map[string]int
+	err := c.rpc.Call(serviceName+".ListFeatures", &Empty{}, &reply)
+	return reply, err
+}
+
+// Close closes the connection.
+func (c *JSONRPCClient) Close() error {
+	return c.rpc.Close()
+}
@@ -0,0 +1,69 @@
+package jsonrpc
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"hourglass"
+)
+
+func TestJSONRPC(t *testing.T) {
+	hg, err := hourglass.New(&hourglass.Config{
+		RedisAddress: "localhost:6379",
+		Limits:       map[string]int{"rpc": 2},
+		KeyPrefix:    "jsonrpc-test:",
+	})
+	require.NoError(t, err)
+	defer hg.Close()
+
+	serverConn, clientConn := net.Pipe()
+	go NewJSONRPCServer(hg).ServeConn(serverConn)
+	client := NewJSONRPCClient(clientConn)
+	defer client.Close()
+
+	require.NoError(t, client.Reset("rpc", "alice"))
+
+	t.Run("ListFeatures should return the limits", func(t *testing.T) {
+		features, err := client.ListFeatures()
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"rpc": 2}, features)
+	})
+
+	t.Run("Consume should count against the limit", func(t *testing.T) {
+		for _, expectedAllowed := range []bool{true, true, false} {
+			result, err := client.Consume("rpc", "alice")
+			require.NoError(t, err)
+			require.Equal(t, expectedAllowed, result.Allowed)
+			require.Equal(t, 2, result.Limit)
+		}
+	})
+
+	t.Run("Get should return the counter", func(t *testing.T) {
+		current, limit, err := client.Get("rpc", "alice")
+		require.NoError(t, err)
+		require.Equal(t, 2, current)
+		require.Equal(t, 2, limit)
+	})
+
+	t.Run("Credit should give a unit back", func(t *testing.T) {
+		current, limit, err := client.Credit("rpc", "alice")
+		require.NoError(t, err)
+		require.Equal(t, 1, current)
+		require.Equal(t, 2, limit)
+	})
+
+	t.Run("Reset should clear the counter", func(t *testing.T) {
+		require.NoError(t, client.Reset("rpc", "alice"))
+
+		current, _, err := client.Get("rpc", "alice")
+		require.NoError(t, err)
+		require.Equal(t, 0, current)
+	})
+
+	t.Run("Errors should be returned to the client", func(t *testing.T) {
+		err := client.Reset("rpc-notexistent", "alice")
+		require.EqualError(t, err, hourglass.ErrUnknownFeature.Error())
+	})
+}
@@ -0,0 +1,98 @@
+package hourglass
+
+import (
+	"context"
+	"sort"
+)
+
+// ConsumerRank is a user's place among the consumers of a feature.
+type ConsumerRank struct {
+	UserName string `json:"userName"`
+	Count    int    `json:"count"`
+	Rank     int    `json:"rank"`
+}
+
+// TopConsumers returns the n users that consumed featureName most in the
+// current window, highest count first with ties ordered by name. Counts
+// against a priority limit are added to the user's default count. It scans
+// the keyspace and reads every counter of the feature, so it is O(active
+// users) and meant for dashboards, not for hot paths. With WithHashedKeys it
+// returns the hashed names.
+func (hg *HourGlass) TopConsumers(ctx context.Context, featureName string, n int) ([]ConsumerRank, error) {
+	if _, exists := hg.limitProvider.Limit(featureName); !exists {
+		return nil, ErrUnknownFeature
+	}
+	if n <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	counters, err := hg.activeCounters(ctx, featureName)
+	if err != nil || len(counters) == 0 {
+		return nil, err
+	}
+
+	keys := make([]string, len(counters))
+	for i, counter := range counters {
+		keys[i] = counter.key
+	}
+	values, err := hg.getCounts(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, counter := range counters {
+		if count, ok := values[counter.key]; ok {
+			counts[counter.keyUser] += count
+		}
+	}
+
+	ranks := make([]ConsumerRank, 0, len(counts))
+	for userName, count := range counts {
+		ranks = append(ranks, ConsumerRank{UserName: userName, Count: count})
+	}
+	sort.Slice(ranks, func(i, j int) bool {
+		if ranks[i].Count != ranks[j].Count {
+			return ranks[i].Count > ranks[j].Count
+		}
+		return ranks[i].UserName < ranks[j].UserName
+	})
+
+	ranks = ranks[:min(n, len(ranks))]
+	for i := range ranks {
+		ranks[i].Rank = i + 1
+	}
+
+	return ranks, nil
+}
+
+// TotalConsumed returns how often featureName was consumed in the current
+// window across all users, including counts against priority limits. Like
+// TopConsumers it scans the keyspace and is meant for capacity planning, not
+// for hot paths.
+func (hg *HourGlass) TotalConsumed(ctx context.Context, featureName string) (int64, error) {
+	if _, exists := hg.limitProvider.Limit(featureName); !exists {
+		return 0, ErrUnknownFeature
+	}
+
+	counters, err := hg.activeCounters(ctx, featureName)
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make([]string, len(counters))
+	for i, counter := range counters {
+		keys[i] = counter.key
+	}
+	counts, err := hg.getCounts(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, count := range counts {
+		total += int64(count)
+	}
+
+	return total, nil
+}
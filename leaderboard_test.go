@@ -0,0 +1,175 @@
+package hourglass
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopConsumers(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"leaderboard": 10,
+		},
+		KeyPrefix: "leaderboard-test:",
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	keys, _ := h.redisClient.Keys(ctx, "leaderboard-test:*").Result()
+	if len(keys) > 0 {
+		h.redisClient.Del(ctx, keys...)
+	}
+
+	for userName, consumes := range map[string]int{"alice": 3, "bob": 5, "carol": 3, "dave": 1} {
+		for range consumes {
+			h.Consume(ctx, "leaderboard", userName)
+		}
+	}
+
+	tt := []struct {
+		description   string
+		featureName   string
+		n             int
+		expectedRanks []ConsumerRank
+		expectedErr   error
+	}{
+		{
+			description: "The top consumers should be ranked by count and then name",
+			featureName: "leaderboard",
+			n:           3,
+			expectedRanks: []ConsumerRank{
+				{UserName: "bob", Count: 5, Rank: 1},
+				{UserName: "alice", Count: 3, Rank: 2},
+				{UserName: "carol", Count: 3, Rank: 3},
+			},
+		},
+		{
+			description: "Asking for more than there are should return everyone",
+			featureName: "leaderboard",
+			n:           10,
+			expectedRanks: []ConsumerRank{
+				{UserName: "bob", Count: 5, Rank: 1},
+				{UserName: "alice", Count: 3, Rank: 2},
+				{UserName: "carol", Count: 3, Rank: 3},
+				{UserName: "dave", Count: 1, Rank: 4},
+			},
+		},
+		{
+			description: "A non-positive n should fail",
+			featureName: "leaderboard",
+			n:           0,
+			expectedErr: ErrInvalidAmount,
+		},
+		{
+			description: "An unknown feature should fail",
+			featureName: "feature-notexistent",
+			n:           3,
+			expectedErr: ErrUnknownFeature,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			ranks, err := h.TopConsumers(ctx, tc.featureName, tc.n)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedRanks, ranks)
+		})
+	}
+}
+
+func TestTopConsumersManyUsers(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits: map[string]int{
+			"leaderboard-many": 10,
+		},
+		KeyPrefix: "leaderboard-many-test:",
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	keys, _ := h.redisClient.Keys(ctx, "leaderboard-many-test:*").Result()
+	if len(keys) > 0 {
+		h.redisClient.Del(ctx, keys...)
+	}
+
+	// More users than fit in one MGET batch.
+	users := scanBatchSize*2 + 1
+	for i := range users {
+		require.NoError(t, h.SetUsage(ctx, "leaderboard-many", fmt.Sprintf("user-%04d", i), 1))
+	}
+	require.NoError(t, h.SetUsage(ctx, "leaderboard-many", "user-0000", 7))
+
+	ranks, err := h.TopConsumers(ctx, "leaderboard-many", users)
+	require.Nil(t, err)
+	require.Len(t, ranks, users)
+	require.Equal(t, ConsumerRank{UserName: "user-0000", Count: 7, Rank: 1}, ranks[0])
+	require.Equal(t, ConsumerRank{UserName: fmt.Sprintf("user-%04d", users-1), Count: 1, Rank: users}, ranks[users-1])
+}
+
+func TestTotalConsumed(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits: map[string]int{
+			"total":       10,
+			"total-empty": 10,
+		},
+		KeyPrefix: "total-test:",
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	keys, _ := h.redisClient.Keys(ctx, "total-test:*").Result()
+	if len(keys) > 0 {
+		h.redisClient.Del(ctx, keys...)
+	}
+
+	for userName, count := range map[string]int{"alice": 3, "bob": 5, "carol": 1} {
+		require.NoError(t, h.SetUsage(ctx, "total", userName, count))
+	}
+
+	tt := []struct {
+		description   string
+		featureName   string
+		expectedTotal int64
+		expectedErr   error
+	}{
+		{
+			description:   "The counters of all users should be summed",
+			featureName:   "total",
+			expectedTotal: 9,
+		},
+		{
+			description:   "A feature nobody used should total zero",
+			featureName:   "total-empty",
+			expectedTotal: 0,
+		},
+		{
+			description: "An unknown feature should fail",
+			featureName: "feature-notexistent",
+			expectedErr: ErrUnknownFeature,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			total, err := h.TotalConsumed(ctx, tc.featureName)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedTotal, total)
+		})
+	}
+}
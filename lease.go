@@ -0,0 +1,74 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+	"time"
+)
+
+//go:embed lease.lua
+var leaseScriptData string
+
+// leaseKey returns the sorted set of the leases userName holds on
+// featureName, scored by their expiry. It is not tied to a window, so leases
+// keep counting and can be released after the counter's window rolls over.
+func (hg *HourGlass) leaseKey(ctx context.Context, featureName, userName string) (key string, limit int, exists bool) {
+	keyFeature, limit, exists := hg.lookupLimit(featureName, userName)
+	return hg.keyPrefix(ctx) + keyFeature + ":" + hg.keyUser(userName) + ":leases", limit, exists
+}
+
+// AcquireLease reserves one of the limit slots of featureName for userName
+// until duration has passed or ReleaseLease is called, even if the holder
+// crashes in between. Leases are counted apart from Consume and fail with
+// ErrLimitExceeded when all slots are held.
+func (hg *HourGlass) AcquireLease(ctx context.Context, featureName, userName string, duration time.Duration) (leaseID string, err error) {
+	if duration <= 0 {
+		return "", ErrInvalidTTL
+	}
+
+	key, limit, exists := hg.leaseKey(ctx, featureName, userName)
+	if !exists {
+		return "", ErrUnknownFeature
+	}
+	if hg.blacklist.contains(userName) {
+		return "", ErrUserBlacklisted
+	}
+
+	leaseID, err = newToken()
+	if err != nil {
+		return "", err
+	}
+	if hg.whitelist.contains(userName) {
+		return leaseID, nil
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	acquired, err := hg.leaseScript.Run(ctx, hg.redisClient, []string{key}, now.UnixMilli(), now.Add(duration).UnixMilli(), limit, leaseID).Int()
+	if err != nil {
+		return "", err
+	}
+	if acquired == 0 {
+		return "", ErrLimitExceeded
+	}
+
+	return leaseID, nil
+}
+
+// ReleaseLease frees the slot held by leaseID before it expires. Releasing
+// an expired or unknown lease does nothing.
+func (hg *HourGlass) ReleaseLease(ctx context.Context, featureName, userName, leaseID string) error {
+	key, _, exists := hg.leaseKey(ctx, featureName, userName)
+	if !exists {
+		return ErrUnknownFeature
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		return err
+	}
+
+	return hg.redisClient.ZRem(ctx, key, leaseID).Err()
+}
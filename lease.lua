@@ -0,0 +1,17 @@
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local expires = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+-- Leases whose holder never released them free their slot once expired.
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now)
+if redis.call('ZCARD', key) >= limit then
+    return 0
+end
+
+redis.call('ZADD', key, expires, ARGV[4])
+if redis.call('PTTL', key) < expires - now then
+    redis.call('PEXPIRE', key, expires - now)
+end
+
+return 1
@@ -0,0 +1,104 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireLease(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 2,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key, _, _ := h.leaseKey(ctx, "feature1", "lease-user")
+	h.redisClient.Del(ctx, key, dailyKey("feature1", "lease-user"))
+
+	tt := []struct {
+		description string
+		featureName string
+		duration    time.Duration
+		expectedErr error
+	}{
+		{
+			description: "The first lease should be acquired",
+			featureName: "feature1",
+			duration:    time.Minute,
+		},
+		{
+			description: "A lease up to the limit should be acquired",
+			featureName: "feature1",
+			duration:    time.Minute,
+		},
+		{
+			description: "A lease over the limit should fail",
+			featureName: "feature1",
+			duration:    time.Minute,
+			expectedErr: ErrLimitExceeded,
+		},
+		{
+			description: "A non-positive duration should fail",
+			featureName: "feature1",
+			expectedErr: ErrInvalidTTL,
+		},
+		{
+			description: "An unknown feature should fail",
+			featureName: "feature-notexistent",
+			duration:    time.Minute,
+			expectedErr: ErrUnknownFeature,
+		},
+	}
+
+	var leases []string
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			leaseID, err := h.AcquireLease(ctx, tc.featureName, "lease-user", tc.duration)
+			require.ErrorIs(t, err, tc.expectedErr)
+			if err == nil {
+				require.NotEmpty(t, leaseID)
+				leases = append(leases, leaseID)
+			}
+		})
+	}
+
+	t.Run("Leases should not touch the consume counter", func(t *testing.T) {
+		result, err := h.Consume(ctx, "feature1", "lease-user")
+		require.Nil(t, err)
+		require.Equal(t, 1, result.Current)
+	})
+
+	t.Run("A released lease should free its slot", func(t *testing.T) {
+		require.Nil(t, h.ReleaseLease(ctx, "feature1", "lease-user", leases[0]))
+
+		_, err := h.AcquireLease(ctx, "feature1", "lease-user", time.Minute)
+		require.Nil(t, err)
+	})
+
+	t.Run("An expired lease should free its slot", func(t *testing.T) {
+		h.redisClient.Del(ctx, key)
+
+		_, err := h.AcquireLease(ctx, "feature1", "lease-user", time.Millisecond)
+		require.Nil(t, err)
+		_, err = h.AcquireLease(ctx, "feature1", "lease-user", time.Minute)
+		require.Nil(t, err)
+
+		time.Sleep(5 * time.Millisecond)
+		_, err = h.AcquireLease(ctx, "feature1", "lease-user", time.Minute)
+		require.Nil(t, err)
+	})
+
+	t.Run("The lease set should not belong to a window", func(t *testing.T) {
+		require.Equal(t, "feature1:lease-user:leases", key)
+	})
+}
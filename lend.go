@@ -0,0 +1,137 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lend.lua
+var lendScriptData string
+
+// LendResult reports the quotas after a loan.
+type LendResult struct {
+	// FromRemaining is what the lender has left in the current window.
+	FromRemaining int `json:"fromRemaining"`
+	// ToLimit is the borrower's limit for the current window, including
+	// everything lent to them.
+	ToLimit int `json:"toLimit"`
+}
+
+// Loan is the total amount one user lent another in the current window.
+type Loan struct {
+	FromUser string `json:"fromUser"`
+	ToUser   string `json:"toUser"`
+	Amount   int    `json:"amount"`
+}
+
+// WithQuotaLending lets LendQuota raise limits. Consume then reads the units
+// lent to the user along with their counter, which costs one GET per call.
+func WithQuotaLending() Option {
+	return func(hg *HourGlass) {
+		hg.quotaLending = true
+	}
+}
+
+// borrowedKey returns the key that holds the units lent to the counter at key.
+func borrowedKey(key string) string {
+	return key + ":borrowed"
+}
+
+// loansKey returns the hash that records the loans of featureName in the
+// current window.
+func (hg *HourGlass) loansKey(ctx context.Context, featureName string) string {
+	return hg.keyPrefix(ctx) + "loans:" + featureName + ":" + hg.windowID(featureName)
+}
+
+// borrowedQuota returns the units lent to the counter at key.
+func (hg *HourGlass) borrowedQuota(ctx context.Context, key string) (int, error) {
+	borrowed, err := hg.redisClient.Get(ctx, borrowedKey(key)).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return borrowed, err
+}
+
+// LendQuota moves amount units of fromUser's remaining quota for featureName
+// to toUser for the current window. The units count as consumed by fromUser
+// and raise toUser's limit. Both happen in one script, which fails with
+// ErrInsufficientQuota if fromUser has fewer than amount units left. It
+// requires WithQuotaLending.
+func (hg *HourGlass) LendQuota(ctx context.Context, featureName, fromUser, toUser string, amount int) (LendResult, error) {
+	if !hg.quotaLending {
+		return LendResult{}, ErrLendingDisabled
+	}
+	if amount <= 0 {
+		return LendResult{}, ErrInvalidAmount
+	}
+	if !hg.validUserName(fromUser) || !hg.validUserName(toUser) {
+		return LendResult{}, ErrInvalidUsername
+	}
+
+	fromKey, fromLimit, exists := hg.lookup(ctx, featureName, fromUser)
+	if !exists {
+		return LendResult{}, ErrUnknownFeature
+	}
+	toKey, toLimit, _ := hg.lookup(ctx, featureName, toUser)
+
+	loan, err := json.Marshal([]string{fromUser, toUser})
+	if err != nil {
+		return LendResult{}, err
+	}
+
+	keys := []string{fromKey, borrowedKey(fromKey), borrowedKey(toKey), hg.loansKey(ctx, featureName)}
+	fromTTL := ttlMillis(hg.ttlFor(ctx, featureName, fromUser))
+	toTTL := ttlMillis(hg.ttlFor(ctx, featureName, toUser))
+
+	reply, err := hg.lendScript.Run(ctx, hg.redisClient, keys, amount, fromLimit, toLimit, fromTTL, toTTL, loan).Int64Slice()
+	if err != nil {
+		return LendResult{}, err
+	}
+	if reply[0] == 1 {
+		return LendResult{FromRemaining: int(reply[1])}, ErrInsufficientQuota
+	}
+
+	return LendResult{FromRemaining: int(reply[1]), ToLimit: int(reply[2])}, nil
+}
+
+// Loans returns the loans made for featureName in the current window, ordered
+// by lender and borrower.
+func (hg *HourGlass) Loans(ctx context.Context, featureName string) ([]Loan, error) {
+	if _, exists := hg.limitProvider.Limit(featureName); !exists {
+		return nil, ErrUnknownFeature
+	}
+
+	fields, err := hg.redisClient.HGetAll(ctx, hg.loansKey(ctx, featureName)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	loans := make([]Loan, 0, len(fields))
+	for field, amount := range fields {
+		var users []string
+		if err := json.Unmarshal([]byte(field), &users); err != nil || len(users) != 2 {
+			hg.logger.WarnContext(ctx, "failed to decode loan", "feature", featureName, "loan", field)
+			continue
+		}
+		lent, err := strconv.Atoi(amount)
+		if err != nil {
+			return nil, err
+		}
+		loans = append(loans, Loan{FromUser: users[0], ToUser: users[1], Amount: lent})
+	}
+	sort.Slice(loans, func(i, j int) bool {
+		if loans[i].FromUser != loans[j].FromUser {
+			return loans[i].FromUser < loans[j].FromUser
+		}
+		return loans[i].ToUser < loans[j].ToUser
+	})
+
+	return loans, nil
+}
@@ -0,0 +1,34 @@
+local from_key = KEYS[1]
+local from_borrowed_key = KEYS[2]
+local to_borrowed_key = KEYS[3]
+local loans_key = KEYS[4]
+local amount = tonumber(ARGV[1])
+local from_limit = tonumber(ARGV[2])
+local to_limit = tonumber(ARGV[3])
+local from_ttl_ms = tonumber(ARGV[4])
+local to_ttl_ms = tonumber(ARGV[5])
+local loan = ARGV[6]
+
+-- The lender may lend what they borrowed themselves.
+from_limit = from_limit + tonumber(redis.call('GET', from_borrowed_key) or '0')
+local from_current = tonumber(redis.call('GET', from_key) or '0')
+if from_limit - from_current < amount then
+    return {1, from_limit - from_current, 0}
+end
+
+redis.call('INCRBY', from_key, amount)
+if redis.call('PTTL', from_key) == -1 then
+    redis.call('PEXPIRE', from_key, from_ttl_ms)
+end
+
+local borrowed = redis.call('INCRBY', to_borrowed_key, amount)
+if redis.call('PTTL', to_borrowed_key) == -1 then
+    redis.call('PEXPIRE', to_borrowed_key, to_ttl_ms)
+end
+
+redis.call('HINCRBY', loans_key, loan, amount)
+if redis.call('PTTL', loans_key) == -1 then
+    redis.call('PEXPIRE', loans_key, to_ttl_ms)
+end
+
+return {0, from_limit - from_current - amount, to_limit + borrowed}
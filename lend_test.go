@@ -0,0 +1,107 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLendQuota(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits:       map[string]int{"lend": 5},
+		KeyPrefix:    "lend-test:",
+	}, WithQuotaLending())
+	require.NoError(t, err)
+	defer h.Close()
+
+	keys, _ := h.redisClient.Keys(ctx, "lend-test:*").Result()
+	if len(keys) > 0 {
+		h.redisClient.Del(ctx, keys...)
+	}
+
+	require.NoError(t, h.SetUsage(ctx, "lend", "alice", 1))
+	require.NoError(t, h.SetUsage(ctx, "lend", "bob", 5))
+
+	tt := []struct {
+		description    string
+		featureName    string
+		fromUser       string
+		amount         int
+		expectedResult LendResult
+		expectedErr    error
+	}{
+		{
+			description:    "Lending should use the lender's quota and raise the borrower's limit",
+			featureName:    "lend",
+			fromUser:       "alice",
+			amount:         3,
+			expectedResult: LendResult{FromRemaining: 1, ToLimit: 8},
+		},
+		{
+			description:    "Lending more than is left should fail",
+			featureName:    "lend",
+			fromUser:       "alice",
+			amount:         2,
+			expectedResult: LendResult{FromRemaining: 1},
+			expectedErr:    ErrInsufficientQuota,
+		},
+		{
+			description: "A non-positive amount should fail",
+			featureName: "lend",
+			fromUser:    "alice",
+			amount:      0,
+			expectedErr: ErrInvalidAmount,
+		},
+		{
+			description: "An unknown feature should fail",
+			featureName: "feature-notexistent",
+			fromUser:    "alice",
+			amount:      1,
+			expectedErr: ErrUnknownFeature,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			result, err := h.LendQuota(ctx, test.featureName, test.fromUser, "bob", test.amount)
+			require.ErrorIs(t, err, test.expectedErr)
+			require.Equal(t, test.expectedResult, result)
+		})
+	}
+
+	t.Run("The borrower should be able to consume the lent units", func(t *testing.T) {
+		for range 3 {
+			result, err := h.Consume(ctx, "lend", "bob")
+			require.NoError(t, err)
+			require.True(t, result.Allowed)
+			require.Equal(t, 8, result.Limit)
+		}
+
+		result, err := h.Consume(ctx, "lend", "bob")
+		require.NoError(t, err)
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("The loan should be reported", func(t *testing.T) {
+		loans, err := h.Loans(ctx, "lend")
+		require.NoError(t, err)
+		require.Equal(t, []Loan{{FromUser: "alice", ToUser: "bob", Amount: 3}}, loans)
+	})
+
+	t.Run("Lending should require WithQuotaLending", func(t *testing.T) {
+		plain, err := New(&Config{
+			RedisAddress: "localhost:6379",
+			Limits:       map[string]int{"lend": 5},
+			KeyPrefix:    "lend-test:",
+		})
+		require.NoError(t, err)
+		defer plain.Close()
+
+		_, err = plain.LendQuota(ctx, "lend", "alice", "bob", 1)
+		require.ErrorIs(t, err, ErrLendingDisabled)
+	})
+}
@@ -0,0 +1,16 @@
+package hourglass
+
+import "context"
+
+// Limiter is the consume, read and credit API shared by HourGlass and
+// InMemory, so code can be unit tested against InMemory without Redis.
+type Limiter interface {
+	Consume(ctx context.Context, featureName, userName string, opts ...ConsumeOption) (ConsumeResult, error)
+	Get(ctx context.Context, featureName, userName string) (current int, limit int)
+	Credit(ctx context.Context, featureName, userName string) (current int, limit int)
+}
+
+var (
+	_ Limiter = (*HourGlass)(nil)
+	_ Limiter = (*InMemory)(nil)
+)
@@ -0,0 +1,107 @@
+package hourglass
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// LimitProvider is the source of feature limits. Implementations must be safe
+// for concurrent use; the default provider serves Config.Limits.
+type LimitProvider interface {
+	Limit(featureName string) (limit int, exists bool)
+	Limits() map[string]int
+}
+
+// limitProviderStarter is implemented by providers that need to load their
+// initial state and start background work once the HourGlass is constructed.
+type limitProviderStarter interface {
+	start(ctx context.Context, logger *slog.Logger) error
+}
+
+// configuredLimits returns config.Limits with the limits set in features and
+// then the overrides of config.Environment applied.
+func configuredLimits(config *Config, features map[string]FeatureConfig) (map[string]int, error) {
+	limits := maps.Clone(config.Limits)
+	if limits == nil {
+		limits = map[string]int{}
+	}
+	for featureName, featureConfig := range features {
+		if featureConfig.Limit > 0 {
+			limits[featureName] = featureConfig.Limit
+		}
+	}
+
+	if config.Environment == "" {
+		return limits, nil
+	}
+
+	overrides, exists := config.Environments[config.Environment]
+	if !exists {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEnvironment, config.Environment)
+	}
+	maps.Copy(limits, overrides)
+
+	return limits, nil
+}
+
+type staticLimitProvider struct {
+	limits atomic.Pointer[map[string]int]
+	// mu serializes updates; reads go through limits without locking.
+	mu sync.Mutex
+}
+
+func newStaticLimitProvider(limits map[string]int) *staticLimitProvider {
+	p := &staticLimitProvider{}
+	p.store(limits)
+	return p
+}
+
+func (p *staticLimitProvider) store(limits map[string]int) {
+	limits = maps.Clone(limits)
+	if limits == nil {
+		limits = map[string]int{}
+	}
+	p.limits.Store(&limits)
+}
+
+// set changes the limit of featureName, adding the feature if needed.
+func (p *staticLimitProvider) set(featureName string, limit int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	limits := maps.Clone(*p.limits.Load())
+	limits[featureName] = limit
+	p.limits.Store(&limits)
+}
+
+func (p *staticLimitProvider) Limit(featureName string) (int, bool) {
+	limit, exists := (*p.limits.Load())[featureName]
+	return limit, exists
+}
+
+func (p *staticLimitProvider) Limits() map[string]int {
+	return maps.Clone(*p.limits.Load())
+}
+
+// FeatureEnabled reports whether featureName has a limit configured.
+func (hg *HourGlass) FeatureEnabled(featureName string) bool {
+	_, exists := hg.limitProvider.Limit(featureName)
+	return exists
+}
+
+// MustFeatureEnabled panics if featureName has no limit configured. It is
+// meant for initialization code that depends on a feature being set up.
+func (hg *HourGlass) MustFeatureEnabled(featureName string) {
+	if !hg.FeatureEnabled(featureName) {
+		panic(fmt.Sprintf("hourglass: feature %q is not configured", featureName))
+	}
+}
+
+// Limits returns a snapshot of the current limit of every feature.
+func (hg *HourGlass) Limits() map[string]int {
+	return maps.Clone(hg.limitProvider.Limits())
+}
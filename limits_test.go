@@ -0,0 +1,91 @@
+package hourglass
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvironmentLimits(t *testing.T) {
+	environments := map[string]map[string]int{
+		"dev":  {"feature1": 1000, "debug-feature": 10},
+		"prod": {},
+	}
+
+	tt := []struct {
+		description    string
+		environment    string
+		expectedLimits map[string]int
+		expectedErr    error
+	}{
+		{
+			description:    "Without an environment the base limits should be used",
+			environment:    "",
+			expectedLimits: map[string]int{"feature1": 5, "feature2": 3},
+		},
+		{
+			description:    "Environment limits should override and extend the base limits",
+			environment:    "dev",
+			expectedLimits: map[string]int{"feature1": 1000, "feature2": 3, "debug-feature": 10},
+		},
+		{
+			description:    "An environment without overrides should use the base limits",
+			environment:    "prod",
+			expectedLimits: map[string]int{"feature1": 5, "feature2": 3},
+		},
+		{
+			description: "An unknown environment should return an error",
+			environment: "staging",
+			expectedErr: ErrUnknownEnvironment,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			h, err := New(&Config{
+				RedisAddress:  "localhost:6379",
+				RedisPassword: "",
+				Limits:        map[string]int{"feature1": 5, "feature2": 3},
+				Environments:  environments,
+				Environment:   tc.environment,
+			})
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			require.Nil(t, err)
+			defer h.Close()
+
+			require.Equal(t, tc.expectedLimits, h.limitProvider.Limits())
+			require.Equal(t, tc.expectedLimits, h.Limits())
+		})
+	}
+}
+
+func TestFeatureEnabled(t *testing.T) {
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+			"disabled": 0,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	t.Run("Configured features should be enabled", func(t *testing.T) {
+		require.True(t, h.FeatureEnabled("feature1"))
+		require.True(t, h.FeatureEnabled("disabled"))
+		require.NotPanics(t, func() { h.MustFeatureEnabled("feature1") })
+	})
+
+	t.Run("Unknown features should not be enabled", func(t *testing.T) {
+		require.False(t, h.FeatureEnabled("feature-notexistent"))
+		require.PanicsWithValue(t, `hourglass: feature "feature-notexistent" is not configured`, func() {
+			h.MustFeatureEnabled("feature-notexistent")
+		})
+	})
+}
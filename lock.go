@@ -0,0 +1,54 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+	"time"
+)
+
+//go:embed unlock.lua
+var unlockScriptData string
+
+const lockRetryInterval = 10 * time.Millisecond
+
+// ConsumeWithLock acquires a per feature/user mutex before consuming quota so
+// that callers can safely consume, do work and credit back on failure without
+// racing other holders. The returned unlock func must be called once the work
+// is done; the lock expires on its own after lockTTL.
+func (hg *HourGlass) ConsumeWithLock(ctx context.Context, featureName, userName string, lockTTL time.Duration) (ConsumeResult, func(), error) {
+	key, _, _ := hg.lookup(ctx, featureName, userName)
+	lockKey := key + ":lock"
+
+	token, err := newToken()
+	if err != nil {
+		return ConsumeResult{}, nil, err
+	}
+
+	for {
+		acquired, err := hg.redisClient.SetNX(ctx, lockKey, token, lockTTL).Result()
+		if err != nil {
+			return ConsumeResult{}, nil, err
+		}
+		if acquired {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ConsumeResult{}, nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+
+	unlock := func() {
+		hg.unlockScript.Run(context.Background(), hg.redisClient, []string{lockKey}, token)
+	}
+
+	result, err := hg.consume(ctx, featureName, userName)
+	if err != nil {
+		unlock()
+		return result, nil, err
+	}
+
+	return result, unlock, nil
+}
@@ -0,0 +1,52 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeWithLock(t *testing.T) {
+	limits := map[string]int{
+		"feature1": 5,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Set(ctx, dailyKey("feature1", "locker"), 1, 1*time.Minute)
+
+	result, unlock, err := h.ConsumeWithLock(ctx, "feature1", "locker", 1*time.Minute)
+	require.Nil(t, err)
+	require.True(t, result.Allowed)
+	require.Equal(t, 2, result.Current)
+	require.Equal(t, 3, result.Remaining)
+
+	t.Run("While the lock is held, a second caller should wait until its context is done", func(t *testing.T) {
+		waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		_, _, err := h.ConsumeWithLock(waitCtx, "feature1", "locker", 1*time.Minute)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	unlock()
+
+	t.Run("Once unlocked, the lock should be acquired again", func(t *testing.T) {
+		result, unlock, err := h.ConsumeWithLock(ctx, "feature1", "locker", 1*time.Minute)
+		require.Nil(t, err)
+		defer unlock()
+
+		require.Equal(t, 3, result.Current)
+	})
+}
@@ -0,0 +1,67 @@
+package hourglass
+
+import (
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// metrics holds the Prometheus metrics of one HourGlass. Each instance has
+// its own registry, so several instances in one process do not collide.
+type metrics struct {
+	registry *prometheus.Registry
+	consumes *prometheus.CounterVec
+	credits  *prometheus.CounterVec
+	// shadowDenials counts consumes that were denied but allowed because
+	// their feature is in shadow mode.
+	shadowDenials *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		consumes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hourglass_consumes_total",
+			Help: "Consumes by feature and whether they were allowed.",
+		}, []string{"feature", "result"}),
+		credits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hourglass_credits_total",
+			Help: "Units credited back by feature.",
+		}, []string{"feature"}),
+		shadowDenials: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hourglass_shadow_denials_total",
+			Help: "Consumes allowed by shadow mode that would have been denied, by feature.",
+		}, []string{"feature"}),
+	}
+	m.registry.MustRegister(m.consumes, m.credits, m.shadowDenials)
+
+	return m
+}
+
+func (m *metrics) observeConsume(featureName string, result ConsumeResult) {
+	outcome := "denied"
+	if result.Allowed {
+		outcome = "allowed"
+	}
+	m.consumes.WithLabelValues(featureName, outcome).Inc()
+}
+
+// ExportMetricsText writes the Prometheus metrics of hg in the text
+// exposition format, so callers can include them in their own /metrics
+// endpoint without running a separate HTTP server.
+func (hg *HourGlass) ExportMetricsText(w io.Writer) error {
+	families, err := hg.metrics.registry.Gather()
+	if err != nil {
+		return err
+	}
+
+	encoder := expfmt.NewEncoder(w, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, family := range families {
+		if err := encoder.Encode(family); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package hourglass
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportMetricsText(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "metrics-user"))
+
+	h.Consume(ctx, "feature1", "metrics-user")
+	h.Consume(ctx, "feature1", "metrics-user")
+	h.Consume(ctx, "feature-notexistent", "metrics-user")
+	h.Credit(ctx, "feature1", "metrics-user")
+
+	var buf bytes.Buffer
+	require.Nil(t, h.ExportMetricsText(&buf))
+	text := buf.String()
+
+	tt := []struct {
+		description string
+		expected    string
+	}{
+		{
+			description: "Allowed consumes should be counted",
+			expected:    `hourglass_consumes_total{feature="feature1",result="allowed"} 1`,
+		},
+		{
+			description: "Denied consumes should be counted",
+			expected:    `hourglass_consumes_total{feature="feature1",result="denied"} 1`,
+		},
+		{
+			description: "Credits should be counted",
+			expected:    `hourglass_credits_total{feature="feature1"} 1`,
+		},
+		{
+			description: "Metrics should carry their help text",
+			expected:    "# HELP hourglass_consumes_total",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Contains(t, text, tc.expected)
+		})
+	}
+
+	t.Run("Unknown features should not be counted", func(t *testing.T) {
+		require.NotContains(t, text, "feature-notexistent")
+	})
+}
@@ -0,0 +1,49 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+)
+
+// IdentityFunc returns the user a request is made by.
+type IdentityFunc func(r *http.Request) string
+
+// Middleware consumes one unit of featureName for the user returned by
+// identity before passing the request on. Denied requests get 429, or 403
+// for blacklisted users, and requests without a user get 401. Allowed
+// requests carry the feature and user via WithFeatureContext and the result
+// via ConsumeResultFromContext, and every response reports the usage in
+// X-RateLimit-* headers.
+func (hg *HourGlass) Middleware(featureName string, identity IdentityFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userName := identity(r)
+			if userName == "" {
+				http.Error(w, "unknown user", http.StatusUnauthorized)
+				return
+			}
+
+			result, err := hg.consume(r.Context(), featureName, userName)
+			if err != nil && result.Allowed {
+				hg.logger.WarnContext(r.Context(), "failed to consume, failing open", "feature", featureName, "user", userName, "error", err)
+			}
+
+			maps.Copy(w.Header(), RateLimitHeaders(result))
+
+			switch {
+			case errors.Is(err, ErrUserBlacklisted):
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			case !result.Allowed:
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+
+			ctx := WithFeatureContext(r.Context(), featureName, userName)
+			ctx = context.WithValue(ctx, consumeResultContextKey, result)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
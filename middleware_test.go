@@ -0,0 +1,97 @@
+package hourglass
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+		Blacklist: []string{"middleware-blocked"},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "middleware-user"))
+
+	identity := func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /items/{id}", h.Middleware("feature1", identity)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, ok := ConsumeResultFromContext(r.Context())
+		require.True(t, ok)
+		require.Equal(t, 1, result.Current)
+
+		fc, ok := r.Context().Value(featureContextKey).(featureContext)
+		require.True(t, ok)
+		require.Equal(t, "middleware-user", fc.userName)
+
+		w.Write([]byte(r.PathValue("id")))
+	})))
+
+	tt := []struct {
+		description       string
+		user              string
+		expectedStatus    int
+		expectedRemaining string
+		expectRetryAfter  bool
+	}{
+		{
+			description:       "A request within the limit should reach the handler",
+			user:              "middleware-user",
+			expectedStatus:    http.StatusOK,
+			expectedRemaining: "0",
+		},
+		{
+			description:       "A request over the limit should be rejected with 429",
+			user:              "middleware-user",
+			expectedStatus:    http.StatusTooManyRequests,
+			expectedRemaining: "0",
+			expectRetryAfter:  true,
+		},
+		{
+			description:    "A request without a user should be rejected with 401",
+			user:           "",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			description:       "A request by a blacklisted user should be rejected with 403",
+			user:              "middleware-blocked",
+			expectedStatus:    http.StatusForbidden,
+			expectedRemaining: "0",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+			req.Header.Set("X-User", tc.user)
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expectedStatus, rec.Code)
+			require.Equal(t, tc.expectedRemaining, rec.Header().Get("X-RateLimit-Remaining"))
+			require.Equal(t, tc.expectRetryAfter, rec.Header().Get("Retry-After") != "")
+			if tc.expectedStatus == http.StatusOK {
+				require.Equal(t, "1", rec.Header().Get("X-RateLimit-Limit"))
+				require.NotEmpty(t, rec.Header().Get("X-RateLimit-Reset"))
+				require.Equal(t, "42", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package hourglass
+
+import (
+	"context"
+	"strings"
+)
+
+var globReplacer = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// MigrateKeys renames every counter key of the configured features that
+// starts with oldPrefix so that it starts with newPrefix instead. With
+// WithClusterSafeHashTag it also adds the hash tag to keys that lack it, so
+// oldPrefix and newPrefix may be the same. When dryRun
+// is true the keys are only logged. It returns the number of keys migrated (or
+// that would have been migrated).
+func (hg *HourGlass) MigrateKeys(ctx context.Context, oldPrefix, newPrefix string, dryRun bool) (int64, error) {
+	var migrated int64
+
+	for featureName := range hg.limitProvider.Limits() {
+		pattern := globReplacer.Replace(oldPrefix+featureName+":") + "*"
+
+		iter := hg.redisClient.Scan(ctx, 0, pattern, 100).Iterator()
+		for iter.Next(ctx) {
+			oldKey := iter.Val()
+			newKey := newPrefix + hg.tagKeyUser(featureName, strings.TrimPrefix(oldKey, oldPrefix))
+			if newKey == oldKey {
+				continue
+			}
+
+			if dryRun {
+				hg.logger.InfoContext(ctx, "would migrate key", "from", oldKey, "to", newKey)
+				migrated++
+				continue
+			}
+
+			if err := hg.redisClient.Rename(ctx, oldKey, newKey).Err(); err != nil {
+				return migrated, err
+			}
+			hg.logger.InfoContext(ctx, "migrated key", "from", oldKey, "to", newKey)
+			migrated++
+		}
+		if err := iter.Err(); err != nil {
+			return migrated, err
+		}
+	}
+
+	return migrated, nil
+}
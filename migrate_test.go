@@ -0,0 +1,71 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateKeys(t *testing.T) {
+	limits := map[string]int{
+		"migrate1": 5,
+		"migrate2": 3,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	oldKeys := []string{
+		dailyKey("migrate1", "alice"),
+		dailyKey("migrate1", "bob"),
+		dailyKey("migrate2", "alice"),
+	}
+	for _, key := range oldKeys {
+		h.redisClient.Set(ctx, key, 2, 1*time.Minute)
+	}
+
+	t.Run("A dry run should count keys without renaming them", func(t *testing.T) {
+		migrated, err := h.MigrateKeys(ctx, "", "app:", true)
+		require.Nil(t, err)
+		require.Equal(t, int64(3), migrated)
+
+		exists, err := h.redisClient.Exists(ctx, oldKeys...).Result()
+		require.Nil(t, err)
+		require.Equal(t, int64(3), exists)
+	})
+
+	t.Run("A real run should rename keys to the new prefix", func(t *testing.T) {
+		migrated, err := h.MigrateKeys(ctx, "", "app:", false)
+		require.Nil(t, err)
+		require.Equal(t, int64(3), migrated)
+
+		for _, key := range oldKeys {
+			value, err := h.redisClient.Get(ctx, "app:"+key).Int()
+			require.Nil(t, err)
+			require.Equal(t, 2, value)
+		}
+
+		exists, err := h.redisClient.Exists(ctx, oldKeys...).Result()
+		require.Nil(t, err)
+		require.Equal(t, int64(0), exists)
+	})
+
+	t.Run("Migrating back should restore the original keys", func(t *testing.T) {
+		migrated, err := h.MigrateKeys(ctx, "app:", "", false)
+		require.Nil(t, err)
+		require.Equal(t, int64(3), migrated)
+
+		current, _ := h.Get(ctx, "migrate1", "alice")
+		require.Equal(t, 2, current)
+	})
+}
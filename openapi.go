@@ -0,0 +1,61 @@
+package hourglass
+
+import "maps"
+
+// openAPIExtension is the name of the OpenAPI specification extension
+// produced by GenerateOpenAPIExtension.
+const openAPIExtension = "x-rate-limits"
+
+// GenerateOpenAPIExtension describes the limits of config as an OpenAPI 3.x
+// x-rate-limits extension, ready to be merged into a spec. Each feature lists
+// its limit, its window in seconds, its burst allowance and the responses
+// and headers Middleware uses for it. config is expected to be valid for New;
+// limit expressions that do not parse and an unknown environment are left
+// out.
+func GenerateOpenAPIExtension(config *Config) map[string]interface{} {
+	features := maps.Clone(config.Features)
+	if features == nil {
+		features = map[string]FeatureConfig{}
+	}
+	for featureName, expr := range config.LimitExpressions {
+		if parsed, err := ParseLimitExpr(expr); err == nil {
+			featureConfig := features[featureName]
+			featureConfig.Limit = parsed.Limit
+			featureConfig.Window = parsed.Window
+			features[featureName] = featureConfig
+		}
+	}
+
+	limits, err := configuredLimits(config, features)
+	if err != nil {
+		limits = config.Limits
+	}
+
+	policies := make(map[string]interface{}, len(limits))
+	for featureName, limit := range limits {
+		window := features[featureName].Window
+		if window <= 0 {
+			window = day
+		}
+
+		policy := map[string]interface{}{
+			"limit":         limit,
+			"windowSeconds": int(window.Seconds()),
+			"responses": map[string]interface{}{
+				"401": "Request has no user",
+				"403": "User is blacklisted",
+				"429": "Rate limit exceeded",
+			},
+			"headers": []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After"},
+		}
+		if burst := features[featureName].BurstAllowance; burst > 0 {
+			policy["burstAllowance"] = burst
+		}
+		if perMinute := features[featureName].MaxBurstPerMinute; perMinute > 0 {
+			policy["maxPerMinute"] = perMinute
+		}
+		policies[featureName] = policy
+	}
+
+	return map[string]interface{}{openAPIExtension: policies}
+}
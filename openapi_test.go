@@ -0,0 +1,74 @@
+package hourglass
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateOpenAPIExtension(t *testing.T) {
+	extension := GenerateOpenAPIExtension(&Config{
+		Limits: map[string]int{
+			"feature1": 100,
+			"feature2": 5,
+		},
+		Features: map[string]FeatureConfig{
+			"feature2": {Window: time.Hour, BurstAllowance: 2},
+		},
+		LimitExpressions: map[string]string{
+			"feature3": "10/min",
+			"feature4": "not an expression",
+		},
+	})
+
+	policies := extension["x-rate-limits"].(map[string]interface{})
+
+	tt := []struct {
+		description    string
+		featureName    string
+		expectedLimit  int
+		expectedWindow int
+		expectedBurst  interface{}
+	}{
+		{
+			description:    "A feature with only a limit should get the daily window",
+			featureName:    "feature1",
+			expectedLimit:  100,
+			expectedWindow: 86400,
+		},
+		{
+			description:    "A feature with settings should describe its window and burst allowance",
+			featureName:    "feature2",
+			expectedLimit:  5,
+			expectedWindow: 3600,
+			expectedBurst:  2,
+		},
+		{
+			description:    "A limit expression should describe its limit and window",
+			featureName:    "feature3",
+			expectedLimit:  10,
+			expectedWindow: 60,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			policy := policies[tc.featureName].(map[string]interface{})
+			require.Equal(t, tc.expectedLimit, policy["limit"])
+			require.Equal(t, tc.expectedWindow, policy["windowSeconds"])
+			require.Equal(t, tc.expectedBurst, policy["burstAllowance"])
+			require.Contains(t, policy["responses"], "429")
+		})
+	}
+
+	t.Run("An invalid limit expression should be left out", func(t *testing.T) {
+		require.NotContains(t, policies, "feature4")
+	})
+
+	t.Run("The extension should encode as JSON", func(t *testing.T) {
+		_, err := json.Marshal(extension)
+		require.Nil(t, err)
+	})
+}
@@ -0,0 +1,162 @@
+package hourglass
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Option func(*HourGlass)
+
+// WithConsumeScript replaces the embedded consume.lua with custom Lua source.
+//
+// The script is called with KEYS[1] set to the counter key, KEYS[2] to the
+// burst allowance key, ARGV[1] to the feature limit, ARGV[2] to the TTL in
+// milliseconds for a newly created key, at least 1, and ARGV[3] to the burst
+// allowance. It must return an array of {current, limit, allowed} where
+// allowed is 1 when the consume succeeded and 0 otherwise. An optional fourth
+// element of 1 marks a consume allowed by the burst allowance.
+func WithConsumeScript(script string) Option {
+	return func(hg *HourGlass) {
+		hg.consumeScriptSource = script
+	}
+}
+
+// ConsumeScriptSource returns the embedded consume.lua, for debugging and as
+// a starting point for WithConsumeScript.
+func ConsumeScriptSource() string {
+	return consumeScriptData
+}
+
+// ConsumeScriptSHA returns the SHA1 of the embedded consume.lua as used by
+// EVALSHA, so callers can check it is loaded with SCRIPT EXISTS.
+func ConsumeScriptSHA() string {
+	sum := sha1.Sum([]byte(consumeScriptData))
+	return hex.EncodeToString(sum[:])
+}
+
+// WithLogger sets the logger used for operational messages. Defaults to
+// slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(hg *HourGlass) {
+		hg.logger = logger
+	}
+}
+
+// WithTimeSeries records the time of every successful Consume in a sorted set
+// so usage can be queried with QueryTimeSeries. Events older than retention
+// are trimmed on write.
+func WithTimeSeries(retention time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.timeSeriesRetention = retention
+	}
+}
+
+// WithLimitProvider replaces Config.Limits as the source of feature limits.
+func WithLimitProvider(provider LimitProvider) Option {
+	return func(hg *HourGlass) {
+		hg.limitProvider = provider
+	}
+}
+
+// FailureMode decides whether Consume allows or denies calls it cannot check
+// because Redis fails.
+type FailureMode int
+
+const (
+	// FailOpen allows calls while Redis is unavailable. It is the default.
+	FailOpen FailureMode = iota
+	// FailClosed denies calls while Redis is unavailable.
+	FailClosed
+)
+
+// WithFailureMode sets how Consume treats calls when Redis fails or the
+// circuit breaker is open.
+func WithFailureMode(mode FailureMode) Option {
+	return func(hg *HourGlass) {
+		hg.failureMode = mode
+	}
+}
+
+// WithScriptResponseHook calls fn with the raw reply of the consume script
+// before it is parsed, for scripts that return extra fields. fn can log,
+// validate or transform the reply; it must return at least the
+// {current, limit, allowed} elements described at WithConsumeScript. An
+// error from fn fails the consume, which is then answered by the failure
+// mode.
+func WithScriptResponseHook(fn func(raw []interface{}) ([]interface{}, error)) Option {
+	return func(hg *HourGlass) {
+		hg.scriptResponseHook = fn
+	}
+}
+
+// WithShadowMode runs Consume for featureNames as usual but never denies a
+// call because of a limit, for analysing traffic before enforcement goes
+// live. Counters, cooldowns and limit exceeded events behave as without
+// shadow mode. Calls that would have been denied are logged as warnings and
+// counted in hourglass_shadow_denials_total.
+func WithShadowMode(featureNames ...string) Option {
+	return func(hg *HourGlass) {
+		if hg.shadowFeatures == nil {
+			hg.shadowFeatures = map[string]bool{}
+		}
+		for _, featureName := range featureNames {
+			hg.shadowFeatures[featureName] = true
+		}
+	}
+}
+
+// WithRecoverFromPanic controls whether a consume script reply of the wrong
+// shape, such as a string instead of an array, fails the consume with
+// ErrUnexpectedRedisResponse or panics. It is on by default; the reply is
+// logged at debug level.
+func WithRecoverFromPanic(enabled bool) Option {
+	return func(hg *HourGlass) {
+		hg.recoverFromPanic = enabled
+	}
+}
+
+// WithOnConnect calls fn every time a new Redis connection is established,
+// for example to run CLIENT SETNAME or log INFO output. It runs in the
+// connection path of whichever command needed the connection and should
+// return quickly; an error fails the connection. Like the timeout options it
+// only applies to New, since NewFromPool reuses an existing pool.
+func WithOnConnect(fn func(ctx context.Context, conn *redis.Conn) error) Option {
+	return func(hg *HourGlass) {
+		hg.onConnect = fn
+	}
+}
+
+// ConsumeOption customizes a single Consume call.
+type ConsumeOption func(*consumeOptions)
+
+type consumeOptions struct {
+	metadata       map[string]string
+	ttl            time.Duration
+	idempotencyKey string
+	idempotencyTTL time.Duration
+}
+
+func newConsumeOptions(opts []ConsumeOption) consumeOptions {
+	var options consumeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithMetadata attaches a key/value pair, e.g. a request ID, to the consume.
+// Metadata is passed to limit exceeded events and audit entries and does not
+// affect rate limiting.
+func WithMetadata(key, value string) ConsumeOption {
+	return func(options *consumeOptions) {
+		if options.metadata == nil {
+			options.metadata = map[string]string{}
+		}
+		options.metadata[key] = value
+	}
+}
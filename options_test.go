@@ -0,0 +1,365 @@
+package hourglass
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithConsumeScript(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("A custom script should replace the embedded consume logic", func(t *testing.T) {
+		h, err := New(&Config{
+			RedisAddress: "localhost:6379",
+			Limits: map[string]int{
+				"feature1": 5,
+			},
+		}, WithConsumeScript(`return {42, tonumber(ARGV[1]), 0}`))
+
+		require.Nil(t, err)
+		defer h.Close()
+
+		result, _ := h.Consume(ctx, "feature1", "custom-script")
+		require.Equal(t, 42, result.Current)
+		require.Equal(t, 5, result.Limit)
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("An empty script should be rejected", func(t *testing.T) {
+		_, err := New(&Config{
+			RedisAddress: "localhost:6379",
+			Limits:       map[string]int{},
+		}, WithConsumeScript("  \n"))
+
+		require.Equal(t, ErrEmptyConsumeScript, err)
+	})
+}
+
+func TestFeatureScripts(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits: map[string]int{
+			"feature1": 5,
+			"feature2": 5,
+		},
+		FeatureScripts: map[string]string{
+			"feature1": `return {42, tonumber(ARGV[1]), 0}`,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature2", "feature-script"))
+
+	t.Run("A feature with its own script should use it", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "feature-script")
+		require.Equal(t, 42, result.Current)
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("Other features should keep the default script", func(t *testing.T) {
+		result, err := h.Consume(ctx, "feature2", "feature-script")
+		require.Nil(t, err)
+		require.Equal(t, 1, result.Current)
+		require.True(t, result.Allowed)
+	})
+
+	t.Run("An empty script should be rejected", func(t *testing.T) {
+		_, err := New(&Config{
+			RedisAddress:   "localhost:6379",
+			Limits:         map[string]int{"feature1": 5},
+			FeatureScripts: map[string]string{"feature1": ""},
+		})
+		require.ErrorIs(t, err, ErrEmptyConsumeScript)
+	})
+}
+
+func TestWithScriptResponseHook(t *testing.T) {
+	ctx := context.Background()
+
+	var slot any
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+	}, WithConsumeScript(`return {5, tonumber(ARGV[1]), 0, 0, "slot-3"}`),
+		WithScriptResponseHook(func(raw []interface{}) ([]interface{}, error) {
+			if len(raw) > 4 {
+				slot = raw[4]
+			}
+			if raw[0].(int64) > 4 {
+				return raw, errors.New("bad reply")
+			}
+			return raw[:2], nil
+		}))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	t.Run("The hook should see the raw reply and its error should fail the consume", func(t *testing.T) {
+		result, err := h.Consume(ctx, "feature1", "hook-user")
+		require.EqualError(t, err, "bad reply")
+		require.Equal(t, "slot-3", slot)
+		require.Equal(t, -1, result.Current)
+		require.Equal(t, 5, result.Limit)
+	})
+
+	t.Run("A reply too short to parse should fail the consume", func(t *testing.T) {
+		h, err := New(&Config{
+			RedisAddress: "localhost:6379",
+			Limits:       map[string]int{"feature1": 5},
+		}, WithScriptResponseHook(func(raw []interface{}) ([]interface{}, error) {
+			return raw[:2], nil
+		}))
+		require.Nil(t, err)
+		defer h.Close()
+
+		_, err = h.Consume(ctx, "feature1", "hook-user")
+		require.ErrorIs(t, err, ErrInvalidScriptResponse)
+	})
+
+	t.Run("The hook should be able to transform the reply", func(t *testing.T) {
+		h, err := New(&Config{
+			RedisAddress: "localhost:6379",
+			Limits:       map[string]int{"feature1": 5},
+		}, WithConsumeScript(`return {1, tonumber(ARGV[1]), 0}`),
+			WithScriptResponseHook(func(raw []interface{}) ([]interface{}, error) {
+				return []interface{}{raw[0], raw[1], int64(1)}, nil
+			}))
+		require.Nil(t, err)
+		defer h.Close()
+
+		result, err := h.Consume(ctx, "feature1", "hook-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+	})
+}
+
+func TestWithShadowMode(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits: map[string]int{
+			"feature1": 1,
+			"feature2": 1,
+		},
+	}, WithShadowMode("feature1"))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "shadow-user"), dailyKey("feature2", "shadow-user"))
+
+	tt := []struct {
+		description     string
+		featureName     string
+		expectedCurrent int
+		expectedAllowed bool
+	}{
+		{
+			description:     "A consume within the limit should be allowed",
+			featureName:     "feature1",
+			expectedCurrent: 1,
+			expectedAllowed: true,
+		},
+		{
+			description:     "A consume over the limit of a shadowed feature should be allowed",
+			featureName:     "feature1",
+			expectedCurrent: 1,
+			expectedAllowed: true,
+		},
+		{
+			description:     "A consume within the limit of another feature should be allowed",
+			featureName:     "feature2",
+			expectedCurrent: 1,
+			expectedAllowed: true,
+		},
+		{
+			description:     "A consume over the limit of another feature should be denied",
+			featureName:     "feature2",
+			expectedCurrent: 1,
+			expectedAllowed: false,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			result, err := h.Consume(ctx, tc.featureName, "shadow-user")
+			require.Nil(t, err)
+			require.Equal(t, tc.expectedCurrent, result.Current)
+			require.Equal(t, tc.expectedAllowed, result.Allowed)
+		})
+	}
+
+	t.Run("Shadow decisions should be counted", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.Nil(t, h.ExportMetricsText(&buf))
+		require.Contains(t, buf.String(), `hourglass_shadow_denials_total{feature="feature1"} 1`)
+		require.NotContains(t, buf.String(), `hourglass_shadow_denials_total{feature="feature2"}`)
+	})
+}
+
+func TestWithRecoverFromPanic(t *testing.T) {
+	ctx := context.Background()
+
+	tt := []struct {
+		description string
+		script      string
+		recover     bool
+		expectPanic bool
+	}{
+		{
+			description: "A reply that is not an array should fail the consume",
+			script:      `return 'not an array'`,
+			recover:     true,
+		},
+		{
+			description: "A reply with elements of the wrong type should fail the consume",
+			script:      `return {'1', '5', '1'}`,
+			recover:     true,
+		},
+		{
+			description: "A bad reply should panic when recovery is off",
+			script:      `return 'not an array'`,
+			expectPanic: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			h, err := New(&Config{
+				RedisAddress: "localhost:6379",
+				Limits: map[string]int{
+					"feature1": 5,
+				},
+			}, WithConsumeScript(tc.script), WithRecoverFromPanic(tc.recover))
+
+			require.Nil(t, err)
+			defer h.Close()
+
+			if tc.expectPanic {
+				require.Panics(t, func() { h.Consume(ctx, "feature1", "panic-user") })
+				return
+			}
+
+			result, err := h.Consume(ctx, "feature1", "panic-user")
+			require.ErrorIs(t, err, ErrUnexpectedRedisResponse)
+			require.Equal(t, -1, result.Current)
+			require.Equal(t, 5, result.Limit)
+		})
+	}
+}
+
+func TestWithOnConnect(t *testing.T) {
+	ctx := context.Background()
+
+	var connects atomic.Int32
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+	}, WithOnConnect(func(ctx context.Context, conn *redis.Conn) error {
+		connects.Add(1)
+		return conn.ClientSetName(ctx, "hourglass-test").Err()
+	}))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	t.Run("New connections should run the callback", func(t *testing.T) {
+		require.Greater(t, connects.Load(), int32(0))
+
+		name, err := h.redisClient.ClientGetName(ctx).Result()
+		require.Nil(t, err)
+		require.Equal(t, "hourglass-test", name)
+	})
+
+	t.Run("A failing callback should fail the connection", func(t *testing.T) {
+		_, err := New(&Config{
+			RedisAddress:  "localhost:6379",
+			RedisPassword: "",
+		}, WithOnConnect(func(ctx context.Context, conn *redis.Conn) error {
+			return errors.New("refused")
+		}))
+		require.Error(t, err)
+	})
+}
+
+func TestConsumeScriptSource(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	t.Run("The source should be the embedded script", func(t *testing.T) {
+		require.Equal(t, consumeScriptData, ConsumeScriptSource())
+		require.Contains(t, ConsumeScriptSource(), "redis.call")
+	})
+
+	t.Run("The SHA should match the loaded script", func(t *testing.T) {
+		require.Equal(t, h.consumeScript.Hash(), ConsumeScriptSHA())
+
+		_, err := h.Consume(ctx, "feature1", "script-user")
+		require.Nil(t, err)
+
+		exists, err := h.redisClient.ScriptExists(ctx, ConsumeScriptSHA()).Result()
+		require.Nil(t, err)
+		require.Equal(t, []bool{true}, exists)
+	})
+}
+
+func TestInvalidIntervals(t *testing.T) {
+	client, err := api.NewClient(api.DefaultConfig())
+	require.Nil(t, err)
+
+	tt := []struct {
+		description string
+		opt         Option
+	}{
+		{
+			description: "A janitor without an interval should be rejected",
+			opt:         WithJanitor(0),
+		},
+		{
+			description: "A local buffer without a flush interval should be rejected",
+			opt:         WithLocalBuffer(10, 0),
+		},
+		{
+			description: "A Consul provider with a negative poll interval should be rejected",
+			opt:         WithConsulLimitProvider(client, "hourglass/limits", -time.Second),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := New(&Config{
+				RedisAddress: "localhost:6379",
+				Limits:       map[string]int{"feature1": 5},
+			}, tc.opt)
+			require.ErrorIs(t, err, ErrInvalidInterval)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package hourglass
+
+import (
+	"context"
+	"time"
+)
+
+// WithPausing enables PauseRateLimiting. Consume then checks whether the
+// user is paused, which costs one EXISTS per call.
+func WithPausing() Option {
+	return func(hg *HourGlass) {
+		hg.pausing = true
+	}
+}
+
+// pauseKey returns the key that marks rate limiting of userName as paused.
+func (hg *HourGlass) pauseKey(ctx context.Context, userName string) string {
+	return hg.keyPrefix(ctx) + "paused:" + hg.keyUser(userName)
+}
+
+// PauseRateLimiting lets userName consume every feature freely for duration,
+// for example during account migrations. Consumes while paused are allowed
+// without touching the counters. duration must be positive, so that a pause
+// always ends. It requires WithPausing.
+func (hg *HourGlass) PauseRateLimiting(ctx context.Context, userName string, duration time.Duration) error {
+	if !hg.pausing {
+		return ErrPausingDisabled
+	}
+	if duration <= 0 {
+		return ErrInvalidTTL
+	}
+	if err := hg.ensureConnected(ctx); err != nil {
+		return err
+	}
+
+	return hg.redisClient.Set(ctx, hg.pauseKey(ctx, userName), 1, duration).Err()
+}
+
+// ResumeRateLimiting ends a pause started with PauseRateLimiting early.
+func (hg *HourGlass) ResumeRateLimiting(ctx context.Context, userName string) error {
+	if !hg.pausing {
+		return ErrPausingDisabled
+	}
+	if err := hg.ensureConnected(ctx); err != nil {
+		return err
+	}
+
+	return hg.redisClient.Del(ctx, hg.pauseKey(ctx, userName)).Err()
+}
+
+func (hg *HourGlass) isPaused(ctx context.Context, userName string) (bool, error) {
+	n, err := hg.redisClient.Exists(ctx, hg.pauseKey(ctx, userName)).Result()
+	return n > 0, err
+}
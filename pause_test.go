@@ -0,0 +1,87 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseRateLimiting(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+	}, WithPausing())
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "pause-user"), h.pauseKey(ctx, "pause-user"))
+
+	result, _ := h.Consume(ctx, "feature1", "pause-user")
+	require.True(t, result.Allowed)
+
+	t.Run("A paused user should consume without touching the counter", func(t *testing.T) {
+		require.Nil(t, h.PauseRateLimiting(ctx, "pause-user", time.Hour))
+		require.InDelta(t, time.Hour.Seconds(), h.redisClient.TTL(ctx, h.pauseKey(ctx, "pause-user")).Val().Seconds(), 2)
+
+		for i := 0; i < 3; i++ {
+			result, _ := h.Consume(ctx, "feature1", "pause-user")
+			require.True(t, result.Allowed)
+		}
+
+		current, _ := h.Get(ctx, "feature1", "pause-user")
+		require.Equal(t, 1, current)
+	})
+
+	t.Run("Resuming should apply the limit again", func(t *testing.T) {
+		require.Nil(t, h.ResumeRateLimiting(ctx, "pause-user"))
+
+		result, _ := h.Consume(ctx, "feature1", "pause-user")
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("A pause without an expiry should be rejected", func(t *testing.T) {
+		require.ErrorIs(t, h.PauseRateLimiting(ctx, "pause-user", 0), ErrInvalidTTL)
+		require.ErrorIs(t, h.PauseRateLimiting(ctx, "pause-user", -time.Second), ErrInvalidTTL)
+		require.Zero(t, h.redisClient.Exists(ctx, h.pauseKey(ctx, "pause-user")).Val())
+	})
+}
+
+func TestPauseRateLimitingDisabled(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "unpaused-user"))
+	h.redisClient.Set(ctx, h.pauseKey(ctx, "unpaused-user"), 1, time.Hour)
+	defer h.redisClient.Del(ctx, h.pauseKey(ctx, "unpaused-user"))
+
+	t.Run("Pausing should require WithPausing", func(t *testing.T) {
+		require.ErrorIs(t, h.PauseRateLimiting(ctx, "unpaused-user", time.Hour), ErrPausingDisabled)
+		require.ErrorIs(t, h.ResumeRateLimiting(ctx, "unpaused-user"), ErrPausingDisabled)
+	})
+
+	t.Run("Consume should not check pauses without WithPausing", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "unpaused-user")
+		require.True(t, result.Allowed)
+
+		result, _ = h.Consume(ctx, "feature1", "unpaused-user")
+		require.False(t, result.Allowed)
+	})
+}
@@ -0,0 +1,191 @@
+package hourglass
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisPool is a Redis connection pool that can be shared by several HourGlass
+// instances created with NewFromPool.
+type RedisPool struct {
+	client           *redis.Client
+	readClient       *redis.Client
+	consumeScript    *redis.Script
+	transferScript   *redis.Script
+	unlockScript     *redis.Script
+	flushScript      *redis.Script
+	creditScript     *redis.Script
+	burstRateScript  *redis.Script
+	sharePoolScript  *redis.Script
+	bankScript       *redis.Script
+	rolloverScript   *redis.Script
+	batchScript      *redis.Script
+	leaseScript      *redis.Script
+	throttleScript   *redis.Script
+	lendScript       *redis.Script
+	idempotentScript *redis.Script
+	copyScript       *redis.Script
+
+	// breaker wraps client and readClient once it is installed by the
+	// first instance created with WithCircuitBreaker.
+	breakerMu sync.Mutex
+	breaker   *circuitBreaker
+}
+
+// NewPool connects to Redis using the connection settings of config.
+func NewPool(config *Config) (*RedisPool, error) {
+	return newPool(config, true, nil)
+}
+
+func newPool(config *Config, ping bool, onConnect func(ctx context.Context, conn *redis.Conn) error) (*RedisPool, error) {
+	// Set defaults for connection pooling
+	if config.PoolSize == 0 {
+		config.PoolSize = 10
+	}
+	if config.MinIdleConns == 0 {
+		config.MinIdleConns = 5
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.DialTimeout == 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	if config.ReadTimeout == 0 {
+		config.ReadTimeout = 3 * time.Second
+	}
+	if config.WriteTimeout == 0 {
+		config.WriteTimeout = 3 * time.Second
+	}
+	if config.PoolTimeout == 0 {
+		config.PoolTimeout = 4 * time.Second
+	}
+	if config.IdleTimeout == 0 {
+		config.IdleTimeout = 5 * time.Minute
+	}
+	if config.MaxConnAge == 0 {
+		config.MaxConnAge = 30 * time.Minute
+	}
+
+	// Connect to Redis with optimized connection pool settings
+	options := redisOptions(config, config.RedisAddress)
+	options.OnConnect = onConnect
+	rdb := redis.NewClient(options)
+
+	readRdb := rdb
+	if config.RedisReadAddress != "" {
+		readOptions := redisOptions(config, config.RedisReadAddress)
+		readOptions.OnConnect = onConnect
+		readRdb = redis.NewClient(readOptions)
+	}
+
+	pool := &RedisPool{
+		client:           rdb,
+		readClient:       readRdb,
+		consumeScript:    redis.NewScript(consumeScriptData),
+		transferScript:   redis.NewScript(transferScriptData),
+		unlockScript:     redis.NewScript(unlockScriptData),
+		flushScript:      redis.NewScript(flushScriptData),
+		creditScript:     redis.NewScript(creditScriptData),
+		burstRateScript:  redis.NewScript(burstRateScriptData),
+		sharePoolScript:  redis.NewScript(sharePoolScriptData),
+		bankScript:       redis.NewScript(bankScriptData),
+		rolloverScript:   redis.NewScript(rolloverScriptData),
+		batchScript:      redis.NewScript(batchScriptData),
+		leaseScript:      redis.NewScript(leaseScriptData),
+		throttleScript:   redis.NewScript(throttleScriptData),
+		lendScript:       redis.NewScript(lendScriptData),
+		idempotentScript: redis.NewScript(idempotentScriptData),
+		copyScript:       redis.NewScript(copyScriptData),
+	}
+
+	if ping {
+		for _, client := range []*redis.Client{rdb, readRdb} {
+			_, err := client.Ping(context.Background()).Result()
+			if err != nil {
+				pool.Close()
+				return nil, err
+			}
+		}
+	}
+
+	return pool, nil
+}
+
+func redisOptions(config *Config, address string) *redis.Options {
+	return &redis.Options{
+		Addr:            address,
+		Password:        config.RedisPassword,
+		DB:              0,
+		PoolSize:        config.PoolSize,
+		MinIdleConns:    config.MinIdleConns,
+		MaxRetries:      config.MaxRetries,
+		DialTimeout:     config.DialTimeout,
+		ReadTimeout:     config.ReadTimeout,
+		WriteTimeout:    config.WriteTimeout,
+		PoolTimeout:     config.PoolTimeout,
+		ConnMaxIdleTime: config.IdleTimeout,
+		ConnMaxLifetime: config.MaxConnAge,
+	}
+}
+
+// installBreaker adds b to the pool's clients unless the pool already has a
+// breaker, and returns the breaker in use. Every instance sharing the pool
+// shares it.
+func (p *RedisPool) installBreaker(b *circuitBreaker) *circuitBreaker {
+	p.breakerMu.Lock()
+	defer p.breakerMu.Unlock()
+
+	if p.breaker == nil {
+		p.breaker = b
+		p.client.AddHook(b)
+		if p.readClient != p.client {
+			p.readClient.AddHook(b)
+		}
+	}
+
+	return p.breaker
+}
+
+func (p *RedisPool) Close() error {
+	if p.readClient != p.client {
+		p.readClient.Close()
+	}
+
+	return p.client.Close()
+}
+
+// PoolHealthReport summarizes the state of the Redis connection pool.
+type PoolHealthReport struct {
+	// UtilizationPct is the share of the pool size held by connections in
+	// use.
+	UtilizationPct float64 `json:"utilizationPct"`
+	WaitCount      uint32  `json:"waitCount"`
+	TimeoutCount   uint32  `json:"timeoutCount"`
+	StaleConns     uint32  `json:"staleConns"`
+}
+
+// PoolHealth reports the utilization and contention of the connection pool
+// used for writes.
+func (hg *HourGlass) PoolHealth() PoolHealthReport {
+	stats := hg.redisClient.PoolStats()
+
+	// Idle connections are counted while they are still being dialed, so the
+	// idle count can briefly exceed the total.
+	inUse := max(int(stats.TotalConns)-int(stats.IdleConns), 0)
+
+	var utilization float64
+	if poolSize := hg.redisClient.Options().PoolSize; poolSize > 0 {
+		utilization = float64(inUse) / float64(poolSize) * 100
+	}
+
+	return PoolHealthReport{
+		UtilizationPct: utilization,
+		WaitCount:      stats.WaitCount,
+		TimeoutCount:   stats.Timeouts,
+		StaleConns:     stats.StaleConns,
+	}
+}
@@ -0,0 +1,137 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromPool(t *testing.T) {
+	ctx := context.Background()
+
+	pool, err := NewPool(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+	})
+
+	require.Nil(t, err)
+
+	first, err := NewFromPool(pool, &Config{
+		Limits: map[string]int{
+			"pool-feature1": 5,
+		},
+	})
+	require.Nil(t, err)
+
+	second, err := NewFromPool(pool, &Config{
+		Limits: map[string]int{
+			"pool-feature2": 3,
+		},
+	})
+	require.Nil(t, err)
+
+	pool.client.Del(ctx, dailyKey("pool-feature2", "pool-user"))
+
+	t.Run("Instances from the same pool should share one client", func(t *testing.T) {
+		require.Same(t, first.redisClient, second.redisClient)
+		require.Same(t, first.consumeScript, second.consumeScript)
+	})
+
+	t.Run("Instances from the same pool should keep their own limits", func(t *testing.T) {
+		_, limit := first.Get(ctx, "pool-feature1", "pool-user")
+		require.Equal(t, 5, limit)
+
+		_, limit = second.Get(ctx, "pool-feature1", "pool-user")
+		require.Equal(t, -1, limit)
+	})
+
+	t.Run("Closing an instance should leave the pool open", func(t *testing.T) {
+		require.Nil(t, first.Close())
+
+		result, _ := second.Consume(ctx, "pool-feature2", "pool-user")
+		require.True(t, result.Allowed)
+		require.Nil(t, pool.client.Ping(ctx).Err())
+	})
+
+	t.Run("Closing the pool should close the shared client", func(t *testing.T) {
+		require.Nil(t, pool.Close())
+		require.NotNil(t, pool.client.Ping(ctx).Err())
+	})
+}
+
+func TestReadReplica(t *testing.T) {
+	ctx := context.Background()
+
+	hg, err := New(&Config{
+		RedisAddress:     "localhost:6379",
+		RedisReadAddress: "localhost:6379",
+		Limits: map[string]int{
+			"replica-feature": 5,
+		},
+	})
+	require.Nil(t, err)
+	defer hg.Close()
+
+	hg.redisClient.Del(ctx, dailyKey("replica-feature", "replica-user"))
+
+	t.Run("Get should use a separate client for the replica", func(t *testing.T) {
+		require.NotSame(t, hg.redisClient, hg.readClient)
+	})
+
+	t.Run("Get should read what Consume wrote", func(t *testing.T) {
+		hg.Consume(ctx, "replica-feature", "replica-user")
+		hg.Consume(ctx, "replica-feature", "replica-user")
+
+		current, limit := hg.Get(ctx, "replica-feature", "replica-user")
+		require.Equal(t, 2, current)
+		require.Equal(t, 5, limit)
+	})
+
+	t.Run("Closing should close the replica client", func(t *testing.T) {
+		require.Nil(t, hg.Close())
+		require.NotNil(t, hg.readClient.Ping(ctx).Err())
+	})
+
+	t.Run("Without a replica Get should share the primary client", func(t *testing.T) {
+		pool, err := NewPool(&Config{RedisAddress: "localhost:6379"})
+		require.Nil(t, err)
+		defer pool.Close()
+
+		require.Same(t, pool.client, pool.readClient)
+	})
+}
+
+func TestPoolHealth(t *testing.T) {
+	ctx := context.Background()
+
+	hg, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		PoolSize:     4,
+		MinIdleConns: 1,
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+	})
+	require.Nil(t, err)
+	defer hg.Close()
+
+	t.Run("An idle pool should report no utilization", func(t *testing.T) {
+		hg.Get(ctx, "feature1", "health-user")
+
+		report := hg.PoolHealth()
+		require.Equal(t, float64(0), report.UtilizationPct)
+		require.Equal(t, uint32(0), report.TimeoutCount)
+	})
+
+	t.Run("Connections in use should count towards the utilization", func(t *testing.T) {
+		conn := hg.redisClient.Conn()
+		defer conn.Close()
+		require.Nil(t, conn.Ping(ctx).Err())
+
+		require.Eventually(t, func() bool {
+			return hg.PoolHealth().UtilizationPct == 25
+		}, time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,22 @@
+package hourglass
+
+import "context"
+
+// WithDynamicPrefix adds fn(ctx) to KeyPrefix for every key, e.g. to keep the
+// counters of each tenant apart in a multi-tenant service. fn receives the
+// context of the call and should be cheap. Background work such as the
+// janitor and the local buffer flush runs with its own context.
+func WithDynamicPrefix(fn func(ctx context.Context) string) Option {
+	return func(hg *HourGlass) {
+		hg.dynamicPrefix = fn
+	}
+}
+
+// keyPrefix returns the prefix of every key used for ctx.
+func (hg *HourGlass) keyPrefix(ctx context.Context) string {
+	if hg.dynamicPrefix == nil {
+		return hg.appConfig.KeyPrefix
+	}
+
+	return hg.appConfig.KeyPrefix + hg.dynamicPrefix(ctx)
+}
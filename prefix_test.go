@@ -0,0 +1,55 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tenantKey struct{}
+
+func TestWithDynamicPrefix(t *testing.T) {
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 1,
+		},
+		KeyPrefix: "app:",
+	}, WithDynamicPrefix(func(ctx context.Context) string {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenant + ":"
+	}))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	acme := context.WithValue(context.Background(), tenantKey{}, "acme")
+	globex := context.WithValue(context.Background(), tenantKey{}, "globex")
+	h.redisClient.Del(acme, "app:acme:"+dailyKey("feature1", "tenant-user"), "app:globex:"+dailyKey("feature1", "tenant-user"))
+
+	t.Run("Keys should carry the prefix of the context", func(t *testing.T) {
+		require.Equal(t, "app:acme:"+dailyKey("feature1", "tenant-user"), h.getKey(acme, "feature1", "tenant-user", time.UTC))
+
+		result, err := h.Consume(acme, "feature1", "tenant-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+
+		exists, err := h.redisClient.Exists(acme, "app:acme:"+dailyKey("feature1", "tenant-user")).Result()
+		require.Nil(t, err)
+		require.Equal(t, int64(1), exists)
+	})
+
+	t.Run("Tenants should have separate counters", func(t *testing.T) {
+		result, err := h.Consume(acme, "feature1", "tenant-user")
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+
+		result, err = h.Consume(globex, "feature1", "tenant-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+	})
+}
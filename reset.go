@@ -0,0 +1,114 @@
+package hourglass
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scheduledReset is a member of the scheduled resets sorted set. It holds the
+// key parts rather than the user name, so hashed keys stay hashed.
+type scheduledReset struct {
+	Prefix     string `json:"prefix"`
+	Feature    string `json:"feature"`
+	KeyFeature string `json:"keyFeature"`
+	KeyUser    string `json:"keyUser"`
+}
+
+func (hg *HourGlass) scheduledResetsKey() string {
+	return hg.appConfig.KeyPrefix + "scheduled-resets"
+}
+
+// scheduledResetWindowsKey is the hash that maps each scheduled reset to the
+// ID of the window it falls in. The window is taken in the user's time zone
+// when scheduling, since hashed names cannot be looked up later, and is kept
+// out of the member so that scheduling again replaces the earlier reset.
+func (hg *HourGlass) scheduledResetWindowsKey() string {
+	return hg.scheduledResetsKey() + ":windows"
+}
+
+// ScheduleReset resets the counter of userName for featureName at at instead
+// of at the window boundary, e.g. on an account anniversary. Resets are kept
+// in the sorted set {KeyPrefix}scheduled-resets and carried out by
+// ProcessScheduledResets. Scheduling again replaces the earlier time. The
+// set is shared by all dynamic prefixes, each reset remembers its own.
+func (hg *HourGlass) ScheduleReset(ctx context.Context, featureName, userName string, at time.Time) error {
+	keyFeature, _, exists := hg.lookupLimit(featureName, userName)
+	if !exists {
+		return ErrUnknownFeature
+	}
+	if !hg.validUserName(userName) {
+		return ErrInvalidUsername
+	}
+
+	member, err := json.Marshal(scheduledReset{Prefix: hg.keyPrefix(ctx), Feature: featureName, KeyFeature: keyFeature, KeyUser: hg.keyUser(userName)})
+	if err != nil {
+		return err
+	}
+
+	_, err = hg.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, hg.scheduledResetsKey(), redis.Z{Score: float64(at.UnixMilli()), Member: member})
+		pipe.HSet(ctx, hg.scheduledResetWindowsKey(), string(member), hg.userWindowIDAt(ctx, featureName, userName, at))
+		return nil
+	})
+	return err
+}
+
+// ProcessScheduledResets deletes the current counters of every reset that is
+// due and returns how many were carried out. Run it periodically, e.g. once a
+// minute; resets happen at the first run after their time.
+func (hg *HourGlass) ProcessScheduledResets(ctx context.Context) (int64, error) {
+	key := hg.scheduledResetsKey()
+	due, err := hg.redisClient.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var processed int64
+	for _, member := range due {
+		var reset scheduledReset
+		if err := json.Unmarshal([]byte(member), &reset); err != nil {
+			hg.logger.WarnContext(ctx, "dropping malformed scheduled reset", "member", member, "error", err)
+			hg.redisClient.ZRem(ctx, key, member)
+			hg.redisClient.HDel(ctx, hg.scheduledResetWindowsKey(), member)
+			continue
+		}
+
+		// ZREM decides which instance carries out a reset when several run
+		// the job at once.
+		removed, err := hg.redisClient.ZRem(ctx, key, member).Result()
+		if err != nil {
+			return processed, err
+		}
+		if removed == 0 {
+			continue
+		}
+
+		window, err := hg.redisClient.HGet(ctx, hg.scheduledResetWindowsKey(), member).Result()
+		switch {
+		case errors.Is(err, redis.Nil):
+			window = hg.windowID(reset.Feature)
+		case err != nil:
+			return processed, err
+		}
+		hg.redisClient.HDel(ctx, hg.scheduledResetWindowsKey(), member)
+		counterKey := reset.Prefix + reset.KeyFeature + ":" + reset.KeyUser + ":" + window
+
+		if err := hg.redisClient.Del(ctx, counterKey, burstKey(counterKey)).Err(); err != nil {
+			return processed, err
+		}
+		if hg.writeCache != nil {
+			hg.writeCache.invalidate(counterKey)
+		}
+		processed++
+	}
+
+	return processed, nil
+}
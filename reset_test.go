@@ -0,0 +1,73 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledResets(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+		KeyPrefix: "resetprefix:",
+	}, WithWriteThroughCache())
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, h.scheduledResetsKey(), h.scheduledResetWindowsKey(), "resetprefix:"+dailyKey("feature1", "due-user"), "resetprefix:"+dailyKey("feature1", "later-user"))
+
+	for _, userName := range []string{"due-user", "later-user"} {
+		for i := 0; i < 3; i++ {
+			_, err := h.Consume(ctx, "feature1", userName)
+			require.Nil(t, err)
+		}
+	}
+
+	t.Run("Due resets should clear the counter", func(t *testing.T) {
+		require.Nil(t, h.ScheduleReset(ctx, "feature1", "due-user", time.Now().Add(-time.Second)))
+		require.Nil(t, h.ScheduleReset(ctx, "feature1", "later-user", time.Now().Add(time.Hour)))
+
+		processed, err := h.ProcessScheduledResets(ctx)
+		require.Nil(t, err)
+		require.Equal(t, int64(1), processed)
+
+		current, _ := h.Get(ctx, "feature1", "due-user")
+		require.Equal(t, -1, current)
+
+		result, err := h.Consume(ctx, "feature1", "due-user")
+		require.Nil(t, err)
+		require.Equal(t, 1, result.Current)
+	})
+
+	t.Run("Pending resets should be kept", func(t *testing.T) {
+		current, _ := h.Get(ctx, "feature1", "later-user")
+		require.Equal(t, 3, current)
+		require.Equal(t, int64(1), h.redisClient.ZCard(ctx, h.scheduledResetsKey()).Val())
+
+		processed, err := h.ProcessScheduledResets(ctx)
+		require.Nil(t, err)
+		require.Zero(t, processed)
+	})
+
+	t.Run("Scheduling again in another window should replace the earlier reset", func(t *testing.T) {
+		require.Nil(t, h.ScheduleReset(ctx, "feature1", "later-user", time.Now().Add(48*time.Hour)))
+		require.Equal(t, int64(1), h.redisClient.ZCard(ctx, h.scheduledResetsKey()).Val())
+		require.Equal(t, int64(1), h.redisClient.HLen(ctx, h.scheduledResetWindowsKey()).Val())
+
+		windows := h.redisClient.HVals(ctx, h.scheduledResetWindowsKey()).Val()
+		require.Equal(t, []string{time.Now().UTC().Add(48 * time.Hour).Format("2006-01-02")}, windows)
+	})
+
+	t.Run("Unknown features should be rejected", func(t *testing.T) {
+		require.ErrorIs(t, h.ScheduleReset(ctx, "feature-notexistent", "due-user", time.Now()), ErrUnknownFeature)
+	})
+}
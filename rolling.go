@@ -0,0 +1,89 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// limitOverrideKey returns the key that holds the rolling average limit for
+// the window of the counter at key.
+func limitOverrideKey(key string) string {
+	return key + ":limit"
+}
+
+// countsKey returns the list of past window counts of userName for
+// featureName, newest first.
+func (hg *HourGlass) countsKey(ctx context.Context, featureName, userName string) string {
+	keyFeature, _, _ := hg.lookupLimit(featureName, userName)
+	return fmt.Sprintf("%s%s:%s:counts", hg.keyPrefix(ctx), keyFeature, hg.keyUser(userName))
+}
+
+// rollingLimit returns the rolling average limit stored for the window of
+// the counter at key, or limit when there is none.
+func (hg *HourGlass) rollingLimit(ctx context.Context, key string, limit int) (int, error) {
+	override, err := hg.redisClient.Get(ctx, limitOverrideKey(key)).Int()
+	if errors.Is(err, redis.Nil) {
+		return limit, nil
+	}
+	if err != nil {
+		return limit, err
+	}
+
+	return override, nil
+}
+
+// UpdateRollingAverage records the count of the current window of userName
+// and sets the limit of the next window to the average of the last
+// RollingAverageWindows counts, rounded up and at least 1. It is meant to run
+// at the end of every window, e.g. from a cron job. Until it has run, the
+// configured limit applies.
+func (hg *HourGlass) UpdateRollingAverage(ctx context.Context, featureName, userName string) error {
+	if _, exists := hg.limitProvider.Limit(featureName); !exists {
+		return ErrUnknownFeature
+	}
+	windows := hg.appConfig.Features[featureName].RollingAverageWindows
+	if windows <= 0 {
+		return ErrNoRollingAverage
+	}
+
+	current, _, err := hg.get(ctx, featureName, userName)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	windowEnd := hg.userWindowEnd(ctx, featureName, userName)
+	window := hg.appConfig.Features[featureName].Window
+	if window <= 0 {
+		window = day
+	}
+
+	countsKey := hg.countsKey(ctx, featureName, userName)
+	var counts *redis.StringSliceCmd
+	_, err = hg.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, countsKey, current)
+		pipe.LTrim(ctx, countsKey, 0, int64(windows-1))
+		pipe.ExpireAt(ctx, countsKey, windowEnd.Add(window*time.Duration(windows)))
+		counts = pipe.LRange(ctx, countsKey, 0, -1)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for _, count := range counts.Val() {
+		n, _ := strconv.Atoi(count)
+		total += n
+	}
+	n := len(counts.Val())
+	average := max((total+n-1)/n, 1)
+
+	// The next window starts at the end of the current one.
+	nextKey := hg.KeyFor(ctx, featureName, userName, windowEnd, false)
+	return hg.redisClient.Set(ctx, limitOverrideKey(nextKey), average, time.Until(windowEnd.Add(window))).Err()
+}
@@ -0,0 +1,65 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateRollingAverage(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"rolling-feature": 100,
+			"feature1":        5,
+		},
+		Features: map[string]FeatureConfig{
+			"rolling-feature": {RollingAverageWindows: 3},
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := dailyKey("rolling-feature", "rolling-user")
+	nextKey := h.KeyFor(ctx, "rolling-feature", "rolling-user", endOfDay(), false)
+	countsKey := h.countsKey(ctx, "rolling-feature", "rolling-user")
+	h.redisClient.Del(ctx, key, limitOverrideKey(key), limitOverrideKey(nextKey), countsKey)
+
+	t.Run("The next window should get the average of the past windows", func(t *testing.T) {
+		h.redisClient.RPush(ctx, countsKey, 2, 6, 10)
+		h.redisClient.Set(ctx, key, 3, time.Minute)
+
+		require.Nil(t, h.UpdateRollingAverage(ctx, "rolling-feature", "rolling-user"))
+
+		// The oldest count drops out: (3 + 2 + 6) / 3 rounded up.
+		limit, err := h.redisClient.Get(ctx, limitOverrideKey(nextKey)).Int()
+		require.Nil(t, err)
+		require.Equal(t, 4, limit)
+		require.Equal(t, int64(3), h.redisClient.LLen(ctx, countsKey).Val())
+	})
+
+	t.Run("Consume should use the limit stored for the window", func(t *testing.T) {
+		h.redisClient.Set(ctx, limitOverrideKey(key), 4, time.Minute)
+
+		result, err := h.Consume(ctx, "rolling-feature", "rolling-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 4, result.Current)
+		require.Equal(t, 4, result.Limit)
+
+		result, err = h.Consume(ctx, "rolling-feature", "rolling-user")
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("Features without a rolling average should be rejected", func(t *testing.T) {
+		require.ErrorIs(t, h.UpdateRollingAverage(ctx, "feature1", "rolling-user"), ErrNoRollingAverage)
+		require.ErrorIs(t, h.UpdateRollingAverage(ctx, "feature-notexistent", "rolling-user"), ErrUnknownFeature)
+	})
+}
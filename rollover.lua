@@ -0,0 +1,19 @@
+local bank_key = KEYS[1]
+local key = KEYS[2]
+local limit = tonumber(ARGV[1])
+local rollover_max = tonumber(ARGV[2])
+local window = ARGV[3]
+
+-- Each window is banked once, so the job can safely run more than once.
+if redis.call('HGET', bank_key, 'window') == window then
+    return 0
+end
+
+local current = tonumber(redis.call('GET', key) or '0')
+local banked = tonumber(redis.call('HGET', bank_key, 'units') or '0')
+local added = math.max(math.min(limit - current, rollover_max - banked), 0)
+
+redis.call('HINCRBY', bank_key, 'units', added)
+redis.call('HSET', bank_key, 'window', window)
+
+return added
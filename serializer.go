@@ -0,0 +1,127 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maxSerializedRetries bounds how often a read-modify-write of a serialized
+// counter is retried when another client changes the key in between.
+const maxSerializedRetries = 10
+
+// ValueSerializer converts counters to and from the string stored in Redis,
+// so that metadata can be kept next to the count.
+type ValueSerializer interface {
+	Encode(count int, meta map[string]string) (string, error)
+	Decode(raw string) (count int, meta map[string]string, err error)
+}
+
+// WithValueSerializer stores counters in the format of vs instead of plain
+// integers. Lua cannot call into Go, so Consume, Credit and Get fall back to
+// an optimistic WATCH/MULTI transaction that decodes, updates and re-encodes
+// the value, keeping any metadata. Burst allowances, the local buffer, the
+// write-through cache and TransferCredit expect plain integers and are not
+// applied to serialized counters.
+func WithValueSerializer(vs ValueSerializer) Option {
+	return func(hg *HourGlass) {
+		hg.valueSerializer = vs
+	}
+}
+
+// intSerializer is the default format, a plain integer without metadata.
+type intSerializer struct{}
+
+func (intSerializer) Encode(count int, meta map[string]string) (string, error) {
+	return strconv.Itoa(count), nil
+}
+
+func (intSerializer) Decode(raw string) (int, map[string]string, error) {
+	count, err := strconv.Atoi(raw)
+	return count, nil, err
+}
+
+func (hg *HourGlass) serializer() ValueSerializer {
+	if hg.valueSerializer == nil {
+		return intSerializer{}
+	}
+	return hg.valueSerializer
+}
+
+// updateSerialized applies update to the decoded counter at key inside a
+// WATCH/MULTI transaction. A missing key is passed to update as a zero count
+// and is written with ttl; existing keys keep their expiry. update returns
+// false to leave the key untouched.
+func (hg *HourGlass) updateSerialized(ctx context.Context, key string, ttl time.Duration, update func(count int) (int, bool)) (int, error) {
+	var current int
+
+	txf := func(tx *redis.Tx) error {
+		count, meta := 0, map[string]string(nil)
+		expiration := ttl
+
+		raw, err := tx.Get(ctx, key).Result()
+		switch {
+		case errors.Is(err, redis.Nil):
+		case err != nil:
+			return err
+		default:
+			count, meta, err = hg.serializer().Decode(raw)
+			if err != nil {
+				return err
+			}
+			expiration = redis.KeepTTL
+		}
+
+		current = count
+		newCount, ok := update(count)
+		if !ok {
+			return nil
+		}
+
+		encoded, err := hg.serializer().Encode(newCount, meta)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, encoded, expiration)
+			return nil
+		})
+		if err == nil {
+			current = newCount
+		}
+		return err
+	}
+
+	for range maxSerializedRetries {
+		err := hg.redisClient.Watch(ctx, txf, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return current, err
+		}
+	}
+
+	return current, redis.TxFailedErr
+}
+
+func (hg *HourGlass) consumeSerialized(ctx context.Context, key string, limit int, ttl time.Duration) (current int, allowed bool, err error) {
+	current, err = hg.updateSerialized(ctx, key, ttl, func(count int) (int, bool) {
+		allowed = count < limit
+		return count + 1, allowed
+	})
+
+	return current, allowed, err
+}
+
+func (hg *HourGlass) creditSerialized(ctx context.Context, key string, amount int) (int, error) {
+	// Like credit.lua, the counter is clamped at zero and a missing key is
+	// left alone.
+	return hg.updateSerialized(ctx, key, 0, func(count int) (int, bool) {
+		if count <= 0 {
+			return count, false
+		}
+		return max(count-amount, 0), true
+	})
+}
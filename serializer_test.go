@@ -0,0 +1,89 @@
+package hourglass
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type jsonSerializer struct{}
+
+type jsonValue struct {
+	Count int               `json:"count"`
+	Meta  map[string]string `json:"meta,omitempty"`
+}
+
+func (jsonSerializer) Encode(count int, meta map[string]string) (string, error) {
+	raw, err := json.Marshal(jsonValue{Count: count, Meta: meta})
+	return string(raw), err
+}
+
+func (jsonSerializer) Decode(raw string) (int, map[string]string, error) {
+	var value jsonValue
+	err := json.Unmarshal([]byte(raw), &value)
+	return value.Count, value.Meta, err
+}
+
+func TestValueSerializer(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 2,
+		},
+	}, WithValueSerializer(jsonSerializer{}))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := dailyKey("feature1", "serializer-user")
+	h.redisClient.Del(ctx, key)
+
+	t.Run("Consume should store counters in the serializer's format", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "serializer-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+
+		require.JSONEq(t, `{"count":1}`, h.redisClient.Get(ctx, key).Val())
+		require.InDelta(t, h.ttlFor(ctx, "feature1", "serializer-user").Seconds(), h.redisClient.TTL(ctx, key).Val().Seconds(), 2)
+	})
+
+	t.Run("Updates should keep the metadata and the expiry", func(t *testing.T) {
+		h.redisClient.Set(ctx, key, `{"count":1,"meta":{"request":"abc"}}`, time.Hour)
+
+		result, _ := h.Consume(ctx, "feature1", "serializer-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 2, result.Current)
+
+		require.JSONEq(t, `{"count":2,"meta":{"request":"abc"}}`, h.redisClient.Get(ctx, key).Val())
+		require.InDelta(t, time.Hour.Seconds(), h.redisClient.TTL(ctx, key).Val().Seconds(), 2)
+	})
+
+	t.Run("Consume should be denied at the limit", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "serializer-user")
+		require.False(t, result.Allowed)
+		require.Equal(t, 2, result.Current)
+	})
+
+	t.Run("Get and Credit should decode the value", func(t *testing.T) {
+		current, _ := h.Get(ctx, "feature1", "serializer-user")
+		require.Equal(t, 2, current)
+
+		current, _ = h.Credit(ctx, "feature1", "serializer-user")
+		require.Equal(t, 1, current)
+		require.JSONEq(t, `{"count":1,"meta":{"request":"abc"}}`, h.redisClient.Get(ctx, key).Val())
+	})
+
+	t.Run("Values that cannot be decoded should fail open", func(t *testing.T) {
+		h.redisClient.Set(ctx, key, "5", time.Hour)
+
+		result, err := h.consume(ctx, "feature1", "serializer-user")
+		require.NotNil(t, err)
+		require.True(t, result.Allowed)
+	})
+}
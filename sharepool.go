@@ -0,0 +1,167 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed sharepool.lua
+var sharePoolScriptData string
+
+// maxRebalanceRetries bounds how often RebalancePool retries when a member
+// consumes while the pool is being rebalanced.
+const maxRebalanceRetries = 10
+
+// sharePoolKeys returns the hashes that hold the member allocations and the
+// member usage of a pool in the current window of featureName.
+func (hg *HourGlass) sharePoolKeys(ctx context.Context, featureName, groupName string) (allocations, used string) {
+	allocations = fmt.Sprintf("%spool:%s:%s:%s", hg.keyPrefix(ctx), featureName, groupName, hg.windowID(featureName))
+	return allocations, allocations + ":used"
+}
+
+// poolFields returns the hash fields of the members that take part in a
+// pool: their names as they appear in keys, hashed with WithHashedKeys, and
+// without blacklisted users.
+func (hg *HourGlass) poolFields(members []string) []string {
+	fields := make([]string, 0, len(members))
+	for _, member := range members {
+		if !hg.blacklist.contains(member) {
+			fields = append(fields, hg.keyUser(member))
+		}
+	}
+
+	return fields
+}
+
+// SharePool divides totalCredits evenly among members for the current window
+// of featureName, replacing any earlier allocations of the pool. Credits that
+// do not divide evenly are left unallocated. Blacklisted members get no
+// allocation. Members consume from their own allocation with
+// ConsumeFromPool.
+func (hg *HourGlass) SharePool(ctx context.Context, featureName, groupName string, totalCredits int, members []string) error {
+	if _, exists := hg.limitProvider.Limit(featureName); !exists {
+		return ErrUnknownFeature
+	}
+	if totalCredits <= 0 {
+		return ErrInvalidAmount
+	}
+	fields := hg.poolFields(members)
+	if len(fields) == 0 {
+		return ErrNoPoolMembers
+	}
+
+	allocationsKey, usedKey := hg.sharePoolKeys(ctx, featureName, groupName)
+	allocations := make(map[string]any, len(fields))
+	for _, field := range fields {
+		allocations[field] = totalCredits / len(fields)
+	}
+
+	_, err := hg.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, allocationsKey, usedKey)
+		pipe.HSet(ctx, allocationsKey, allocations)
+		pipe.ExpireAt(ctx, allocationsKey, hg.windowEnd(featureName))
+		return nil
+	})
+	return err
+}
+
+// RebalancePool divides the credits left in the pool evenly among members,
+// for when members join or leave. Members keep what they already used on top
+// of their new share, so the allocations never add up to more than the pool
+// was created with. Usage of members that left is dropped.
+func (hg *HourGlass) RebalancePool(ctx context.Context, featureName, groupName string, members []string) error {
+	fields := hg.poolFields(members)
+	if len(fields) == 0 {
+		return ErrNoPoolMembers
+	}
+
+	allocationsKey, usedKey := hg.sharePoolKeys(ctx, featureName, groupName)
+
+	txf := func(tx *redis.Tx) error {
+		allocations, err := tx.HGetAll(ctx, allocationsKey).Result()
+		if err != nil {
+			return err
+		}
+		if len(allocations) == 0 {
+			return ErrPoolNotFound
+		}
+		used, err := tx.HGetAll(ctx, usedKey).Result()
+		if err != nil {
+			return err
+		}
+
+		remaining := 0
+		for member, allocation := range allocations {
+			allocated, _ := strconv.Atoi(allocation)
+			spent, _ := strconv.Atoi(used[member])
+			remaining += max(allocated-spent, 0)
+		}
+
+		newAllocations := make(map[string]any, len(fields))
+		newUsed := make(map[string]any, len(fields))
+		for _, field := range fields {
+			spent, _ := strconv.Atoi(used[field])
+			newAllocations[field] = spent + remaining/len(fields)
+			if spent > 0 {
+				newUsed[field] = spent
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, allocationsKey, usedKey)
+			pipe.HSet(ctx, allocationsKey, newAllocations)
+			pipe.ExpireAt(ctx, allocationsKey, hg.windowEnd(featureName))
+			if len(newUsed) > 0 {
+				pipe.HSet(ctx, usedKey, newUsed)
+				pipe.ExpireAt(ctx, usedKey, hg.windowEnd(featureName))
+			}
+			return nil
+		})
+		return err
+	}
+
+	for range maxRebalanceRetries {
+		err := hg.redisClient.Watch(ctx, txf, allocationsKey, usedKey)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+
+	return redis.TxFailedErr
+}
+
+// ConsumeFromPool consumes one unit of userName's allocation in the pool.
+// Users that are not members of the pool get ErrNotPoolMember and
+// blacklisted users ErrUserBlacklisted.
+func (hg *HourGlass) ConsumeFromPool(ctx context.Context, featureName, groupName, userName string) (ConsumeResult, error) {
+	if _, exists := hg.limitProvider.Limit(featureName); !exists {
+		return ConsumeResult{Current: -1, Limit: -1, Allowed: true}, nil
+	}
+	if hg.blacklist.contains(userName) {
+		return ConsumeResult{Current: -1, Limit: -1, Allowed: false}, ErrUserBlacklisted
+	}
+
+	allocationsKey, usedKey := hg.sharePoolKeys(ctx, featureName, groupName)
+	result, err := hg.sharePoolScript.Run(ctx, hg.redisClient, []string{allocationsKey, usedKey}, hg.keyUser(userName)).Result()
+	if err != nil {
+		return hg.failureResult(-1, err)
+	}
+
+	current, allocation, allowed, _ := parseConsumeReply(result.([]interface{}))
+	if allocation < 0 {
+		return ConsumeResult{Current: -1, Limit: -1, Allowed: false}, ErrNotPoolMember
+	}
+
+	return ConsumeResult{
+		Current:   current,
+		Limit:     allocation,
+		Remaining: max(allocation-current, 0),
+		Allowed:   allowed,
+		ResetsAt:  hg.windowEnd(featureName),
+	}, nil
+}
@@ -0,0 +1,20 @@
+local allocations_key = KEYS[1]
+local used_key = KEYS[2]
+local member = ARGV[1]
+
+local allocation = redis.call('HGET', allocations_key, member)
+if allocation == false then
+    return {-1, -1, 0}
+end
+allocation = tonumber(allocation)
+
+local used = tonumber(redis.call('HGET', used_key, member) or '0')
+if used >= allocation then
+    return {used, allocation, 0}
+end
+
+used = redis.call('HINCRBY', used_key, member, 1)
+-- Usage expires together with the allocations of the window.
+redis.call('PEXPIRE', used_key, redis.call('PTTL', allocations_key))
+
+return {used, allocation, 1}
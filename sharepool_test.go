@@ -0,0 +1,121 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharePool(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 100,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	allocationsKey, usedKey := h.sharePoolKeys(ctx, "feature1", "team")
+	h.redisClient.Del(ctx, allocationsKey, usedKey)
+
+	t.Run("Credits should be divided evenly among members", func(t *testing.T) {
+		err := h.SharePool(ctx, "feature1", "team", 10, []string{"alice", "bob", "carol"})
+		require.Nil(t, err)
+
+		for i := 1; i <= 3; i++ {
+			result, err := h.ConsumeFromPool(ctx, "feature1", "team", "alice")
+			require.Nil(t, err)
+			require.True(t, result.Allowed)
+			require.Equal(t, i, result.Current)
+			require.Equal(t, 3, result.Limit)
+		}
+
+		result, err := h.ConsumeFromPool(ctx, "feature1", "team", "alice")
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+
+		ttl, err := h.redisClient.TTL(ctx, usedKey).Result()
+		require.Nil(t, err)
+		require.Greater(t, ttl.Seconds(), 0.0)
+	})
+
+	t.Run("Rebalancing should divide the remaining credits", func(t *testing.T) {
+		_, err := h.ConsumeFromPool(ctx, "feature1", "team", "bob")
+		require.Nil(t, err)
+
+		// 9 allocated, alice used 3 and bob used 1, so 5 are left for two members.
+		err = h.RebalancePool(ctx, "feature1", "team", []string{"alice", "dave"})
+		require.Nil(t, err)
+
+		allocations, err := h.redisClient.HGetAll(ctx, allocationsKey).Result()
+		require.Nil(t, err)
+		require.Equal(t, map[string]string{"alice": "5", "dave": "2"}, allocations)
+
+		result, err := h.ConsumeFromPool(ctx, "feature1", "team", "alice")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 4, result.Current)
+	})
+
+	t.Run("Users outside the pool should be rejected", func(t *testing.T) {
+		_, err := h.ConsumeFromPool(ctx, "feature1", "team", "bob")
+		require.ErrorIs(t, err, ErrNotPoolMember)
+	})
+
+	t.Run("Invalid pools should be rejected", func(t *testing.T) {
+		require.ErrorIs(t, h.SharePool(ctx, "feature1", "team", 10, nil), ErrNoPoolMembers)
+		require.ErrorIs(t, h.SharePool(ctx, "feature1", "team", 0, []string{"alice"}), ErrInvalidAmount)
+		require.ErrorIs(t, h.SharePool(ctx, "feature-notexistent", "team", 10, []string{"alice"}), ErrUnknownFeature)
+		require.ErrorIs(t, h.RebalancePool(ctx, "feature1", "no-team", []string{"alice"}), ErrPoolNotFound)
+	})
+}
+
+func TestSharePoolMembers(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress: "localhost:6379",
+		Limits: map[string]int{
+			"feature1": 100,
+		},
+		Blacklist: []string{"mallory"},
+	}, WithHashedKeys("pool-secret"))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	allocationsKey, usedKey := h.sharePoolKeys(ctx, "feature1", "hashed-team")
+	h.redisClient.Del(ctx, allocationsKey, usedKey)
+
+	require.Nil(t, h.SharePool(ctx, "feature1", "hashed-team", 10, []string{"alice", "mallory"}))
+
+	t.Run("Members should be stored under their hashed names", func(t *testing.T) {
+		allocations, err := h.redisClient.HGetAll(ctx, allocationsKey).Result()
+		require.Nil(t, err)
+		require.Equal(t, map[string]string{h.keyUser("alice"): "10"}, allocations)
+	})
+
+	t.Run("Members should consume from their allocation", func(t *testing.T) {
+		result, err := h.ConsumeFromPool(ctx, "feature1", "hashed-team", "alice")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 10, result.Limit)
+
+		require.Nil(t, h.RebalancePool(ctx, "feature1", "hashed-team", []string{"alice", "bob", "mallory"}))
+		allocations, err := h.redisClient.HGetAll(ctx, allocationsKey).Result()
+		require.Nil(t, err)
+		require.Equal(t, map[string]string{h.keyUser("alice"): "5", h.keyUser("bob"): "4"}, allocations)
+	})
+
+	t.Run("Blacklisted members should be rejected", func(t *testing.T) {
+		_, err := h.ConsumeFromPool(ctx, "feature1", "hashed-team", "mallory")
+		require.ErrorIs(t, err, ErrUserBlacklisted)
+		require.ErrorIs(t, h.SharePool(ctx, "feature1", "hashed-team", 10, []string{"mallory"}), ErrNoPoolMembers)
+	})
+}
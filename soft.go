@@ -0,0 +1,54 @@
+package hourglass
+
+import (
+	"context"
+	"math"
+)
+
+// SoftConsume consumes one unit like Consume but lets the user go over the
+// limit, up to the feature's SoftLimit, instead of denying them. penaltyUnits
+// is how far the counter is over the limit after the consume, for the caller
+// to bill the overage. Only calls over the SoftLimit are denied. Burst
+// allowances, cooldowns, per minute rates, the local buffer and value
+// serializers are not applied.
+func (hg *HourGlass) SoftConsume(ctx context.Context, featureName, userName string) (result ConsumeResult, penaltyUnits int, err error) {
+	key, limit, exists := hg.lookup(ctx, featureName, userName)
+	if !exists {
+		return ConsumeResult{Current: -1, Limit: -1}, 0, ErrUnknownFeature
+	}
+	if hg.blacklist.contains(userName) {
+		return ConsumeResult{Current: -1, Limit: limit}, 0, ErrUserBlacklisted
+	}
+
+	resetsAt := hg.userWindowEnd(ctx, featureName, userName)
+	if hg.whitelist.contains(userName) {
+		return ConsumeResult{Current: 0, Limit: limit, Remaining: limit, Allowed: true, ResetsAt: resetsAt}, 0, nil
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		result, err := hg.failureResult(limit, err)
+		return result, 0, err
+	}
+
+	hardLimit := math.MaxInt32
+	if softLimit := hg.appConfig.Features[featureName].SoftLimit; softLimit > 0 {
+		hardLimit = max(softLimit, limit)
+	}
+
+	current, _, allowed, _, err := hg.runConsumeScript(ctx, hg.consumeScript, key, hardLimit, 0, hg.ttlFor(ctx, featureName, userName))
+	if err != nil {
+		result, err := hg.failureResult(limit, err)
+		return result, 0, err
+	}
+	if allowed {
+		penaltyUnits = max(current-limit, 0)
+	}
+
+	return ConsumeResult{
+		Current:   current,
+		Limit:     limit,
+		Remaining: max(limit-current, 0),
+		Allowed:   allowed,
+		ResetsAt:  resetsAt,
+	}, penaltyUnits, nil
+}
@@ -0,0 +1,86 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftConsume(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 2,
+		},
+		Features: map[string]FeatureConfig{
+			"feature1": {SoftLimit: 4},
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "soft-user"))
+
+	tt := []struct {
+		description     string
+		featureName     string
+		expectedCurrent int
+		expectedAllowed bool
+		expectedPenalty int
+		expectedErr     error
+	}{
+		{
+			description:     "A consume within the limit should have no penalty",
+			featureName:     "feature1",
+			expectedCurrent: 1,
+			expectedAllowed: true,
+		},
+		{
+			description:     "A consume up to the limit should have no penalty",
+			featureName:     "feature1",
+			expectedCurrent: 2,
+			expectedAllowed: true,
+		},
+		{
+			description:     "The first consume over the limit should have a penalty of one",
+			featureName:     "feature1",
+			expectedCurrent: 3,
+			expectedAllowed: true,
+			expectedPenalty: 1,
+		},
+		{
+			description:     "A consume up to the soft limit should be allowed with a penalty",
+			featureName:     "feature1",
+			expectedCurrent: 4,
+			expectedAllowed: true,
+			expectedPenalty: 2,
+		},
+		{
+			description:     "A consume over the soft limit should be denied",
+			featureName:     "feature1",
+			expectedCurrent: 4,
+			expectedAllowed: false,
+		},
+		{
+			description:     "An unknown feature should fail",
+			featureName:     "feature-notexistent",
+			expectedCurrent: -1,
+			expectedErr:     ErrUnknownFeature,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			result, penaltyUnits, err := h.SoftConsume(ctx, tc.featureName, "soft-user")
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedCurrent, result.Current)
+			require.Equal(t, tc.expectedAllowed, result.Allowed)
+			require.Equal(t, tc.expectedPenalty, penaltyUnits)
+		})
+	}
+}
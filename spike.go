@@ -0,0 +1,87 @@
+package hourglass
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const baselineDays = 7
+
+// WithSpikeDetector calls alert when a user's consume rate over the last
+// window exceeds threshold times their average rate of the previous seven
+// days. Rates are consumes per second. The window is counted from the time
+// series, so WithTimeSeries is required with a retention of at least window.
+// Daily totals for the baseline are kept in a Redis hash per feature and user;
+// no alert fires until the user has a baseline. alert runs synchronously in
+// Consume and should return quickly.
+func WithSpikeDetector(threshold float64, window time.Duration, alert func(featureName, userName string, rate float64)) Option {
+	return func(hg *HourGlass) {
+		hg.spikeDetector = &spikeDetector{threshold: threshold, window: window, alert: alert}
+	}
+}
+
+type spikeDetector struct {
+	threshold float64
+	window    time.Duration
+	alert     func(featureName, userName string, rate float64)
+}
+
+func (hg *HourGlass) baselineKey(ctx context.Context, featureName, userName string) string {
+	return fmt.Sprintf("%s%s:%s:baseline", hg.keyPrefix(ctx), featureName, hg.keyUser(userName))
+}
+
+// detectSpike records the consume in the daily baseline and alerts when the
+// current rate is a spike. It is called after the consume was recorded in the
+// time series.
+func (hg *HourGlass) detectSpike(ctx context.Context, featureName, userName string) error {
+	d := hg.spikeDetector
+	now := time.Now()
+	today := now.UTC().Format("2006-01-02")
+	key := hg.baselineKey(ctx, featureName, userName)
+
+	var windowCount *redis.IntCmd
+	var daily *redis.MapStringStringCmd
+	_, err := hg.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, key, today, 1)
+		pipe.Expire(ctx, key, (baselineDays+1)*24*time.Hour)
+		daily = pipe.HGetAll(ctx, key)
+		windowCount = pipe.ZCount(ctx, hg.timeSeriesKey(ctx, featureName, userName),
+			strconv.FormatInt(now.Add(-d.window).UnixMilli(), 10), strconv.FormatInt(now.UnixMilli(), 10))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	oldest := now.UTC().AddDate(0, 0, -baselineDays).Format("2006-01-02")
+	var total int
+	var stale []string
+	for date, count := range daily.Val() {
+		switch {
+		case date < oldest:
+			stale = append(stale, date)
+		case date != today:
+			n, _ := strconv.Atoi(count)
+			total += n
+		}
+	}
+	if len(stale) > 0 {
+		hg.redisClient.HDel(ctx, key, stale...)
+	}
+
+	averageRate := float64(total) / baselineDays / (24 * time.Hour).Seconds()
+	if averageRate == 0 {
+		return nil
+	}
+
+	rate := float64(windowCount.Val()) / d.window.Seconds()
+	if rate > d.threshold*averageRate {
+		d.alert(featureName, userName, rate)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpikeDetector(t *testing.T) {
+	ctx := context.Background()
+
+	type spike struct {
+		featureName string
+		userName    string
+		rate        float64
+	}
+	var spikes []spike
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 100,
+		},
+	}, WithTimeSeries(time.Hour), WithSpikeDetector(10, time.Minute, func(featureName, userName string, rate float64) {
+		spikes = append(spikes, spike{featureName, userName, rate})
+	}))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	baselineKey := h.baselineKey(ctx, "feature1", "spike-user")
+	h.redisClient.Del(ctx, dailyKey("feature1", "spike-user"), h.timeSeriesKey(ctx, "feature1", "spike-user"), baselineKey)
+
+	t.Run("Without a baseline no alert should fire", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "spike-user")
+		require.True(t, result.Allowed)
+		require.Empty(t, spikes)
+
+		today := time.Now().UTC().Format("2006-01-02")
+		require.Equal(t, "1", h.redisClient.HGet(ctx, baselineKey, today).Val())
+	})
+
+	t.Run("A rate above the baseline should fire an alert", func(t *testing.T) {
+		// 7 * 1440 consumes over the last week is one per minute on average.
+		for days := 1; days <= 7; days++ {
+			date := time.Now().UTC().AddDate(0, 0, -days).Format("2006-01-02")
+			h.redisClient.HSet(ctx, baselineKey, date, 1440)
+		}
+
+		// Ten consumes in the window are exactly ten times the average.
+		for i := 0; i < 9; i++ {
+			h.Consume(ctx, "feature1", "spike-user")
+		}
+		require.Empty(t, spikes)
+
+		h.Consume(ctx, "feature1", "spike-user")
+		require.Len(t, spikes, 1)
+		require.Equal(t, "feature1", spikes[0].featureName)
+		require.Equal(t, "spike-user", spikes[0].userName)
+		require.InDelta(t, 11.0/60, spikes[0].rate, 0.001)
+	})
+
+	t.Run("Days older than the baseline should be dropped", func(t *testing.T) {
+		stale := time.Now().UTC().AddDate(0, 0, -10).Format("2006-01-02")
+		h.redisClient.HSet(ctx, baselineKey, stale, 1000000)
+
+		h.Consume(ctx, "feature1", "spike-user")
+		require.False(t, h.redisClient.HExists(ctx, baselineKey, stale).Val())
+	})
+
+	t.Run("The spike detector should require the time series", func(t *testing.T) {
+		_, err := New(&Config{RedisAddress: "localhost:6379"}, WithSpikeDetector(10, time.Minute, func(string, string, float64) {}))
+		require.ErrorIs(t, err, ErrTimeSeriesRequired)
+	})
+}
@@ -0,0 +1,85 @@
+package hourglass
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type statusResponse struct {
+	Feature   string    `json:"feature"`
+	User      string    `json:"user"`
+	Current   int       `json:"current"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+type featureStatus struct {
+	Limit       int `json:"limit"`
+	ActiveUsers int `json:"active_users"`
+}
+
+type statusJSON struct {
+	Features map[string]featureStatus `json:"features"`
+}
+
+// StatusJSON writes every configured feature with its limit and the number of
+// users active today as a JSON object. Counting users scans the keyspace once
+// per feature.
+func (hg *HourGlass) StatusJSON(ctx context.Context, w io.Writer) error {
+	status := statusJSON{Features: map[string]featureStatus{}}
+
+	for featureName, limit := range hg.limitProvider.Limits() {
+		users, err := hg.ActiveUsers(ctx, featureName)
+		if err != nil {
+			return err
+		}
+		status.Features[featureName] = featureStatus{Limit: limit, ActiveUsers: len(users)}
+	}
+
+	return json.NewEncoder(w).Encode(status)
+}
+
+// NewStatusHandler returns a handler that reports the usage of the user and
+// feature given by the "user" and "feature" query parameters as JSON.
+func NewStatusHandler(hg *HourGlass) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userName := r.URL.Query().Get("user")
+		featureName := r.URL.Query().Get("feature")
+		if userName == "" || featureName == "" {
+			http.Error(w, "user and feature query parameters are required", http.StatusBadRequest)
+			return
+		}
+
+		current, limit, err := hg.get(r.Context(), featureName, userName)
+		switch {
+		case errors.Is(err, ErrUnknownFeature):
+			http.Error(w, "unknown feature", http.StatusNotFound)
+			return
+		case errors.Is(err, ErrInvalidUsername):
+			http.Error(w, ErrInvalidUsername.Error(), http.StatusBadRequest)
+			return
+		case errors.Is(err, redis.Nil):
+			current = 0
+		case err != nil:
+			http.Error(w, "rate limit store unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(statusResponse{
+			Feature:   featureName,
+			User:      userName,
+			Current:   current,
+			Limit:     limit,
+			Remaining: max(limit-current, 0),
+			ResetsAt:  hg.userWindowEnd(r.Context(), featureName, userName),
+		})
+	})
+}
@@ -0,0 +1,131 @@
+package hourglass
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusHandler(t *testing.T) {
+	limits := map[string]int{
+		"feature1": 5,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:    "localhost:6379",
+		RedisPassword:   "",
+		Limits:          limits,
+		UserNamePattern: DefaultUserNamePattern,
+	})
+
+	require.Nil(t, err)
+
+	h.redisClient.Set(ctx, dailyKey("feature1", "status-user"), 2, 1*time.Minute)
+	h.redisClient.Del(ctx, dailyKey("feature1", "status-new-user"))
+
+	handler := NewStatusHandler(h)
+
+	tt := []struct {
+		description       string
+		query             string
+		expectedStatus    int
+		expectedCurrent   int
+		expectedRemaining int
+	}{
+		{
+			description:       "For an existing feature, the usage should be returned",
+			query:             "?user=status-user&feature=feature1",
+			expectedStatus:    http.StatusOK,
+			expectedCurrent:   2,
+			expectedRemaining: 3,
+		},
+		{
+			description:       "For a user without usage, the current value should be zero",
+			query:             "?user=status-new-user&feature=feature1",
+			expectedStatus:    http.StatusOK,
+			expectedCurrent:   0,
+			expectedRemaining: 5,
+		},
+		{
+			description:    "For a non-existing feature, 404 should be returned",
+			query:          "?user=status-user&feature=feature-notexistent",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			description:    "For an invalid user name, 400 should be returned",
+			query:          "?user=bad%20user&feature=feature1",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			description:    "Without query parameters, 400 should be returned",
+			query:          "",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rate-limits"+test.query, nil))
+
+			require.Equal(t, test.expectedStatus, rec.Code)
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var body statusResponse
+			require.Nil(t, json.NewDecoder(rec.Body).Decode(&body))
+			require.Equal(t, "feature1", body.Feature)
+			require.Equal(t, test.expectedCurrent, body.Current)
+			require.Equal(t, 5, body.Limit)
+			require.Equal(t, test.expectedRemaining, body.Remaining)
+		})
+	}
+
+	t.Run("When redis is unavailable, 503 should be returned", func(t *testing.T) {
+		require.Nil(t, h.Close())
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rate-limits?user=status-user&feature=feature1", nil))
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
+
+func TestStatusJSON(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+			"feature2": 3,
+		},
+		KeyPrefix: "statusjson:",
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	for _, userName := range []string{"alice", "bob"} {
+		h.redisClient.Del(ctx, "statusjson:"+dailyKey("feature1", userName), "statusjson:"+dailyKey("feature2", userName))
+		h.Consume(ctx, "feature1", userName)
+	}
+
+	var buf bytes.Buffer
+	require.Nil(t, h.StatusJSON(ctx, &buf))
+
+	require.JSONEq(t, `{"features": {
+		"feature1": {"limit": 5, "active_users": 2},
+		"feature2": {"limit": 3, "active_users": 0}
+	}}`, buf.String())
+}
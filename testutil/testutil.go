@@ -0,0 +1,46 @@
+// Package testutil helps tests of code that uses hourglass.
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"hourglass"
+)
+
+// WithSeedUsage sets the counters in seeds before a test runs, so it can
+// start from known usage. Seeds are keyed by {feature}:{user}:{date} with
+// today's UTC date, like the unprefixed counter keys. It panics when a seed
+// cannot be written, since a test cannot run on a partial setup, and returns
+// ctx for chaining.
+func WithSeedUsage(ctx context.Context, hg *hourglass.HourGlass, seeds map[string]int) context.Context {
+	for key, count := range seeds {
+		featureName, userName, err := parseSeedKey(key)
+		if err != nil {
+			panic(err)
+		}
+		if err := hg.SetUsage(ctx, featureName, userName, count); err != nil {
+			panic(fmt.Errorf("testutil: seed %q: %w", key, err))
+		}
+	}
+
+	return ctx
+}
+
+// parseSeedKey splits a {feature}:{user}:{date} key. The user is everything
+// between the first and the last colon.
+func parseSeedKey(key string) (featureName, userName string, err error) {
+	featureName, rest, found := strings.Cut(key, ":")
+	separator := strings.LastIndex(rest, ":")
+	if !found || separator <= 0 {
+		return "", "", fmt.Errorf("testutil: seed %q is not a {feature}:{user}:{date} key", key)
+	}
+
+	if today := time.Now().UTC().Format("2006-01-02"); rest[separator+1:] != today {
+		return "", "", fmt.Errorf("testutil: seed %q is not for today, %s", key, today)
+	}
+
+	return featureName, rest[:separator], nil
+}
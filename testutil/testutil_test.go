@@ -0,0 +1,91 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"hourglass"
+)
+
+func TestParseSeedKey(t *testing.T) {
+	today := time.Now().UTC().Format("2006-01-02")
+
+	tt := []struct {
+		description     string
+		key             string
+		expectedFeature string
+		expectedUser    string
+		expectErr       bool
+	}{
+		{
+			description:     "A key should split into its feature and user",
+			key:             "feature1:alice:" + today,
+			expectedFeature: "feature1",
+			expectedUser:    "alice",
+		},
+		{
+			description:     "A user with colons should be kept whole",
+			key:             "feature1:org:alice:" + today,
+			expectedFeature: "feature1",
+			expectedUser:    "org:alice",
+		},
+		{
+			description: "A key for another day should fail",
+			key:         "feature1:alice:2000-01-01",
+			expectErr:   true,
+		},
+		{
+			description: "A key without a user should fail",
+			key:         "feature1:" + today,
+			expectErr:   true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			featureName, userName, err := parseSeedKey(tc.key)
+			require.Equal(t, tc.expectErr, err != nil)
+			require.Equal(t, tc.expectedFeature, featureName)
+			require.Equal(t, tc.expectedUser, userName)
+		})
+	}
+}
+
+func TestWithSeedUsage(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := hourglass.New(&hourglass.Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	today := time.Now().UTC().Format("2006-01-02")
+	ctx = WithSeedUsage(ctx, h, map[string]int{
+		"feature1:seeded-user:" + today: 4,
+	})
+
+	t.Run("Consume should start from the seeded usage", func(t *testing.T) {
+		result, err := h.Consume(ctx, "feature1", "seeded-user")
+		require.Nil(t, err)
+		require.Equal(t, 5, result.Current)
+
+		result, err = h.Consume(ctx, "feature1", "seeded-user")
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("A seed that cannot be written should panic", func(t *testing.T) {
+		require.Panics(t, func() {
+			WithSeedUsage(ctx, h, map[string]int{"feature-notexistent:seeded-user:" + today: 1})
+		})
+	})
+}
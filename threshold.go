@@ -0,0 +1,48 @@
+package hourglass
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+)
+
+// DefaultThresholdWarnings are the usage fractions logged when
+// Config.ThresholdWarnings is nil.
+var DefaultThresholdWarnings = []float64{0.8, 0.9, 1.0}
+
+func (hg *HourGlass) thresholdWarnings() []float64 {
+	if hg.appConfig.ThresholdWarnings == nil {
+		return DefaultThresholdWarnings
+	}
+
+	return hg.appConfig.ThresholdWarnings
+}
+
+// warnThresholds logs every threshold that the consume which produced result
+// crossed. A marker stored next to the counter until the window ends keeps a
+// threshold from firing again after the user is credited and crosses it once
+// more.
+func (hg *HourGlass) warnThresholds(ctx context.Context, featureName, userName string, result ConsumeResult) {
+	for _, threshold := range hg.thresholdWarnings() {
+		if threshold <= 0 || result.Current != max(int(math.Ceil(threshold*float64(result.Limit))), 1) {
+			continue
+		}
+
+		// Counters of a counter backend only grow, so they cross each
+		// threshold once per window without a marker.
+		if hg.backend == nil {
+			ttl := time.Until(result.ResetsAt)
+			if ttl <= 0 {
+				ttl = hg.ttlFor(ctx, featureName, userName)
+			}
+			key, _, _ := hg.lookup(ctx, featureName, userName)
+			first, err := hg.redisClient.SetNX(ctx, key+":threshold:"+strconv.FormatFloat(threshold, 'f', -1, 64), 1, ttl).Result()
+			if err == nil && !first {
+				continue
+			}
+		}
+
+		hg.logger.WarnContext(ctx, "rate limit threshold reached", "feature", featureName, "user", userName, "pct", 100*float64(result.Current)/float64(result.Limit))
+	}
+}
@@ -0,0 +1,95 @@
+package hourglass
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdWarnings(t *testing.T) {
+	ctx := context.Background()
+
+	tt := []struct {
+		description  string
+		thresholds   []float64
+		consumes     int
+		credits      int
+		expectedPcts []float64
+	}{
+		{
+			description:  "The default thresholds should each fire once",
+			consumes:     12,
+			expectedPcts: []float64{80, 90, 100},
+		},
+		{
+			description:  "Custom thresholds should replace the defaults",
+			thresholds:   []float64{0.5},
+			consumes:     10,
+			expectedPcts: []float64{50},
+		},
+		{
+			description: "An empty slice should turn warnings off",
+			thresholds:  []float64{},
+			consumes:    10,
+		},
+		{
+			description:  "Crossing a threshold again after a credit should not fire again",
+			consumes:     8,
+			credits:      1,
+			expectedPcts: []float64{80},
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			var buf bytes.Buffer
+			h, err := New(&Config{
+				RedisAddress:      "localhost:6379",
+				Limits:            map[string]int{"threshold": 10},
+				KeyPrefix:         "threshold-test:",
+				ThresholdWarnings: test.thresholds,
+			}, WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+			require.NoError(t, err)
+			defer h.Close()
+
+			keys, _ := h.redisClient.Keys(ctx, "threshold-test:*").Result()
+			if len(keys) > 0 {
+				h.redisClient.Del(ctx, keys...)
+			}
+
+			for range test.consumes {
+				_, err := h.Consume(ctx, "threshold", "alice")
+				require.NoError(t, err)
+			}
+			for range test.credits {
+				h.Credit(ctx, "threshold", "alice")
+				_, err := h.Consume(ctx, "threshold", "alice")
+				require.NoError(t, err)
+			}
+
+			var pcts []float64
+			decoder := json.NewDecoder(&buf)
+			for decoder.More() {
+				var record struct {
+					Msg     string  `json:"msg"`
+					Feature string  `json:"feature"`
+					User    string  `json:"user"`
+					Pct     float64 `json:"pct"`
+				}
+				require.NoError(t, decoder.Decode(&record))
+				if record.Msg != "rate limit threshold reached" {
+					continue
+				}
+				require.Equal(t, "threshold", record.Feature)
+				require.Equal(t, "alice", record.User)
+				pcts = append(pcts, record.Pct)
+			}
+
+			require.Equal(t, test.expectedPcts, pcts)
+		})
+	}
+}
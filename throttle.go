@@ -0,0 +1,88 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"time"
+)
+
+//go:embed throttle.lua
+var throttleScriptData string
+
+// throttleKey returns the token bucket shared by all callers of featureName.
+func (hg *HourGlass) throttleKey(ctx context.Context, featureName string) string {
+	return hg.keyPrefix(ctx) + "throttle:" + featureName
+}
+
+// takeToken takes a token from the bucket of featureName. The bucket holds
+// up to the feature's limit and refills at the limit per window. When no
+// token is left it returns how long until the next one.
+func (hg *HourGlass) takeToken(ctx context.Context, featureName string) (taken bool, wait time.Duration, err error) {
+	limit, exists := hg.limitProvider.Limit(featureName)
+	if !exists {
+		return false, 0, ErrUnknownFeature
+	}
+	if limit <= 0 {
+		return false, 0, ErrLimitExceeded
+	}
+
+	window := hg.appConfig.Features[featureName].Window
+	if window <= 0 {
+		window = day
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		return false, 0, err
+	}
+
+	perMillisecond := float64(limit) / float64(window.Milliseconds())
+	reply, err := hg.throttleScript.Run(ctx, hg.redisClient, []string{hg.throttleKey(ctx, featureName)}, limit, perMillisecond, time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+
+	return reply[0] == 1, time.Duration(reply[1]) * time.Millisecond, nil
+}
+
+// Throttle blocks until featureName may make another call, for rate limiting
+// outbound calls rather than budgeting quota. Calls are spread by a token
+// bucket shared by all instances that holds up to the feature's limit and
+// refills at the limit per window, e.g. "10/s" for ten calls a second. It
+// returns ctx.Err() when ctx is done first, ErrUnknownFeature for unknown
+// features and ErrLimitExceeded for features with a limit of zero.
+func (hg *HourGlass) Throttle(ctx context.Context, featureName string) error {
+	for {
+		taken, wait, err := hg.takeToken(ctx, featureName)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// TryThrottle is Throttle without waiting: it reports whether a token was
+// available. Unknown features report false and Redis failures are answered
+// by the failure mode.
+func (hg *HourGlass) TryThrottle(ctx context.Context, featureName string) bool {
+	taken, _, err := hg.takeToken(ctx, featureName)
+	if errors.Is(err, ErrUnknownFeature) || errors.Is(err, ErrLimitExceeded) {
+		return false
+	}
+	if err != nil {
+		hg.logger.WarnContext(ctx, "failed to take throttle token", "feature", featureName, "error", err)
+		return hg.failureMode == FailOpen
+	}
+
+	return taken
+}
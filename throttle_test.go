@@ -0,0 +1,77 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottle(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature2": 0,
+		},
+		LimitExpressions: map[string]string{
+			"feature1": "20/s",
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, h.throttleKey(ctx, "feature1"))
+
+	t.Run("TryThrottle should allow calls while the bucket has tokens", func(t *testing.T) {
+		for range 20 {
+			require.True(t, h.TryThrottle(ctx, "feature1"))
+		}
+		require.False(t, h.TryThrottle(ctx, "feature1"))
+	})
+
+	t.Run("Throttle should wait for the next token", func(t *testing.T) {
+		start := time.Now()
+		require.Nil(t, h.Throttle(ctx, "feature1"))
+		require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("Throttle should stop waiting when the context is done", func(t *testing.T) {
+		h.redisClient.Del(ctx, h.throttleKey(ctx, "feature1"))
+		for range 20 {
+			h.TryThrottle(ctx, "feature1")
+		}
+
+		timeoutCtx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, h.Throttle(timeoutCtx, "feature1"), context.DeadlineExceeded)
+	})
+
+	tt := []struct {
+		description string
+		featureName string
+		expectedErr error
+	}{
+		{
+			description: "An unknown feature should fail",
+			featureName: "feature-notexistent",
+			expectedErr: ErrUnknownFeature,
+		},
+		{
+			description: "A feature with a limit of zero should fail",
+			featureName: "feature2",
+			expectedErr: ErrLimitExceeded,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			require.ErrorIs(t, h.Throttle(ctx, tc.featureName), tc.expectedErr)
+			require.False(t, h.TryThrottle(ctx, tc.featureName))
+		})
+	}
+}
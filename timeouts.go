@@ -0,0 +1,81 @@
+package hourglass
+
+import "time"
+
+// timeouts holds connection timeouts set through options. They take
+// precedence over the matching Config fields when New creates the pool.
+type timeouts struct {
+	base  time.Duration
+	dial  time.Duration
+	read  time.Duration
+	write time.Duration
+	pool  time.Duration
+}
+
+// WithBaseTimeout derives the connection timeouts from base: DialTimeout is
+// 2*base, ReadTimeout and WriteTimeout are base and PoolTimeout is base plus
+// 500ms. WithDialTimeout, WithReadTimeout, WithWriteTimeout and
+// WithPoolTimeout override the derived values regardless of order.
+func WithBaseTimeout(base time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.timeouts.base = base
+	}
+}
+
+// WithDialTimeout sets how long connecting to Redis may take. It overrides
+// Config.DialTimeout and the value derived by WithBaseTimeout.
+func WithDialTimeout(d time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.timeouts.dial = d
+	}
+}
+
+// WithReadTimeout sets how long reading a reply from Redis may take. It
+// overrides Config.ReadTimeout and the value derived by WithBaseTimeout.
+func WithReadTimeout(d time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.timeouts.read = d
+	}
+}
+
+// WithWriteTimeout sets how long writing a command to Redis may take. It
+// overrides Config.WriteTimeout and the value derived by WithBaseTimeout.
+func WithWriteTimeout(d time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.timeouts.write = d
+	}
+}
+
+// WithPoolTimeout sets how long a command waits for a free connection. It
+// overrides Config.PoolTimeout and the value derived by WithBaseTimeout.
+func WithPoolTimeout(d time.Duration) Option {
+	return func(hg *HourGlass) {
+		hg.timeouts.pool = d
+	}
+}
+
+// apply returns a copy of config with the timeouts set, leaving the
+// caller's Config as it was for reuse.
+func (t timeouts) apply(config *Config) *Config {
+	applied := *config
+	if t.base > 0 {
+		applied.DialTimeout = 2 * t.base
+		applied.ReadTimeout = t.base
+		applied.WriteTimeout = t.base
+		applied.PoolTimeout = t.base + 500*time.Millisecond
+	}
+	if t.dial > 0 {
+		applied.DialTimeout = t.dial
+	}
+	if t.read > 0 {
+		applied.ReadTimeout = t.read
+	}
+	if t.write > 0 {
+		applied.WriteTimeout = t.write
+	}
+	if t.pool > 0 {
+		applied.PoolTimeout = t.pool
+	}
+
+	return &applied
+}
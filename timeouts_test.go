@@ -0,0 +1,85 @@
+package hourglass
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutOptions(t *testing.T) {
+	tt := []struct {
+		description   string
+		opts          []Option
+		expectedDial  time.Duration
+		expectedRead  time.Duration
+		expectedWrite time.Duration
+		expectedPool  time.Duration
+	}{
+		{
+			description:   "Without options the defaults should be used",
+			expectedDial:  5 * time.Second,
+			expectedRead:  3 * time.Second,
+			expectedWrite: 3 * time.Second,
+			expectedPool:  4 * time.Second,
+		},
+		{
+			description:   "The base timeout should derive all timeouts",
+			opts:          []Option{WithBaseTimeout(time.Second)},
+			expectedDial:  2 * time.Second,
+			expectedRead:  time.Second,
+			expectedWrite: time.Second,
+			expectedPool:  1500 * time.Millisecond,
+		},
+		{
+			description:   "Individual timeouts should override the base in any order",
+			opts:          []Option{WithReadTimeout(200 * time.Millisecond), WithBaseTimeout(time.Second), WithPoolTimeout(3 * time.Second)},
+			expectedDial:  2 * time.Second,
+			expectedRead:  200 * time.Millisecond,
+			expectedWrite: time.Second,
+			expectedPool:  3 * time.Second,
+		},
+		{
+			description:   "Individual timeouts should work without a base",
+			opts:          []Option{WithDialTimeout(time.Second), WithWriteTimeout(2 * time.Second)},
+			expectedDial:  time.Second,
+			expectedRead:  3 * time.Second,
+			expectedWrite: 2 * time.Second,
+			expectedPool:  4 * time.Second,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			h, err := New(&Config{
+				RedisAddress:  "localhost:6379",
+				RedisPassword: "",
+			}, tc.opts...)
+			require.Nil(t, err)
+			defer h.Close()
+
+			options := h.redisClient.Options()
+			require.Equal(t, tc.expectedDial, options.DialTimeout)
+			require.Equal(t, tc.expectedRead, options.ReadTimeout)
+			require.Equal(t, tc.expectedWrite, options.WriteTimeout)
+			require.Equal(t, tc.expectedPool, options.PoolTimeout)
+		})
+	}
+}
+
+func TestTimeoutOptionsLeaveConfigUnchanged(t *testing.T) {
+	config := &Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+	}
+
+	h, err := New(config, WithBaseTimeout(time.Second))
+	require.Nil(t, err)
+	defer h.Close()
+	require.Zero(t, config.ReadTimeout)
+
+	reused, err := New(config)
+	require.Nil(t, err)
+	defer reused.Close()
+	require.Equal(t, 3*time.Second, reused.redisClient.Options().ReadTimeout)
+}
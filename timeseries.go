@@ -0,0 +1,64 @@
+package hourglass
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func (hg *HourGlass) timeSeriesKey(ctx context.Context, featureName, userName string) string {
+	return fmt.Sprintf("%s%s:%s:ts", hg.keyPrefix(ctx), featureName, hg.keyUser(userName))
+}
+
+func newToken() (string, error) {
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(tokenBytes), nil
+}
+
+func (hg *HourGlass) recordTimeSeries(ctx context.Context, featureName, userName string) error {
+	member, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	key := hg.timeSeriesKey(ctx, featureName, userName)
+	oldest := now.Add(-hg.timeSeriesRetention).UnixMilli()
+
+	_, err = hg.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixMilli()), Member: member})
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(oldest, 10))
+		pipe.Expire(ctx, key, hg.timeSeriesRetention)
+		return nil
+	})
+
+	return err
+}
+
+// QueryTimeSeries returns the time of every successful consume of featureName
+// by userName between from and to, inclusive. It requires WithTimeSeries.
+func (hg *HourGlass) QueryTimeSeries(ctx context.Context, featureName, userName string, from, to time.Time) ([]time.Time, error) {
+	scores, err := hg.redisClient.ZRangeByScoreWithScores(ctx, hg.timeSeriesKey(ctx, featureName, userName), &redis.ZRangeBy{
+		Min: strconv.FormatInt(from.UnixMilli(), 10),
+		Max: strconv.FormatInt(to.UnixMilli(), 10),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]time.Time, 0, len(scores))
+	for _, score := range scores {
+		events = append(events, time.UnixMilli(int64(score.Score)).UTC())
+	}
+
+	return events, nil
+}
@@ -0,0 +1,49 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryTimeSeries(t *testing.T) {
+	limits := map[string]int{
+		"feature1": 2,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+	}, WithTimeSeries(1*time.Hour))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Del(ctx, dailyKey("feature1", "ts-user"), h.timeSeriesKey(ctx, "feature1", "ts-user"))
+
+	start := time.Now().Add(-1 * time.Second)
+	for i := 0; i < 3; i++ {
+		h.Consume(ctx, "feature1", "ts-user")
+	}
+	end := time.Now().Add(1 * time.Second)
+
+	t.Run("Only allowed consumes in the range should be returned", func(t *testing.T) {
+		events, err := h.QueryTimeSeries(ctx, "feature1", "ts-user", start, end)
+		require.Nil(t, err)
+		require.Len(t, events, 2)
+		for _, event := range events {
+			require.WithinRange(t, event, start, end)
+		}
+	})
+
+	t.Run("A range without consumes should return no events", func(t *testing.T) {
+		events, err := h.QueryTimeSeries(ctx, "feature1", "ts-user", start.Add(-2*time.Hour), start.Add(-1*time.Hour))
+		require.Nil(t, err)
+		require.Empty(t, events)
+	})
+}
@@ -0,0 +1,89 @@
+package hourglass
+
+import (
+	"context"
+	"time"
+)
+
+type userLocation struct {
+	userName string
+	location *time.Location
+}
+
+// withUserLocation looks up the time zone of userName once and returns a
+// copy of ctx that carries it for the rest of the call.
+func (hg *HourGlass) withUserLocation(ctx context.Context, userName string) context.Context {
+	if hg.appConfig.UserTimezone == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, userLocationContextKey, userLocation{userName: userName, location: hg.userLocation(ctx, userName)})
+}
+
+// userLocation returns the time zone of userName from Config.UserTimezone,
+// or UTC.
+func (hg *HourGlass) userLocation(ctx context.Context, userName string) *time.Location {
+	if hg.appConfig.UserTimezone == nil {
+		return time.UTC
+	}
+	if cached, ok := ctx.Value(userLocationContextKey).(userLocation); ok && cached.userName == userName {
+		return cached.location
+	}
+
+	location := hg.appConfig.UserTimezone(ctx, userName)
+	if location == nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// userWindowEnd returns when the current window of featureName resets for
+// userName, at midnight in the user's time zone for daily windows.
+func (hg *HourGlass) userWindowEnd(ctx context.Context, featureName, userName string) time.Time {
+	if _, windowed := hg.windowStart(featureName); windowed {
+		return hg.windowEnd(featureName)
+	}
+
+	return endOfDayIn(hg.userLocation(ctx, userName))
+}
+
+// userWindowIDAt is windowIDAt for userName, using the date in the user's
+// time zone for daily windows.
+func (hg *HourGlass) userWindowIDAt(ctx context.Context, featureName, userName string, at time.Time) string {
+	if _, windowed := hg.windowStartAt(featureName, at); windowed {
+		return hg.windowIDAt(featureName, at)
+	}
+
+	return at.In(hg.userLocation(ctx, userName)).Format("2006-01-02")
+}
+
+// Time zones range from UTC-12 to UTC+14, so at any moment users can be on
+// up to three different dates.
+var (
+	earliestZone = time.FixedZone("UTC-12", -12*60*60)
+	latestZone   = time.FixedZone("UTC+14", 14*60*60)
+)
+
+// windowIDsAt returns every ID the window of featureName containing at can
+// have across users. It is the single windowIDAt unless daily windows follow
+// Config.UserTimezone, in which case it is every date from UTC-12 to UTC+14.
+func (hg *HourGlass) windowIDsAt(featureName string, at time.Time) []string {
+	if _, windowed := hg.windowStartAt(featureName, at); windowed || hg.appConfig.UserTimezone == nil {
+		return []string{hg.windowIDAt(featureName, at)}
+	}
+
+	earliest := at.In(earliestZone)
+	date := time.Date(earliest.Year(), earliest.Month(), earliest.Day(), 0, 0, 0, 0, time.UTC)
+	last := at.In(latestZone).Format("2006-01-02")
+
+	var ids []string
+	for {
+		id := date.Format("2006-01-02")
+		ids = append(ids, id)
+		if id == last {
+			return ids
+		}
+		date = date.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,131 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserTimezone(t *testing.T) {
+	ctx := context.Background()
+
+	kiritimati := time.FixedZone("LINT", 14*60*60)
+	var lookups int
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+		},
+		UserTimezone: func(ctx context.Context, userName string) *time.Location {
+			lookups++
+			if userName == "tz-user" {
+				return kiritimati
+			}
+			return nil
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	tt := []struct {
+		description      string
+		userName         string
+		location         *time.Location
+		expectedResetsAt time.Time
+	}{
+		{
+			description:      "A user with a time zone should reset at their midnight",
+			userName:         "tz-user",
+			location:         kiritimati,
+			expectedResetsAt: endOfDayIn(kiritimati),
+		},
+		{
+			description:      "A user without a time zone should reset at midnight UTC",
+			userName:         "utc-user",
+			location:         time.UTC,
+			expectedResetsAt: endOfDay(),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			key := "feature1:" + tc.userName + ":" + time.Now().In(tc.location).Format("2006-01-02")
+			h.redisClient.Del(ctx, key)
+			lookups = 0
+
+			result, err := h.Consume(ctx, "feature1", tc.userName)
+			require.Nil(t, err)
+			require.Equal(t, 1, lookups)
+			require.True(t, tc.expectedResetsAt.Equal(result.ResetsAt))
+
+			current, err := h.redisClient.Get(ctx, key).Int()
+			require.Nil(t, err)
+			require.Equal(t, 1, current)
+			require.InDelta(t, time.Until(tc.expectedResetsAt).Seconds(), h.redisClient.TTL(ctx, key).Val().Seconds(), 2)
+		})
+	}
+}
+
+func TestUserTimezoneHelpers(t *testing.T) {
+	ctx := context.Background()
+
+	zones := map[string]*time.Location{
+		"east-user": time.FixedZone("UTC+14", 14*60*60),
+		"west-user": time.FixedZone("UTC-12", -12*60*60),
+	}
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"tz-helpers": 5,
+		},
+		Whitelist: []string{"west-user"},
+		UserTimezone: func(ctx context.Context, userName string) *time.Location {
+			return zones[userName]
+		},
+	})
+	require.Nil(t, err)
+	defer h.Close()
+
+	keyFor := func(userName string) string {
+		return "tz-helpers:" + userName + ":" + time.Now().In(zones[userName]).Format("2006-01-02")
+	}
+
+	t.Run("Users on another date than UTC should be active", func(t *testing.T) {
+		// Yesterday's counter of the eastern user is not active anymore.
+		yesterday := "tz-helpers:east-user:" + time.Now().In(zones["east-user"]).AddDate(0, 0, -1).Format("2006-01-02")
+		h.redisClient.Set(ctx, keyFor("east-user"), 2, time.Hour)
+		h.redisClient.Set(ctx, keyFor("west-user"), 3, time.Hour)
+		h.redisClient.Set(ctx, yesterday, 4, time.Hour)
+		defer h.redisClient.Del(ctx, keyFor("east-user"), keyFor("west-user"), yesterday)
+
+		users, err := h.ActiveUsers(ctx, "tz-helpers")
+		require.Nil(t, err)
+		require.ElementsMatch(t, []string{"east-user", "west-user"}, users)
+
+		total, err := h.TotalConsumed(ctx, "tz-helpers")
+		require.Nil(t, err)
+		require.Equal(t, int64(5), total)
+	})
+
+	t.Run("A whitelisted user should reset at their midnight", func(t *testing.T) {
+		result, err := h.Consume(ctx, "tz-helpers", "west-user")
+		require.Nil(t, err)
+		require.True(t, endOfDayIn(zones["west-user"]).Equal(result.ResetsAt))
+	})
+
+	t.Run("A scheduled reset should delete the user's local day", func(t *testing.T) {
+		h.redisClient.Set(ctx, keyFor("east-user"), 2, time.Hour)
+		defer h.redisClient.Del(ctx, keyFor("east-user"))
+
+		require.Nil(t, h.ScheduleReset(ctx, "tz-helpers", "east-user", time.Now().Add(-time.Second)))
+		processed, err := h.ProcessScheduledResets(ctx)
+		require.Nil(t, err)
+		require.Equal(t, int64(1), processed)
+		require.Equal(t, int64(0), h.redisClient.Exists(ctx, keyFor("east-user")).Val())
+	})
+}
@@ -0,0 +1,44 @@
+package hourglass
+
+import (
+	"context"
+	_ "embed"
+)
+
+//go:embed transfer.lua
+var transferScriptData string
+
+// TransferCredit moves amount units of consumed quota from fromUser to toUser
+// for featureName. Neither counter is allowed to drop below zero or rise above
+// the feature limit.
+func (hg *HourGlass) TransferCredit(ctx context.Context, featureName, fromUser, toUser string, amount int) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	fromKey, _, exists := hg.lookup(ctx, featureName, fromUser)
+	if !exists {
+		return ErrUnknownFeature
+	}
+	toKey, limit, exists := hg.lookup(ctx, featureName, toUser)
+	if !exists {
+		return ErrUnknownFeature
+	}
+
+	ttl := ttlMillis(hg.ttlFor(ctx, featureName, toUser))
+	keys := []string{fromKey, toKey}
+
+	result, err := hg.transferScript.Run(ctx, hg.redisClient, keys, amount, limit, ttl).Int64Slice()
+	if err != nil {
+		return err
+	}
+
+	switch result[0] {
+	case 1:
+		return ErrInsufficientCredit
+	case 2:
+		return ErrLimitExceeded
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+local from_key = KEYS[1]
+local to_key = KEYS[2]
+local amount = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local ttl_ms = tonumber(ARGV[3])
+
+local from_current = tonumber(redis.call('GET', from_key) or '0')
+local to_current = tonumber(redis.call('GET', to_key) or '0')
+
+if from_current < amount then
+    return {1, from_current, to_current}
+end
+
+if to_current + amount > limit then
+    return {2, from_current, to_current}
+end
+
+local from_new = redis.call('DECRBY', from_key, amount)
+local to_new = redis.call('INCRBY', to_key, amount)
+if redis.call('PTTL', to_key) == -1 then
+    redis.call('PEXPIRE', to_key, ttl_ms)
+end
+
+return {0, from_new, to_new}
@@ -0,0 +1,100 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferCredit(t *testing.T) {
+	limits := map[string]int{
+		"feature1": 5,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	tt := []struct {
+		description      string
+		featureName      string
+		amount           int
+		existingFrom     int
+		existingTo       int
+		expectedErr      error
+		expectedFromUser int
+		expectedToUser   int
+	}{
+		{
+			description:      "When the sender has enough credit, the amount should move between users",
+			featureName:      "feature1",
+			amount:           2,
+			existingFrom:     3,
+			existingTo:       1,
+			expectedFromUser: 1,
+			expectedToUser:   3,
+		},
+		{
+			description:      "When the sender has too little credit, nothing should change",
+			featureName:      "feature1",
+			amount:           4,
+			existingFrom:     3,
+			existingTo:       1,
+			expectedErr:      ErrInsufficientCredit,
+			expectedFromUser: 3,
+			expectedToUser:   1,
+		},
+		{
+			description:      "When the receiver would go above the limit, nothing should change",
+			featureName:      "feature1",
+			amount:           2,
+			existingFrom:     3,
+			existingTo:       4,
+			expectedErr:      ErrLimitExceeded,
+			expectedFromUser: 3,
+			expectedToUser:   4,
+		},
+		{
+			description:      "For a non-existing feature, an error should be returned",
+			featureName:      "feature-notexistent",
+			amount:           1,
+			expectedErr:      ErrUnknownFeature,
+			expectedFromUser: -1,
+			expectedToUser:   -1,
+		},
+		{
+			description:      "A non-positive amount should be rejected",
+			featureName:      "feature1",
+			amount:           0,
+			existingFrom:     3,
+			existingTo:       1,
+			expectedErr:      ErrInvalidAmount,
+			expectedFromUser: 3,
+			expectedToUser:   1,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			h.redisClient.Set(ctx, dailyKey("feature1", "from"), test.existingFrom, 1*time.Minute)
+			h.redisClient.Set(ctx, dailyKey("feature1", "to"), test.existingTo, 1*time.Minute)
+
+			err := h.TransferCredit(ctx, test.featureName, "from", "to", test.amount)
+			require.Equal(t, test.expectedErr, err)
+
+			from, _ := h.Get(ctx, test.featureName, "from")
+			to, _ := h.Get(ctx, test.featureName, "to")
+			require.Equal(t, test.expectedFromUser, from)
+			require.Equal(t, test.expectedToUser, to)
+		})
+	}
+}
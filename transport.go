@@ -0,0 +1,137 @@
+package hourglass
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxRateLimitWait bounds how long the transport waits on a single
+// Retry-After or X-RateLimit-Reset header.
+const maxRateLimitWait = time.Minute
+
+type rateLimitAwareTransport struct {
+	inner      http.RoundTripper
+	maxRetries int
+	maxWait    time.Duration
+
+	mu       sync.Mutex
+	resumeAt time.Time
+}
+
+// NewRateLimitAwareTransport wraps inner so that requests rejected with 429
+// and a Retry-After header are retried up to maxRetries times after the
+// requested wait. When a response reports X-RateLimit-Remaining: 0, the next
+// request is held back until X-RateLimit-Reset. Waits are capped at one
+// minute. A nil inner uses http.DefaultTransport.
+func NewRateLimitAwareTransport(inner http.RoundTripper, maxRetries int) http.RoundTripper {
+	if inner == nil {
+		inner = http.DefaultTransport
+	}
+
+	return &rateLimitAwareTransport{
+		inner:      inner,
+		maxRetries: maxRetries,
+		maxWait:    maxRateLimitWait,
+	}
+}
+
+func (t *rateLimitAwareTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	resumeAt := t.resumeAt
+	t.mu.Unlock()
+
+	if err := t.sleep(req.Context(), time.Until(resumeAt)); err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.inner.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		t.observe(resp)
+
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= t.maxRetries {
+			return resp, nil
+		}
+		wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"))
+		if !ok {
+			return resp, nil
+		}
+
+		// A request body can only be sent again if it can be recreated.
+		retry := req
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			retry = req.Clone(req.Context())
+			retry.Body = body
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		req = retry
+	}
+}
+
+// observe remembers when the server allows requests again once a response
+// reports that no quota is left.
+func (t *rateLimitAwareTransport) observe(resp *http.Response) {
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.resumeAt = time.Unix(reset, 0)
+	t.mu.Unlock()
+}
+
+func (t *rateLimitAwareTransport) sleep(ctx context.Context, wait time.Duration) error {
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(min(wait, t.maxWait))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,103 @@
+package hourglass
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitAwareTransport(t *testing.T) {
+	t.Run("A 429 with Retry-After should be retried", func(t *testing.T) {
+		var requests atomic.Int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: NewRateLimitAwareTransport(nil, 3)}
+		resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+		require.Nil(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, int64(3), requests.Load())
+	})
+
+	t.Run("Retries should stop after maxRetries", func(t *testing.T) {
+		var requests atomic.Int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: NewRateLimitAwareTransport(nil, 2)}
+		resp, err := client.Get(server.URL)
+		require.Nil(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.Equal(t, int64(3), requests.Load())
+	})
+
+	t.Run("A 429 without Retry-After should not be retried", func(t *testing.T) {
+		var requests atomic.Int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: NewRateLimitAwareTransport(nil, 2)}
+		resp, err := client.Get(server.URL)
+		require.Nil(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, int64(1), requests.Load())
+	})
+
+	t.Run("No remaining quota should hold back the next request until the reset", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		transport := NewRateLimitAwareTransport(nil, 0).(*rateLimitAwareTransport)
+		transport.maxWait = 100 * time.Millisecond
+		client := &http.Client{Transport: transport}
+
+		resp, err := client.Get(server.URL)
+		require.Nil(t, err)
+		resp.Body.Close()
+
+		start := time.Now()
+		resp, err = client.Get(server.URL)
+		require.Nil(t, err)
+		resp.Body.Close()
+
+		require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("Retry-After should accept an HTTP date", func(t *testing.T) {
+		wait, ok := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+		require.True(t, ok)
+		require.InDelta(t, time.Minute.Seconds(), wait.Seconds(), 2)
+
+		_, ok = parseRetryAfter("soon")
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,39 @@
+package hourglass
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ttlCounterKey returns the key of the counter used by ConsumeWithTTL. It has
+// no window in it, so the counter lives until its own TTL runs out.
+func (hg *HourGlass) ttlCounterKey(ctx context.Context, featureName, userName string) string {
+	keyFeature, _, _ := hg.lookupLimit(featureName, userName)
+	return fmt.Sprintf("%s%s:%s:ttl", hg.keyPrefix(ctx), keyFeature, hg.keyUser(userName))
+}
+
+// ttlCounterReset returns when the custom TTL counter at key expires, assuming
+// a fresh ttl if it cannot be read.
+func (hg *HourGlass) ttlCounterReset(ctx context.Context, key string, ttl time.Duration) time.Time {
+	remaining, err := hg.redisClient.PTTL(ctx, key).Result()
+	if err != nil || remaining <= 0 {
+		return time.Now().Add(ttl)
+	}
+
+	return time.Now().Add(remaining)
+}
+
+// ConsumeWithTTL consumes from a counter whose window is ttl from the first
+// call instead of the feature's configured window, e.g. a trial that resets
+// exactly 72 hours after first use. The TTL is only set when the counter is
+// created, later calls keep the original expiry. The counter is kept apart
+// from the one used by Consume. The TTL has millisecond precision, shorter
+// TTLs return ErrInvalidTTL.
+func (hg *HourGlass) ConsumeWithTTL(ctx context.Context, featureName, userName string, ttl time.Duration) (ConsumeResult, error) {
+	if ttl < time.Millisecond {
+		return ConsumeResult{Current: -1, Limit: -1, Allowed: false}, ErrInvalidTTL
+	}
+
+	return hg.consumeWith(ctx, featureName, userName, consumeOptions{ttl: ttl})
+}
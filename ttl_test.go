@@ -0,0 +1,78 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeWithTTL(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 2,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := h.ttlCounterKey(ctx, "feature1", "ttl-user")
+	h.redisClient.Del(ctx, key, dailyKey("feature1", "ttl-user"))
+
+	t.Run("A new counter should get the given TTL", func(t *testing.T) {
+		result, err := h.ConsumeWithTTL(ctx, "feature1", "ttl-user", 72*time.Hour)
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+		require.WithinDuration(t, time.Now().Add(72*time.Hour), result.ResetsAt, 5*time.Second)
+
+		ttl, err := h.redisClient.TTL(ctx, key).Result()
+		require.Nil(t, err)
+		require.InDelta(t, (72 * time.Hour).Seconds(), ttl.Seconds(), 5)
+	})
+
+	t.Run("An existing counter should keep its TTL", func(t *testing.T) {
+		result, err := h.ConsumeWithTTL(ctx, "feature1", "ttl-user", time.Hour)
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 2, result.Current)
+		require.WithinDuration(t, time.Now().Add(72*time.Hour), result.ResetsAt, 5*time.Second)
+
+		result, err = h.ConsumeWithTTL(ctx, "feature1", "ttl-user", time.Hour)
+		require.Nil(t, err)
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("The counter should be separate from Consume", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "ttl-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+	})
+
+	t.Run("A non positive TTL should be rejected", func(t *testing.T) {
+		_, err := h.ConsumeWithTTL(ctx, "feature1", "ttl-user", 0)
+		require.ErrorIs(t, err, ErrInvalidTTL)
+
+		_, err = h.ConsumeWithTTL(ctx, "feature1", "ttl-user", time.Microsecond)
+		require.ErrorIs(t, err, ErrInvalidTTL)
+	})
+
+	t.Run("A sub-second TTL should be kept in milliseconds", func(t *testing.T) {
+		key := h.ttlCounterKey(ctx, "feature1", "ttl-short-user")
+		h.redisClient.Del(ctx, key)
+
+		result, err := h.ConsumeWithTTL(ctx, "feature1", "ttl-short-user", 500*time.Millisecond)
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+
+		pttl := h.redisClient.PTTL(ctx, key).Val()
+		require.Greater(t, pttl, time.Duration(0))
+		require.LessOrEqual(t, pttl, 500*time.Millisecond)
+	})
+}
@@ -0,0 +1,105 @@
+package hourglass
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// UniqueUsers returns how many distinct users consumed featureName in the
+// current window and the windows-1 before it, e.g. the last 7 days. Users are
+// found by scanning for counters, so only windows whose counters are still in
+// Redis count. Users are deduplicated in memory, or in a temporary
+// HyperLogLog with Config.UseHyperLogLog. With WithHashedKeys hashed names
+// are counted, which does not change the result.
+func (hg *HourGlass) UniqueUsers(ctx context.Context, featureName string, windows int) (int64, error) {
+	if _, exists := hg.limitProvider.Limit(featureName); !exists {
+		return 0, ErrUnknownFeature
+	}
+	if windows <= 0 {
+		return 0, ErrInvalidAmount
+	}
+
+	window := hg.appConfig.Features[featureName].Window
+	if window <= 0 {
+		window = day
+	}
+	now := time.Now()
+
+	if hg.appConfig.UseHyperLogLog {
+		return hg.uniqueUsersHLL(ctx, featureName, windows, window, now)
+	}
+
+	seen := map[string]bool{}
+	for _, windowID := range hg.recentWindowIDs(featureName, windows, window, now) {
+		err := hg.scanCounters(ctx, featureName, windowID, func(counter activeCounter) {
+			seen[counter.keyUser] = true
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(seen)), nil
+}
+
+// uniqueUsersHLL adds the users of each window to a temporary HyperLogLog a
+// scan batch at a time and counts it.
+func (hg *HourGlass) uniqueUsersHLL(ctx context.Context, featureName string, windows int, window time.Duration, now time.Time) (int64, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return 0, err
+	}
+	hllKey := hg.keyPrefix(ctx) + "uniqueusers:" + featureName + ":" + hex.EncodeToString(suffix)
+	defer hg.redisClient.Del(context.WithoutCancel(ctx), hllKey)
+
+	batch := make([]any, 0, scanBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := hg.redisClient.PFAdd(ctx, hllKey, batch...).Err()
+		batch = batch[:0]
+		return err
+	}
+
+	for _, windowID := range hg.recentWindowIDs(featureName, windows, window, now) {
+		var addErr error
+		err := hg.scanCounters(ctx, featureName, windowID, func(counter activeCounter) {
+			batch = append(batch, counter.keyUser)
+			if len(batch) == scanBatchSize && addErr == nil {
+				addErr = flush()
+			}
+		})
+		if err == nil {
+			err = addErr
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	return hg.redisClient.PFCount(ctx, hllKey).Result()
+}
+
+// recentWindowIDs returns the IDs of the window of featureName containing now
+// and the windows-1 before it, including every date in use somewhere when
+// daily windows follow Config.UserTimezone.
+func (hg *HourGlass) recentWindowIDs(featureName string, windows int, window time.Duration, now time.Time) []string {
+	seen := map[string]bool{}
+	var ids []string
+	for i := range windows {
+		for _, id := range hg.windowIDsAt(featureName, now.Add(-time.Duration(i)*window)) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,97 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueUsers(t *testing.T) {
+	ctx := context.Background()
+
+	for _, useHyperLogLog := range []bool{false, true} {
+		h, err := New(&Config{
+			RedisAddress:   "localhost:6379",
+			Limits:         map[string]int{"unique": 10},
+			KeyPrefix:      "unique-test:",
+			UseHyperLogLog: useHyperLogLog,
+		})
+		require.NoError(t, err)
+		defer h.Close()
+
+		keys, _ := h.redisClient.Keys(ctx, "unique-test:*").Result()
+		if len(keys) > 0 {
+			h.redisClient.Del(ctx, keys...)
+		}
+
+		// Users per day, starting today.
+		days := [][]string{
+			{"alice", "bob"},
+			{"bob", "carol"},
+			{"dave"},
+			{"erin"},
+		}
+		for i, users := range days {
+			at := time.Now().Add(-time.Duration(i) * day)
+			for _, userName := range users {
+				h.redisClient.Set(ctx, h.KeyFor(ctx, "unique", userName, at, false), 1, time.Minute)
+			}
+		}
+
+		tt := []struct {
+			description   string
+			featureName   string
+			windows       int
+			expectedCount int64
+			expectedErr   error
+		}{
+			{
+				description:   "The current window should count its users",
+				featureName:   "unique",
+				windows:       1,
+				expectedCount: 2,
+			},
+			{
+				description:   "Users in several windows should be counted once",
+				featureName:   "unique",
+				windows:       3,
+				expectedCount: 4,
+			},
+			{
+				description:   "Windows older than requested should be left out",
+				featureName:   "unique",
+				windows:       2,
+				expectedCount: 3,
+			},
+			{
+				description: "A non-positive number of windows should fail",
+				featureName: "unique",
+				windows:     0,
+				expectedErr: ErrInvalidAmount,
+			},
+			{
+				description: "An unknown feature should fail",
+				featureName: "feature-notexistent",
+				windows:     1,
+				expectedErr: ErrUnknownFeature,
+			},
+		}
+
+		for _, test := range tt {
+			name := test.description
+			if useHyperLogLog {
+				name += " with HyperLogLog"
+			}
+			t.Run(name, func(t *testing.T) {
+				count, err := h.UniqueUsers(ctx, test.featureName, test.windows)
+				require.ErrorIs(t, err, test.expectedErr)
+				require.Equal(t, test.expectedCount, count)
+			})
+		}
+
+		keys, _ = h.redisClient.Keys(ctx, "unique-test:uniqueusers:*").Result()
+		require.Empty(t, keys)
+	}
+}
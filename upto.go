@@ -0,0 +1,53 @@
+package hourglass
+
+import (
+	"context"
+	"time"
+)
+
+// ConsumeUpTo consumes as much of requested as the user has left instead of
+// failing when less than requested is available. It returns the number of
+// units granted together with the new counter and the limit, and
+// ErrLimitExceeded when nothing could be granted. The local buffer and value
+// serializers are not applied.
+func (hg *HourGlass) ConsumeUpTo(ctx context.Context, featureName, userName string, requested int) (granted, current, limit int, err error) {
+	if requested <= 0 {
+		return 0, -1, -1, ErrInvalidAmount
+	}
+
+	key, limit, exists := hg.lookup(ctx, featureName, userName)
+	if !exists {
+		return 0, -1, -1, ErrUnknownFeature
+	}
+	if hg.blacklist.contains(userName) {
+		return 0, -1, limit, ErrUserBlacklisted
+	}
+	if hg.whitelist.contains(userName) {
+		return requested, 0, limit, nil
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		return 0, -1, limit, err
+	}
+
+	// flush.lua grants min(requested, limit - current), which is exactly the
+	// partial consume needed here.
+	result, err := hg.flushScript.Run(ctx, hg.redisClient, []string{key}, requested, limit, ttlMillis(hg.ttlFor(ctx, featureName, userName))).Int64Slice()
+	if err != nil {
+		return 0, -1, limit, err
+	}
+
+	current, granted = int(result[0]), int(result[1])
+	if granted == 0 {
+		hg.publishLimitExceeded(ctx, LimitEvent{
+			Feature: featureName,
+			User:    userName,
+			Current: current,
+			Limit:   limit,
+			Time:    time.Now().UTC(),
+		})
+		return 0, current, limit, ErrLimitExceeded
+	}
+
+	return granted, current, limit, nil
+}
@@ -0,0 +1,85 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumeUpTo(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 10,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := dailyKey("feature1", "upto-user")
+	h.redisClient.Del(ctx, key)
+
+	tt := []struct {
+		description     string
+		featureName     string
+		requested       int
+		expectedGranted int
+		expectedCurrent int
+		expectedErr     error
+	}{
+		{
+			description:     "A request within the limit should be granted in full",
+			featureName:     "feature1",
+			requested:       6,
+			expectedGranted: 6,
+			expectedCurrent: 6,
+		},
+		{
+			description:     "A request over the limit should be granted what is left",
+			featureName:     "feature1",
+			requested:       100,
+			expectedGranted: 4,
+			expectedCurrent: 10,
+		},
+		{
+			description:     "A request with nothing left should fail",
+			featureName:     "feature1",
+			requested:       1,
+			expectedGranted: 0,
+			expectedCurrent: 10,
+			expectedErr:     ErrLimitExceeded,
+		},
+		{
+			description:     "A non-positive request should fail",
+			featureName:     "feature1",
+			requested:       0,
+			expectedCurrent: -1,
+			expectedErr:     ErrInvalidAmount,
+		},
+		{
+			description:     "An unknown feature should fail",
+			featureName:     "feature-notexistent",
+			requested:       1,
+			expectedCurrent: -1,
+			expectedErr:     ErrUnknownFeature,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			granted, current, _, err := h.ConsumeUpTo(ctx, tc.featureName, "upto-user", tc.requested)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedGranted, granted)
+			require.Equal(t, tc.expectedCurrent, current)
+		})
+	}
+
+	t.Run("The counter should get an end of day expiry", func(t *testing.T) {
+		require.InDelta(t, h.ttlFor(ctx, "feature1", "upto-user").Seconds(), h.redisClient.TTL(ctx, key).Val().Seconds(), 2)
+	})
+}
@@ -0,0 +1,117 @@
+package hourglass
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// UsagePct returns how much of the limit userName has used, as a percentage.
+// A feature with a limit of zero is always reported as fully used.
+func (hg *HourGlass) UsagePct(ctx context.Context, featureName, userName string) (float64, error) {
+	current, limit, err := hg.get(ctx, featureName, userName)
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if limit == 0 {
+		return 100, nil
+	}
+
+	return float64(current) / float64(limit) * 100, nil
+}
+
+// Simulate projects the counter of userName after additionalCalls more
+// consumes without touching Redis. wouldExceed reports that some of those
+// calls would be denied. Unknown features return -1 for both values, and a
+// failed read returns -1 for the projection; neither would exceed.
+func (hg *HourGlass) Simulate(ctx context.Context, featureName, userName string, additionalCalls int) (projectedCurrent, limit int, wouldExceed bool) {
+	current, limit, err := hg.get(ctx, featureName, userName)
+	switch {
+	case errors.Is(err, redis.Nil):
+		current = 0
+	case err != nil:
+		return -1, limit, false
+	}
+
+	projectedCurrent = current + additionalCalls
+	return projectedCurrent, limit, projectedCurrent > limit
+}
+
+// Projection extrapolates the consume rate of the last hour, taken from the
+// time series, to when userName will hit the limit of featureName.
+// willExceedAt is nil when that would not happen before the window resets.
+// It requires WithTimeSeries and reports no consumption without it or when
+// Redis cannot be read.
+func (hg *HourGlass) Projection(ctx context.Context, featureName, userName string) (projectedHourlyConsumption float64, willExceedAt *time.Time) {
+	if hg.timeSeriesRetention <= 0 {
+		return 0, nil
+	}
+
+	lookback := min(time.Hour, hg.timeSeriesRetention)
+	now := time.Now()
+	events, err := hg.redisClient.ZCount(ctx, hg.timeSeriesKey(ctx, featureName, userName),
+		strconv.FormatInt(now.Add(-lookback).UnixMilli(), 10), strconv.FormatInt(now.UnixMilli(), 10)).Result()
+	if err != nil {
+		hg.logger.WarnContext(ctx, "failed to read consume rate", "feature", featureName, "user", userName, "error", err)
+		return 0, nil
+	}
+	projectedHourlyConsumption = float64(events) / lookback.Hours()
+
+	current, limit, err := hg.get(ctx, featureName, userName)
+	switch {
+	case errors.Is(err, redis.Nil):
+		current = 0
+	case err != nil:
+		return projectedHourlyConsumption, nil
+	}
+
+	if current >= limit {
+		return projectedHourlyConsumption, &now
+	}
+	if projectedHourlyConsumption == 0 {
+		return 0, nil
+	}
+
+	hoursLeft := float64(limit-current) / projectedHourlyConsumption
+	exceedAt := now.Add(time.Duration(hoursLeft * float64(time.Hour)))
+	if !exceedAt.Before(hg.userWindowEnd(ctx, featureName, userName)) {
+		return projectedHourlyConsumption, nil
+	}
+
+	return projectedHourlyConsumption, &exceedAt
+}
+
+// SetUsage sets the current counter of featureName for userName to count,
+// e.g. to seed tests or correct a counter by hand. The counter expires with
+// the window like one created by Consume.
+func (hg *HourGlass) SetUsage(ctx context.Context, featureName, userName string, count int) error {
+	if count < 0 {
+		return ErrInvalidAmount
+	}
+
+	key, _, exists := hg.lookup(ctx, featureName, userName)
+	if !exists {
+		return ErrUnknownFeature
+	}
+
+	if err := hg.ensureConnected(ctx); err != nil {
+		return err
+	}
+
+	raw, err := hg.serializer().Encode(count, nil)
+	if err != nil {
+		return err
+	}
+	if hg.writeCache != nil {
+		hg.writeCache.invalidate(key)
+	}
+
+	return hg.redisClient.Set(ctx, key, raw, hg.ttlFor(ctx, featureName, userName)).Err()
+}
@@ -0,0 +1,265 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsagePct(t *testing.T) {
+	limits := map[string]int{
+		"feature1": 4,
+		"disabled": 0,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Set(ctx, dailyKey("feature1", "pct-user"), 1, 1*time.Minute)
+	h.redisClient.Set(ctx, dailyKey("disabled", "pct-user"), 0, 1*time.Minute)
+	h.redisClient.Del(ctx, dailyKey("feature1", "pct-new-user"))
+
+	tt := []struct {
+		description string
+		featureName string
+		username    string
+		expectedPct float64
+		expectedErr error
+	}{
+		{
+			description: "For an existing counter, the percentage of the limit should be returned",
+			featureName: "feature1",
+			username:    "pct-user",
+			expectedPct: 25,
+		},
+		{
+			description: "For a user that has not consumed, zero should be returned",
+			featureName: "feature1",
+			username:    "pct-new-user",
+			expectedPct: 0,
+		},
+		{
+			description: "For a feature with a zero limit, 100 should be returned",
+			featureName: "disabled",
+			username:    "pct-user",
+			expectedPct: 100,
+		},
+		{
+			description: "For a non-existing feature, an error should be returned",
+			featureName: "feature-notexistent",
+			username:    "pct-user",
+			expectedErr: ErrUnknownFeature,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			pct, err := h.UsagePct(ctx, test.featureName, test.username)
+
+			require.Equal(t, test.expectedErr, err)
+			require.Equal(t, test.expectedPct, pct)
+		})
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 4,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	h.redisClient.Set(ctx, dailyKey("feature1", "simulate-user"), 1, 1*time.Minute)
+	h.redisClient.Del(ctx, dailyKey("feature1", "simulate-new-user"))
+
+	tt := []struct {
+		description         string
+		featureName         string
+		username            string
+		additionalCalls     int
+		expectedProjected   int
+		expectedLimit       int
+		expectedWouldExceed bool
+	}{
+		{
+			description:       "Calls up to the limit should not exceed it",
+			featureName:       "feature1",
+			username:          "simulate-user",
+			additionalCalls:   3,
+			expectedProjected: 4,
+			expectedLimit:     4,
+		},
+		{
+			description:         "Calls beyond the limit should exceed it",
+			featureName:         "feature1",
+			username:            "simulate-user",
+			additionalCalls:     4,
+			expectedProjected:   5,
+			expectedLimit:       4,
+			expectedWouldExceed: true,
+		},
+		{
+			description:       "A user without usage should start from zero",
+			featureName:       "feature1",
+			username:          "simulate-new-user",
+			additionalCalls:   2,
+			expectedProjected: 2,
+			expectedLimit:     4,
+		},
+		{
+			description:       "A non-existing feature should return -1",
+			featureName:       "feature-notexistent",
+			username:          "simulate-user",
+			additionalCalls:   2,
+			expectedProjected: -1,
+			expectedLimit:     -1,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.description, func(t *testing.T) {
+			projected, limit, wouldExceed := h.Simulate(ctx, test.featureName, test.username, test.additionalCalls)
+
+			require.Equal(t, test.expectedProjected, projected)
+			require.Equal(t, test.expectedLimit, limit)
+			require.Equal(t, test.expectedWouldExceed, wouldExceed)
+		})
+	}
+
+	t.Run("Simulating should not change the counter", func(t *testing.T) {
+		current, _ := h.Get(ctx, "feature1", "simulate-user")
+		require.Equal(t, 1, current)
+	})
+}
+
+func TestProjection(t *testing.T) {
+	limits := map[string]int{
+		"feature1":  61,
+		"unlimited": 1000000,
+	}
+
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits:        limits,
+	}, WithTimeSeries(2*time.Hour))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	now := time.Now()
+	for _, featureName := range []string{"feature1", "unlimited"} {
+		key := h.timeSeriesKey(ctx, featureName, "projection-user")
+		h.redisClient.Del(ctx, key)
+		// 60 consumes in the last hour and one older one that is not counted.
+		for i := 0; i < 60; i++ {
+			h.redisClient.ZAdd(ctx, key, redis.Z{Score: float64(now.Add(-time.Duration(i) * time.Minute).UnixMilli()), Member: i})
+		}
+		h.redisClient.ZAdd(ctx, key, redis.Z{Score: float64(now.Add(-90 * time.Minute).UnixMilli()), Member: "old"})
+		h.redisClient.Set(ctx, dailyKey(featureName, "projection-user"), 60, time.Minute)
+	}
+
+	t.Run("A user close to the limit should get a projected time", func(t *testing.T) {
+		rate, exceedAt := h.Projection(ctx, "feature1", "projection-user")
+		require.Equal(t, 60.0, rate)
+		require.NotNil(t, exceedAt)
+		require.WithinDuration(t, now.Add(time.Minute), *exceedAt, 5*time.Second)
+	})
+
+	t.Run("A user who will not reach the limit in this window should get nil", func(t *testing.T) {
+		rate, exceedAt := h.Projection(ctx, "unlimited", "projection-user")
+		require.Equal(t, 60.0, rate)
+		require.Nil(t, exceedAt)
+	})
+
+	t.Run("A user without consumes should have no rate", func(t *testing.T) {
+		rate, exceedAt := h.Projection(ctx, "feature1", "projection-nobody")
+		require.Zero(t, rate)
+		require.Nil(t, exceedAt)
+	})
+}
+
+func TestSetUsage(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 10,
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	tt := []struct {
+		description     string
+		featureName     string
+		count           int
+		expectedCurrent int
+		expectedErr     error
+	}{
+		{
+			description:     "The counter should be set to the count",
+			featureName:     "feature1",
+			count:           7,
+			expectedCurrent: 7,
+		},
+		{
+			description:     "A count of zero should reset the counter",
+			featureName:     "feature1",
+			count:           0,
+			expectedCurrent: 0,
+		},
+		{
+			description:     "A negative count should fail",
+			featureName:     "feature1",
+			count:           -1,
+			expectedCurrent: 0,
+			expectedErr:     ErrInvalidAmount,
+		},
+		{
+			description:     "An unknown feature should fail",
+			featureName:     "feature-notexistent",
+			count:           1,
+			expectedCurrent: 0,
+			expectedErr:     ErrUnknownFeature,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.description, func(t *testing.T) {
+			err := h.SetUsage(ctx, tc.featureName, "set-usage-user", tc.count)
+			require.ErrorIs(t, err, tc.expectedErr)
+
+			current, _ := h.Get(ctx, "feature1", "set-usage-user")
+			require.Equal(t, tc.expectedCurrent, current)
+		})
+	}
+
+	t.Run("The counter should expire with the window", func(t *testing.T) {
+		ttl := h.redisClient.TTL(ctx, dailyKey("feature1", "set-usage-user")).Val()
+		require.InDelta(t, timeUntilEndOfDay().Seconds(), ttl.Seconds(), 2)
+	})
+}
@@ -0,0 +1,157 @@
+package hourglass
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+var limitExprUnits = map[string]time.Duration{
+	"s":      time.Second,
+	"sec":    time.Second,
+	"second": time.Second,
+	"m":      time.Minute,
+	"min":    time.Minute,
+	"minute": time.Minute,
+	"h":      time.Hour,
+	"hour":   time.Hour,
+	"d":      day,
+	"day":    day,
+}
+
+// ParseLimitExpr parses a rate expression such as "10/min", "100/hour" or
+// "1000/day" into a FeatureConfig with Limit and Window set. The unit may
+// also be a Go duration such as "15m".
+func ParseLimitExpr(expr string) (FeatureConfig, error) {
+	count, unit, found := strings.Cut(strings.TrimSpace(expr), "/")
+	if !found {
+		return FeatureConfig{}, fmt.Errorf("%w %q: missing /", ErrInvalidLimitExpr, expr)
+	}
+
+	limit, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || limit < 0 {
+		return FeatureConfig{}, fmt.Errorf("%w %q: invalid count", ErrInvalidLimitExpr, expr)
+	}
+
+	unit = strings.TrimSpace(unit)
+	window, known := limitExprUnits[unit]
+	if !known {
+		window, err = time.ParseDuration(unit)
+		if err != nil || window <= 0 {
+			return FeatureConfig{}, fmt.Errorf("%w %q: invalid window", ErrInvalidLimitExpr, expr)
+		}
+	}
+
+	return FeatureConfig{Limit: limit, Window: window}, nil
+}
+
+// parseFeatures returns config.Features with the parsed LimitExpressions
+// merged in.
+func parseFeatures(config *Config) (map[string]FeatureConfig, error) {
+	features := maps.Clone(config.Features)
+	if features == nil {
+		features = map[string]FeatureConfig{}
+	}
+
+	for featureName, expr := range config.LimitExpressions {
+		parsed, err := ParseLimitExpr(expr)
+		if err != nil {
+			return nil, fmt.Errorf("feature %q: %w", featureName, err)
+		}
+
+		featureConfig := features[featureName]
+		featureConfig.Limit = parsed.Limit
+		featureConfig.Window = parsed.Window
+		features[featureName] = featureConfig
+	}
+
+	return features, nil
+}
+
+// windowStart returns the start of the current window of featureName, or
+// false for features that use the default daily window.
+func (hg *HourGlass) windowStart(featureName string) (time.Time, bool) {
+	return hg.windowStartAt(featureName, time.Now())
+}
+
+// windowStartAt is windowStart for the window that contains at.
+func (hg *HourGlass) windowStartAt(featureName string, at time.Time) (time.Time, bool) {
+	window := hg.appConfig.Features[featureName].Window
+	if window <= 0 || window == day {
+		return time.Time{}, false
+	}
+
+	// Time.Truncate counts from the zero Time, so align to the Unix epoch
+	// explicitly. Windows that divide a day start at the same times either
+	// way, but a 7 day window starts on a Thursday rather than a Monday.
+	elapsed := at.UnixNano() % int64(window)
+	return time.Unix(0, at.UnixNano()-elapsed).UTC(), true
+}
+
+// windowID identifies the current window of featureName in counter keys. It
+// is the date for daily windows and the start time for all others.
+func (hg *HourGlass) windowID(featureName string) string {
+	return hg.windowIDAt(featureName, time.Now())
+}
+
+// windowIDAt is windowID for the window that contains at.
+func (hg *HourGlass) windowIDAt(featureName string, at time.Time) string {
+	start, windowed := hg.windowStartAt(featureName, at)
+	if !windowed {
+		return at.UTC().Format("2006-01-02")
+	}
+
+	return start.Format("2006-01-02T150405")
+}
+
+// windowBounds returns the start and end of the window of featureName that
+// windowID identifies, as written by windowIDAt. It reports false for IDs
+// that are not a window of featureName, e.g. a date for a windowed feature.
+// keyFeature may include the priority level of a priority counter.
+func (hg *HourGlass) windowBounds(keyFeature, windowID string) (start, end time.Time, ok bool) {
+	featureName := hg.baseFeature(keyFeature)
+	layout := "2006-01-02T150405"
+	if _, windowed := hg.windowStartAt(featureName, time.Time{}); !windowed {
+		layout = "2006-01-02"
+	}
+
+	start, err := time.Parse(layout, windowID)
+	if err != nil || hg.windowIDAt(featureName, start) != windowID {
+		return time.Time{}, time.Time{}, false
+	}
+
+	window := hg.appConfig.Features[featureName].Window
+	if window <= 0 {
+		window = day
+	}
+
+	return start, start.Add(window), true
+}
+
+// baseFeature strips the priority level from the feature part of a priority
+// counter key.
+func (hg *HourGlass) baseFeature(keyFeature string) string {
+	featureName, priority, found := strings.Cut(keyFeature, ":")
+	if !found {
+		return keyFeature
+	}
+	if _, ok := hg.appConfig.PriorityLimits[featureName][priority]; !ok {
+		return keyFeature
+	}
+
+	return featureName
+}
+
+// windowEnd returns when the current window of featureName resets.
+func (hg *HourGlass) windowEnd(featureName string) time.Time {
+	start, windowed := hg.windowStart(featureName)
+	if !windowed {
+		return endOfDay()
+	}
+
+	return start.Add(hg.appConfig.Features[featureName].Window)
+}
@@ -0,0 +1,121 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimitExpr(t *testing.T) {
+	tt := []struct {
+		expr           string
+		expectedLimit  int
+		expectedWindow time.Duration
+		expectedErr    error
+	}{
+		{expr: "10/min", expectedLimit: 10, expectedWindow: time.Minute},
+		{expr: "100/hour", expectedLimit: 100, expectedWindow: time.Hour},
+		{expr: "1000/day", expectedLimit: 1000, expectedWindow: 24 * time.Hour},
+		{expr: " 5 / s ", expectedLimit: 5, expectedWindow: time.Second},
+		{expr: "50/15m", expectedLimit: 50, expectedWindow: 15 * time.Minute},
+		{expr: "10", expectedErr: ErrInvalidLimitExpr},
+		{expr: "ten/min", expectedErr: ErrInvalidLimitExpr},
+		{expr: "-1/min", expectedErr: ErrInvalidLimitExpr},
+		{expr: "10/fortnight", expectedErr: ErrInvalidLimitExpr},
+		{expr: "10/0s", expectedErr: ErrInvalidLimitExpr},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.expr, func(t *testing.T) {
+			featureConfig, err := ParseLimitExpr(tc.expr)
+			require.ErrorIs(t, err, tc.expectedErr)
+			require.Equal(t, tc.expectedLimit, featureConfig.Limit)
+			require.Equal(t, tc.expectedWindow, featureConfig.Window)
+		})
+	}
+}
+
+func TestLimitExpressions(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 5,
+			"feature2": 5,
+		},
+		LimitExpressions: map[string]string{
+			"feature1": "2/hour",
+			"feature3": "3/day",
+			"feature4": "3/s",
+			"feature5": "10/168h",
+		},
+		Features: map[string]FeatureConfig{
+			"feature1": {BurstAllowance: 1},
+		},
+	})
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	t.Run("Expressions should set the limit and window of their features", func(t *testing.T) {
+		require.Equal(t, map[string]int{"feature1": 2, "feature2": 5, "feature3": 3, "feature4": 3, "feature5": 10}, h.limitProvider.Limits())
+		require.Equal(t, FeatureConfig{Limit: 2, Window: time.Hour, BurstAllowance: 1}, h.appConfig.Features["feature1"])
+	})
+
+	t.Run("Windowed features should count per window", func(t *testing.T) {
+		start := time.Now().UTC().Truncate(time.Hour)
+		key := "feature1:window-user:" + start.Format("2006-01-02T150405")
+		h.redisClient.Del(ctx, key, burstKey(key))
+
+		result, err := h.consume(ctx, "feature1", "window-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, start.Add(time.Hour), result.ResetsAt)
+
+		require.Equal(t, "1", h.redisClient.Get(ctx, key).Val())
+		require.InDelta(t, time.Until(start.Add(time.Hour)).Seconds(), h.redisClient.TTL(ctx, key).Val().Seconds(), 2)
+	})
+
+	t.Run("A per-second limit should be enforced", func(t *testing.T) {
+		// Start at the beginning of a second so that all consumes fall in it.
+		time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+
+		for i := 1; i <= 4; i++ {
+			result, err := h.consume(ctx, "feature4", "second-user")
+			require.Nil(t, err)
+			require.Equal(t, i <= 3, result.Allowed)
+		}
+
+		key, _, _ := h.lookup(ctx, "feature4", "second-user")
+		pttl := h.redisClient.PTTL(ctx, key).Val()
+		require.Greater(t, pttl, time.Duration(0))
+		require.LessOrEqual(t, pttl, time.Second)
+	})
+
+	t.Run("Windows should be aligned to the Unix epoch", func(t *testing.T) {
+		start, windowed := h.windowStartAt("feature5", time.Now())
+		require.True(t, windowed)
+		require.Equal(t, time.Thursday, start.Weekday())
+		require.Zero(t, start.Sub(time.Unix(0, 0))%(168*time.Hour))
+	})
+
+	t.Run("Daily expressions should keep the daily keys", func(t *testing.T) {
+		h.redisClient.Del(ctx, dailyKey("feature3", "window-user"))
+
+		result, _ := h.Consume(ctx, "feature3", "window-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, "1", h.redisClient.Get(ctx, dailyKey("feature3", "window-user")).Val())
+	})
+
+	t.Run("An invalid expression should fail New", func(t *testing.T) {
+		_, err := New(&Config{
+			RedisAddress:     "localhost:6379",
+			LimitExpressions: map[string]string{"feature1": "lots"},
+		})
+		require.ErrorIs(t, err, ErrInvalidLimitExpr)
+	})
+}
@@ -0,0 +1,118 @@
+package hourglass
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// WithWriteThroughCache keeps an in-process counter per feature and user so
+// that Consume can skip the read in consume.lua. The counter is loaded from
+// Redis on first use; while it is below the limit a consume only sends a
+// write-only increment, which also gives a recreated key its TTL, and takes
+// the result as the new counter. When the increment lands above the limit
+// because another instance consumed in the meantime, it is undone and the
+// full script runs. Near the limit the full script runs as well. Features
+// with a custom consume script, and every feature with a script response
+// hook, always run their script.
+func WithWriteThroughCache() Option {
+	return func(hg *HourGlass) {
+		hg.writeCache = &writeCache{entries: map[string]*atomic.Int64{}}
+	}
+}
+
+type writeCache struct {
+	mu      sync.Mutex
+	day     string
+	entries map[string]*atomic.Int64
+}
+
+func (c *writeCache) counter(key string) (*atomic.Int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Keys are per day, drop yesterday's counters once the day rolls over.
+	if day := time.Now().UTC().Format("2006-01-02"); day != c.day {
+		c.day = day
+		c.entries = map[string]*atomic.Int64{}
+	}
+
+	counter, exists := c.entries[key]
+	return counter, exists
+}
+
+func (c *writeCache) store(key string, current int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counter, exists := c.entries[key]
+	if !exists {
+		counter = &atomic.Int64{}
+		c.entries[key] = counter
+	}
+	counter.Store(int64(current))
+}
+
+func (c *writeCache) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+func (hg *HourGlass) consumeWriteThrough(ctx context.Context, script *redis.Script, key string, limit, burst int, ttl time.Duration) (current int, newLimit int, allowed, burstUsed bool, err error) {
+	// Only the embedded script can be replaced by a plain increment.
+	if script != hg.pool.consumeScript || hg.scriptResponseHook != nil {
+		return hg.runConsumeScript(ctx, script, key, limit, burst, ttl)
+	}
+
+	if counter, exists := hg.writeCache.counter(key); exists {
+		if counter.Add(1) <= int64(limit) {
+			incremented, err := hg.increment(ctx, key, ttl)
+			if err != nil {
+				counter.Add(-1)
+				return -1, limit, false, false, err
+			}
+			if incremented <= int64(limit) {
+				counter.Store(incremented)
+				return int(incremented), limit, true, false, nil
+			}
+			// Another instance consumed in the meantime, undo the increment
+			// and confirm with the script, which also applies the burst
+			// allowance.
+			if err := hg.redisClient.Decr(ctx, key).Err(); err != nil {
+				return -1, limit, false, false, err
+			}
+		} else {
+			counter.Add(-1)
+		}
+	}
+
+	current, newLimit, allowed, burstUsed, err = hg.runConsumeScript(ctx, script, key, limit, burst, ttl)
+	if err != nil {
+		return current, newLimit, allowed, burstUsed, err
+	}
+	hg.writeCache.store(key, current)
+
+	return current, newLimit, allowed, burstUsed, nil
+}
+
+// increment adds one to the counter at key without reading it first. A key
+// that expired in the meantime is recreated with ttl in the same
+// transaction.
+func (hg *HourGlass) increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := hg.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, key, 0, time.Duration(ttlMillis(ttl))*time.Millisecond)
+		incr = pipe.Incr(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
@@ -0,0 +1,106 @@
+package hourglass
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteThroughCache(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 3,
+		},
+	}, WithWriteThroughCache())
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := dailyKey("feature1", "cache-user")
+	h.redisClient.Del(ctx, key)
+
+	t.Run("Consumes should be written through to Redis", func(t *testing.T) {
+		result, _ := h.Consume(ctx, "feature1", "cache-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+
+		counter, exists := h.writeCache.counter(key)
+		require.True(t, exists)
+		require.Equal(t, int64(1), counter.Load())
+
+		result, _ = h.Consume(ctx, "feature1", "cache-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 2, result.Current)
+		require.Equal(t, "2", h.redisClient.Get(ctx, key).Val())
+		require.Greater(t, h.redisClient.TTL(ctx, key).Val(), time.Duration(0))
+	})
+
+	t.Run("Consumes by other instances should be respected", func(t *testing.T) {
+		h.redisClient.Set(ctx, key, 3, 0)
+
+		result, _ := h.Consume(ctx, "feature1", "cache-user")
+		require.False(t, result.Allowed)
+		require.Equal(t, 3, result.Current)
+		require.Equal(t, "3", h.redisClient.Get(ctx, key).Val())
+	})
+
+	t.Run("Credit should invalidate the cached counter", func(t *testing.T) {
+		current, _ := h.Credit(ctx, "feature1", "cache-user")
+		require.Equal(t, 2, current)
+
+		_, exists := h.writeCache.counter(key)
+		require.False(t, exists)
+
+		result, _ := h.Consume(ctx, "feature1", "cache-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 3, result.Current)
+
+		result, _ = h.Consume(ctx, "feature1", "cache-user")
+		require.False(t, result.Allowed)
+	})
+
+	t.Run("A counter recreated through the cache should get a TTL", func(t *testing.T) {
+		h.redisClient.Del(ctx, key)
+		h.writeCache.store(key, 0)
+
+		result, _ := h.Consume(ctx, "feature1", "cache-user")
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Current)
+		require.Greater(t, h.redisClient.TTL(ctx, key).Val(), time.Duration(0))
+	})
+}
+
+func TestWriteThroughCacheCustomScript(t *testing.T) {
+	ctx := context.Background()
+
+	h, err := New(&Config{
+		RedisAddress:  "localhost:6379",
+		RedisPassword: "",
+		Limits: map[string]int{
+			"feature1": 3,
+		},
+	}, WithWriteThroughCache(), WithConsumeScript("return {42, tonumber(ARGV[1]), 1}"))
+
+	require.Nil(t, err)
+	defer h.Close()
+
+	key := dailyKey("feature1", "cache-script-user")
+	h.redisClient.Del(ctx, key)
+
+	for range 2 {
+		result, err := h.Consume(ctx, "feature1", "cache-script-user")
+		require.Nil(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 42, result.Current)
+	}
+
+	_, exists := h.writeCache.counter(key)
+	require.False(t, exists)
+	require.Equal(t, int64(0), h.redisClient.Exists(ctx, key).Val())
+}